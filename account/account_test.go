@@ -0,0 +1,170 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestReconcile(t *testing.T) {
+	db := newTestDB(t)
+	at := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	if err := db.SaveAccountBalances(at, map[string]float64{
+		"XXBT": 1.5,
+		"XETH": 2,
+		"ZUSD": 100,
+		"DOT":  0,
+		"XXRP": 10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(id, asset string, minutes int, amount, fee, balance float64) models.LedgerEntry {
+		return models.LedgerEntry{ID: id, RefID: "R" + id, Time: at.Add(time.Duration(minutes-60) * time.Minute),
+			Type: "deposit", Asset: asset, Amount: amount, Fee: fee, Balance: balance}
+	}
+	if _, err := db.SaveLedgerEntries([]models.LedgerEntry{
+		// Within the tolerance.
+		entry("L1", "XXBT", 0, 1, 0, 1),
+		entry("L2", "XXBT", 1, 0.5000000001, 0, 1.5),
+		// An earlier deposit of 1 ETH is missing: the balances agree, the sum
+		// does not.
+		entry("L3", "XETH", 0, 1.05, 0.05, 2),
+		// Sold before the snapshot, then absent from it.
+		entry("L4", "ADA", 0, 100, 0, 100),
+		entry("L5", "ADA", 1, -99.9, 0.1, 0),
+		// Not in the snapshot.
+		entry("L6", "SOL", 0, 5, 0, 5),
+		// Just past the tolerance.
+		entry("L7", "XXRP", 0, 10.00000002, 0, 10.00000002),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Reconcile(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.BalancesAt.Equal(at) || report.Mismatches != 4 {
+		t.Errorf("balances at %v, %d mismatches, want %v and 4", report.BalancesAt, report.Mismatches, at)
+	}
+
+	tests := []struct {
+		asset  string
+		code   string
+		status string
+		issues int
+		net    float64
+	}{
+		{"ADA", "ADA", StatusOK, 0, 0},
+		{"DOT", "DOT", StatusOK, 0, 0},
+		{"SOL", "SOL", StatusMissingBalance, 1, -5},
+		{"XETH", "ETH", StatusMismatch, 1, 1},
+		{"XXBT", "BTC", StatusOK, 0, -1e-10},
+		{"XXRP", "XRP", StatusMismatch, 2, -2e-8},
+		{"ZUSD", "USD", StatusMissingLedger, 1, 100},
+	}
+	if len(report.Assets) != len(tests) {
+		t.Fatalf("assets %+v", report.Assets)
+	}
+	for i, tt := range tests {
+		got := report.Assets[i]
+		if got.Asset != tt.asset || got.Code != tt.code || got.Status != tt.status || len(got.Issues) != tt.issues {
+			t.Errorf("asset %d = %s (%s) %s %q, want %s (%s) %s with %d issues", i, got.Asset, got.Code, got.Status, got.Issues, tt.asset, tt.code, tt.status, tt.issues)
+		}
+		if diff := got.NetDifference - tt.net; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("%s: net difference %v, want %v", got.Asset, got.NetDifference, tt.net)
+		}
+	}
+}
+
+func TestReconcileWithoutBalances(t *testing.T) {
+	if _, err := Reconcile(newTestDB(t)); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("Reconcile = %v, want ErrNotFound", err)
+	}
+}
+
+// newFakeKraken serves a ledger of count entries, one per page, and the
+// balances.
+func newFakeKraken(t *testing.T, count int) *kraken.PrivateClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/private/Ledgers":
+			r.ParseForm()
+			fmt.Fprintf(w, `{"error":[],"result":{"ledger":{"L%s":{"refid":"R","time":1718020800,"type":"deposit","asset":"XXBT",`+
+				`"amount":"0.1","fee":"0","balance":"0.1"}},"count":%d}}`, r.Form.Get("ofs"), count)
+		case "/0/private/Balance":
+			fmt.Fprint(w, `{"error":[],"result":{"XXBT":"0.1"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := kraken.NewPrivateClient(kraken.Credentials{Key: "key", Secret: "c2VjcmV0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetBaseURL(server.URL + "/0")
+	return client
+}
+
+func TestSyncRunsOneAtATime(t *testing.T) {
+	s := NewSyncer(newTestDB(t), newFakeKraken(t, 1))
+	s.running.Lock()
+	if _, err := s.WithContext(context.Background()).Sync(); !errors.Is(err, ErrSyncRunning) {
+		t.Fatalf("Sync while another runs = %v, want ErrSyncRunning", err)
+	}
+	s.running.Unlock()
+
+	result, err := s.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Assets != 1 || result.LedgerEntries != 1 {
+		t.Errorf("result %+v", result)
+	}
+}
+
+// The wait between ledger pages ends with the context of the sync.
+func TestSyncStopsWaitingOnCancel(t *testing.T) {
+	db := newTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewSyncer(db, newFakeKraken(t, 3)).WithContext(ctx).Sync()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Sync = %v, want the deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed >= ledgerPageDelay {
+		t.Errorf("Sync returned after %v, the page delay is %v", elapsed, ledgerPageDelay)
+	}
+	// The first page is kept.
+	if entries, err := db.GetLedgerEntriesFromDB("", time.Time{}, time.Now(), 0); err != nil || len(entries) != 1 {
+		t.Errorf("stored entries %+v, %v, want 1", entries, err)
+	}
+}
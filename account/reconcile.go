@@ -0,0 +1,115 @@
+package account
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+)
+
+const (
+	StatusOK             = "ok"
+	StatusMismatch       = "mismatch"
+	StatusMissingLedger  = "missing_ledger"
+	StatusMissingBalance = "missing_balance"
+)
+
+// tolerance absorbs the rounding of amounts Kraken reports with up to ten
+// decimals.
+const tolerance = 1e-8
+
+type AssetReconciliation struct {
+	Asset             string     `json:"asset"`
+	Code              string     `json:"code"`
+	Reported          float64    `json:"reported"`
+	LedgerNet         float64    `json:"ledger_net"`
+	LedgerBalance     float64    `json:"ledger_balance"`
+	Entries           int        `json:"entries"`
+	LastEntry         *time.Time `json:"last_entry,omitempty"`
+	NetDifference     float64    `json:"net_difference"`
+	BalanceDifference float64    `json:"balance_difference"`
+	Status            string     `json:"status"`
+	Issues            []string   `json:"issues,omitempty"`
+}
+
+type Reconciliation struct {
+	BalancesAt time.Time             `json:"balances_at"`
+	Assets     []AssetReconciliation `json:"assets"`
+	Mismatches int                   `json:"mismatches"`
+}
+
+// Reconcile compares the latest reported balances with the stored ledger.
+// Each asset is checked twice: the sum of all ledger entries (amount minus
+// fee) reveals missing entries, and the balance carried by the latest entry
+// reveals movements the ledger has not recorded yet.
+func Reconcile(db *database.DB) (*Reconciliation, error) {
+	balances, err := db.GetLatestAccountBalances()
+	if err != nil {
+		return nil, err
+	}
+	totals, err := db.GetLedgerTotalsFromDB()
+	if err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[string]*AssetReconciliation)
+	get := func(asset string) *AssetReconciliation {
+		r, ok := byAsset[asset]
+		if !ok {
+			r = &AssetReconciliation{Asset: asset, Code: assets.Normalize(asset)}
+			byAsset[asset] = r
+		}
+		return r
+	}
+
+	report := &Reconciliation{BalancesAt: balances[0].Timestamp}
+	reported := make(map[string]bool)
+	for _, b := range balances {
+		get(b.Asset).Reported = b.Amount
+		reported[b.Asset] = true
+	}
+	for _, t := range totals {
+		r := get(t.Asset)
+		r.LedgerNet = t.Net
+		r.LedgerBalance = t.LastBalance
+		r.Entries = t.Entries
+		lastTime := t.LastTime
+		r.LastEntry = &lastTime
+	}
+
+	for _, r := range byAsset {
+		r.NetDifference = r.Reported - r.LedgerNet
+		r.BalanceDifference = r.Reported - r.LedgerBalance
+
+		switch {
+		case r.Entries == 0 && math.Abs(r.Reported) > tolerance:
+			r.Issues = append(r.Issues, "aucune écriture dans le grand livre pour ce solde")
+			r.Status = StatusMissingLedger
+		case !reported[r.Asset] && math.Abs(r.LedgerBalance) > tolerance:
+			r.Issues = append(r.Issues, "solde absent du dernier relevé")
+			r.Status = StatusMissingBalance
+		default:
+			if math.Abs(r.BalanceDifference) > tolerance {
+				r.Issues = append(r.Issues, "le solde de la dernière écriture diffère du solde déclaré")
+			}
+			if math.Abs(r.NetDifference) > tolerance {
+				r.Issues = append(r.Issues, "la somme des écritures diffère du solde déclaré")
+			}
+			r.Status = StatusOK
+			if len(r.Issues) > 0 {
+				r.Status = StatusMismatch
+			}
+		}
+		if r.Status != StatusOK {
+			report.Mismatches++
+		}
+		report.Assets = append(report.Assets, *r)
+	}
+
+	sort.Slice(report.Assets, func(i, j int) bool {
+		return report.Assets[i].Asset < report.Assets[j].Asset
+	})
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// ledgerPageDelay spaces the Ledgers calls of one sync so that a long
+// history does not exhaust Kraken's API call counter.
+const ledgerPageDelay = 3 * time.Second
+
+// ErrSyncRunning is returned by Sync while another sync of the account is
+// running.
+var ErrSyncRunning = errors.New("une synchronisation du compte est déjà en cours")
+
+// Syncer snapshots the balances and ledger entries of a Kraken account into
+// the database.
+type Syncer struct {
+	db     *database.DB
+	client *kraken.PrivateClient
+	ctx    context.Context
+	// running is shared by the copies returned by WithContext, so that the
+	// scheduled and the requested syncs never page the ledger together.
+	running *sync.Mutex
+}
+
+func NewSyncer(db *database.DB, client *kraken.PrivateClient) *Syncer {
+	return &Syncer{db: db, client: client, ctx: context.Background(), running: &sync.Mutex{}}
+}
+
+// WithContext returns a syncer whose Kraken calls and database writes carry
+// ctx, and whose waits between ledger pages end with it.
+func (s *Syncer) WithContext(ctx context.Context) *Syncer {
+	return &Syncer{db: s.db.WithContext(ctx), client: s.client.WithContext(ctx), ctx: ctx, running: s.running}
+}
+
+type SyncResult struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Assets        int       `json:"assets"`
+	LedgerEntries int       `json:"ledger_entries"`
+}
+
+// Sync fetches the ledger entries newer than the last stored one, then a
+// balance snapshot, so that both describe the same point in time. It returns
+// ErrSyncRunning at once when another sync is running.
+func (s *Syncer) Sync() (*SyncResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrSyncRunning
+	}
+	defer s.running.Unlock()
+
+	now := time.Now().UTC()
+
+	inserted, err := s.syncLedger(now)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la synchronisation du grand livre: %w", err)
+	}
+
+	balances, err := s.client.GetBalance()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des soldes: %w", err)
+	}
+	if err := s.db.SaveAccountBalances(now, balances); err != nil {
+		return nil, err
+	}
+
+	return &SyncResult{Timestamp: now, Assets: len(balances), LedgerEntries: inserted}, nil
+}
+
+func (s *Syncer) syncLedger(end time.Time) (int, error) {
+	start, err := s.db.GetLatestLedgerTime()
+	if err != nil {
+		return 0, err
+	}
+	if !start.IsZero() {
+		// Kraken's start bound is exclusive and entries already stored are
+		// ignored, so step back to catch entries sharing the same second.
+		start = start.Add(-time.Second)
+	}
+
+	inserted, offset := 0, 0
+	for {
+		page, err := s.client.GetLedgers("", start, end, offset)
+		if err != nil {
+			return inserted, err
+		}
+
+		entries := make([]models.LedgerEntry, 0, len(page.Ledger))
+		for id, e := range page.Ledger {
+			entries = append(entries, models.LedgerEntry{
+				ID:      id,
+				RefID:   e.RefID,
+				Time:    kraken.UnixTime(e.Time),
+				Type:    e.Type,
+				Subtype: e.Subtype,
+				AClass:  e.AClass,
+				Asset:   e.Asset,
+				Amount:  e.Amount,
+				Fee:     e.Fee,
+				Balance: e.Balance,
+			})
+		}
+		n, err := s.db.SaveLedgerEntries(entries)
+		if err != nil {
+			return inserted, err
+		}
+		inserted += n
+
+		offset += len(page.Ledger)
+		if len(page.Ledger) == 0 || offset >= page.Count {
+			return inserted, nil
+		}
+		select {
+		case <-time.After(ledgerPageDelay):
+		case <-s.ctx.Done():
+			return inserted, s.ctx.Err()
+		}
+	}
+}
+
+// Start runs Sync every interval in the background, each run with its own
+// cycle identifier.
+func (s *Syncer) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			ctx := logging.WithCycleID(context.Background(), logging.NewID())
+			if result, err := s.WithContext(ctx).Sync(); err != nil {
+				slog.ErrorContext(ctx, "échec de la synchronisation du compte", logging.Err(err))
+			} else {
+				slog.InfoContext(ctx, "compte synchronisé", "assets", result.Assets, "ledger_entries", result.LedgerEntries)
+			}
+		}
+	}()
+}
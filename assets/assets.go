@@ -0,0 +1,117 @@
+package assets
+
+import "strings"
+
+// krakenAssets maps Kraken's legacy asset codes and aliases to the codes
+// used everywhere else.
+var krakenAssets = map[string]string{
+	"XXBT": "BTC",
+	"XBT":  "BTC",
+	"XXDG": "DOGE",
+	"XDG":  "DOGE",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XXRP": "XRP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XZEC": "ZEC",
+	"XREP": "REP",
+	"XMLN": "MLN",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZJPY": "JPY",
+	"ZCAD": "CAD",
+	"ZAUD": "AUD",
+	"ZCHF": "CHF",
+}
+
+// Normalize returns the common code of an asset ("XXBT" → "BTC"). Kraken
+// balance suffixes such as ".S" (staked) or ".F" (opt-in rewards) are
+// dropped.
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if i := strings.Index(code, "."); i > 0 {
+		code = code[:i]
+	}
+	if normalized, ok := krakenAssets[code]; ok {
+		return normalized
+	}
+	return code
+}
+
+// quoteCodes are the quote currencies recognized at the end of a pair name
+// without separator, longest first so that "USDT" wins over "USD". Kraken's
+// prefixed codes ("ZUSD", "XXBT") are only read in the 8-character form of
+// its pair names, see splitKrakenPair.
+var quoteCodes = []string{
+	"USDT", "USDC",
+	"DAI", "XBT", "BTC", "ETH", "USD", "EUR", "GBP", "JPY", "CAD", "AUD", "CHF",
+}
+
+var separators = []string{"/", "-", "_", ":"}
+
+// HasSeparator reports whether a pair name separates its base and quote
+// ("BTC/USD", "BTC-USD"), in which case SplitPair does not have to guess.
+func HasSeparator(pair string) bool {
+	for _, sep := range separators {
+		if strings.Contains(pair, sep) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitPair splits a pair name in any common format ("XXBTZUSD", "XBTUSD",
+// "XBT/USD", "BTC-USD", "BTCUSDT", "btc_usd") into normalized base and
+// quote codes. Without a separator the split is a guess on the quote
+// suffix: a name such as "STETH" or "PYUSD" is read as a pair, so callers
+// that may receive a single asset should check the venues' markets first.
+func SplitPair(pair string) (base, quote string, ok bool) {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	for _, sep := range separators {
+		if b, q, found := strings.Cut(pair, sep); found {
+			if b == "" || q == "" {
+				return "", "", false
+			}
+			return Normalize(b), Normalize(q), true
+		}
+	}
+
+	if base, quote, ok := splitKrakenPair(pair); ok {
+		return base, quote, true
+	}
+
+	for _, q := range quoteCodes {
+		// A base of a single letter is more likely a longer quote code
+		// ("USDT" is not T/USD).
+		if strings.HasSuffix(pair, q) && len(pair)-len(q) >= 2 {
+			return Normalize(pair[:len(pair)-len(q)]), Normalize(q), true
+		}
+	}
+	return "", "", false
+}
+
+// splitKrakenPair splits the 8-character names of Kraken's legacy pairs,
+// made of two prefixed 4-character codes ("XXBTZUSD", "XETHXXBT").
+func splitKrakenPair(pair string) (base, quote string, ok bool) {
+	if len(pair) != 8 {
+		return "", "", false
+	}
+	base, quote = krakenAssets[pair[:4]], krakenAssets[pair[4:]]
+	if base == "" || quote == "" {
+		return "", "", false
+	}
+	return base, quote, true
+}
+
+// NormalizePair returns the canonical "BASE/QUOTE" symbol of a pair name,
+// e.g. "XXBTZUSD" → "BTC/USD".
+func NormalizePair(pair string) (string, bool) {
+	base, quote, ok := SplitPair(pair)
+	if !ok {
+		return "", false
+	}
+	return base + "/" + quote, true
+}
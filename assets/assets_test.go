@@ -0,0 +1,85 @@
+package assets
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"XXBT":   "BTC",
+		"xbt":    "BTC",
+		"XXDG":   "DOGE",
+		"ZUSD":   "USD",
+		"ETH2.S": "ETH2",
+		"DOT.F":  "DOT",
+		" eth ":  "ETH",
+		"XTZ":    "XTZ",
+		"STETH":  "STETH",
+	}
+	for code, want := range tests {
+		if got := Normalize(code); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestSplitPair(t *testing.T) {
+	tests := []struct {
+		pair  string
+		base  string
+		quote string
+		ok    bool
+	}{
+		// Kraken's legacy 8-character names.
+		{"XXBTZUSD", "BTC", "USD", true},
+		{"XETHXXBT", "ETH", "BTC", true},
+		{"XXDGZEUR", "DOGE", "EUR", true},
+		{"xethzusd", "ETH", "USD", true},
+		// Kraken's alternative names and other venues' symbols.
+		{"XBTUSD", "BTC", "USD", true},
+		{"ETHXBT", "ETH", "BTC", true},
+		{"XTZUSD", "XTZ", "USD", true},
+		{"XTZEUR", "XTZ", "EUR", true},
+		{"BTCUSDT", "BTC", "USDT", true},
+		{"ETHUSDC", "ETH", "USDC", true},
+		{"ZECUSD", "ZEC", "USD", true},
+		{"XRPUSDT", "XRP", "USDT", true},
+		// An 8-character name whose halves are not Kraken codes.
+		{"AAVEUSDT", "AAVE", "USDT", true},
+		// Separators.
+		{"XBT/USD", "BTC", "USD", true},
+		{"BTC-USD", "BTC", "USD", true},
+		{"btc_usd", "BTC", "USD", true},
+		{"XXBT:ZUSD", "BTC", "USD", true},
+		{"STETH/ETH", "STETH", "ETH", true},
+		// Not pairs.
+		{"USDT", "", "", false},
+		{"BTC", "", "", false},
+		{"/USD", "", "", false},
+		{"BTC-", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			base, quote, ok := SplitPair(tt.pair)
+			if base != tt.base || quote != tt.quote || ok != tt.ok {
+				t.Errorf("SplitPair(%q) = %q, %q, %v, want %q, %q, %v", tt.pair, base, quote, ok, tt.base, tt.quote, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHasSeparator(t *testing.T) {
+	tests := map[string]bool{
+		"BTC/USD":  true,
+		"BTC-USD":  true,
+		"btc_usd":  true,
+		"XBT:USD":  true,
+		"XXBTZUSD": false,
+		"STETH":    false,
+	}
+	for pair, want := range tests {
+		if got := HasSeparator(pair); got != want {
+			t.Errorf("HasSeparator(%q) = %v, want %v", pair, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrJWTNotConfigured is returned by LoadJWTVerifier when neither an HS256
+// secret nor an RS256 public key is configured.
+var ErrJWTNotConfigured = errors.New("auth: aucune clé de vérification JWT configurée")
+
+// jwtLeeway tolerates small clock differences with the token issuer.
+const jwtLeeway = time.Minute
+
+// JWTVerifier checks the tokens issued by the SSO: HS256 with a shared
+// secret or RS256 with the issuer's public key. Issuer and Audience are
+// checked when set.
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	Issuer    string
+	Audience  string
+}
+
+func NewHS256Verifier(secret []byte) *JWTVerifier {
+	return &JWTVerifier{secret: secret}
+}
+
+// NewRS256Verifier parses a PEM-encoded RSA public key (PKIX or PKCS #1).
+func NewRS256Verifier(pemData []byte) (*JWTVerifier, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("auth: clé publique PEM invalide")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return &JWTVerifier{publicKey: key}, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("auth: clé publique invalide: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("auth: la clé publique n'est pas une clé RSA")
+	}
+	return &JWTVerifier{publicKey: key}, nil
+}
+
+// LoadJWTVerifier builds the verifier from JWT_HS256_SECRET (or
+// JWT_HS256_SECRET_FILE) or from the PEM file named by
+// JWT_RS256_PUBLIC_KEY_FILE, with the optional JWT_ISSUER and JWT_AUDIENCE.
+func LoadJWTVerifier() (*JWTVerifier, error) {
+	var v *JWTVerifier
+	secret := os.Getenv("JWT_HS256_SECRET")
+	if path := os.Getenv("JWT_HS256_SECRET_FILE"); secret == "" && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("lecture de JWT_HS256_SECRET_FILE: %w", err)
+		}
+		secret = strings.TrimSpace(string(data))
+	}
+	keyFile := os.Getenv("JWT_RS256_PUBLIC_KEY_FILE")
+
+	switch {
+	case secret != "" && keyFile != "":
+		return nil, errors.New("auth: JWT_HS256_SECRET et JWT_RS256_PUBLIC_KEY_FILE sont exclusifs")
+	case secret != "":
+		v = NewHS256Verifier([]byte(secret))
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("lecture de JWT_RS256_PUBLIC_KEY_FILE: %w", err)
+		}
+		if v, err = NewRS256Verifier(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrJWTNotConfigured
+	}
+	v.Issuer = os.Getenv("JWT_ISSUER")
+	v.Audience = os.Getenv("JWT_AUDIENCE")
+	return v, nil
+}
+
+func (v *JWTVerifier) algorithm() string {
+	if v.publicKey != nil {
+		return "RS256"
+	}
+	return "HS256"
+}
+
+// Claims are the registered claims of a token and its scopes, read from the
+// space-separated "scope" claim or the "scp" array.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Scp       []string `json:"scp"`
+	Scopes    []string `json:"-"`
+}
+
+// audience accepts the single string and the array forms of "aud".
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// looksLikeJWT tells a JWT from an API key: JWTs have three dot-separated
+// parts.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify checks the signature and the time, issuer and audience claims of a
+// compact JWT. Only the algorithm the verifier was configured with is
+// accepted, whatever the token header says.
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jeton JWT mal formé")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("en-tête JWT invalide: %w", err)
+	}
+	if header.Alg != v.algorithm() {
+		return nil, fmt.Errorf("algorithme JWT %q refusé, %s attendu", header.Alg, v.algorithm())
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("signature JWT mal encodée")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	if err := v.verifySignature(signed, signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("revendications JWT invalides: %w", err)
+	}
+	if err := v.checkClaims(&claims, time.Now()); err != nil {
+		return nil, err
+	}
+	claims.Scopes = append(strings.Fields(claims.Scope), claims.Scp...)
+	return &claims, nil
+}
+
+func (v *JWTVerifier) verifySignature(signed, signature []byte) error {
+	if v.publicKey != nil {
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("signature JWT invalide")
+		}
+		return nil
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write(signed)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.New("signature JWT invalide")
+	}
+	return nil
+}
+
+func (v *JWTVerifier) checkClaims(c *Claims, now time.Time) error {
+	if c.ExpiresAt == 0 {
+		return errors.New("jeton JWT sans expiration")
+	}
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(jwtLeeway)) {
+		return errors.New("jeton JWT expiré")
+	}
+	if c.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("jeton JWT pas encore valide")
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("émetteur JWT %q refusé", c.Issuer)
+	}
+	if v.Audience != "" && !containsString(c.Audience, v.Audience) {
+		return errors.New("jeton JWT destiné à une autre audience")
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
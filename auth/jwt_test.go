@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret")
+
+// signToken builds a compact JWT; alg picks the signature, "none" leaving it
+// empty.
+func signToken(t *testing.T, alg string, claims map[string]any, hsKey []byte, rsKey *rsa.PrivateKey) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, hsKey)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		digest := sha256.Sum256([]byte(signed))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, rsKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newRSAKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestVerify(t *testing.T) {
+	rsKey, publicPEM := newRSAKey(t)
+	otherKey, _ := newRSAKey(t)
+	rs256, err := NewRS256Verifier(publicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs256 := NewHS256Verifier(testSecret)
+	checked := NewHS256Verifier(testSecret)
+	checked.Issuer, checked.Audience = "https://sso.example.com", "cryptoprice"
+
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := map[string]any{"sub": "alice", "exp": exp, "scope": "read:prices read:db"}
+	withClaims := func(extra map[string]any) map[string]any {
+		claims := map[string]any{"sub": "alice", "exp": exp}
+		for k, v := range extra {
+			claims[k] = v
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		scopes   []string
+		err      string
+	}{
+		{"HS256", hs256, signToken(t, "HS256", valid, testSecret, nil), []string{"read:prices", "read:db"}, ""},
+		{"RS256", rs256, signToken(t, "RS256", valid, nil, rsKey), []string{"read:prices", "read:db"}, ""},
+		{"scp array", hs256, signToken(t, "HS256", withClaims(map[string]any{"scp": []string{"admin"}}), testSecret, nil), []string{"admin"}, ""},
+		// The public key is no HMAC secret: the verifier only accepts its own
+		// algorithm.
+		{"HS256 signed with the public key", rs256, signToken(t, "HS256", valid, publicPEM, nil), nil, `algorithme JWT "HS256" refusé`},
+		{"none", rs256, signToken(t, "none", valid, nil, nil), nil, `algorithme JWT "none" refusé`},
+		{"none on HS256", hs256, signToken(t, "none", valid, nil, nil), nil, `algorithme JWT "none" refusé`},
+		{"RS256 on HS256", hs256, signToken(t, "RS256", valid, nil, rsKey), nil, `algorithme JWT "RS256" refusé`},
+		{"HS256 bad signature", hs256, signToken(t, "HS256", valid, []byte("other-secret"), nil), nil, "signature JWT invalide"},
+		{"RS256 bad signature", rs256, signToken(t, "RS256", valid, nil, otherKey), nil, "signature JWT invalide"},
+		{"tampered claims", hs256, tamper(signToken(t, "HS256", valid, testSecret, nil), withClaims(map[string]any{"scope": "admin"})), nil, "signature JWT invalide"},
+		{"no expiration", hs256, signToken(t, "HS256", map[string]any{"sub": "alice"}, testSecret, nil), nil, "sans expiration"},
+		{"expired", hs256, signToken(t, "HS256", withClaims(map[string]any{"exp": time.Now().Add(-2 * jwtLeeway).Unix()}), testSecret, nil), nil, "expiré"},
+		{"not yet valid", hs256, signToken(t, "HS256", withClaims(map[string]any{"nbf": time.Now().Add(2 * jwtLeeway).Unix()}), testSecret, nil), nil, "pas encore valide"},
+		{"issuer and audience", checked, signToken(t, "HS256", withClaims(map[string]any{"iss": "https://sso.example.com", "aud": "cryptoprice"}), testSecret, nil), nil, ""},
+		{"audience array", checked, signToken(t, "HS256", withClaims(map[string]any{"iss": "https://sso.example.com", "aud": []string{"other", "cryptoprice"}}), testSecret, nil), nil, ""},
+		{"wrong issuer", checked, signToken(t, "HS256", withClaims(map[string]any{"iss": "https://evil.example.com", "aud": "cryptoprice"}), testSecret, nil), nil, "émetteur JWT"},
+		{"no issuer", checked, signToken(t, "HS256", withClaims(map[string]any{"aud": "cryptoprice"}), testSecret, nil), nil, "émetteur JWT"},
+		{"wrong audience", checked, signToken(t, "HS256", withClaims(map[string]any{"iss": "https://sso.example.com", "aud": "other"}), testSecret, nil), nil, "autre audience"},
+		{"wrong audience array", checked, signToken(t, "HS256", withClaims(map[string]any{"iss": "https://sso.example.com", "aud": []string{"other"}}), testSecret, nil), nil, "autre audience"},
+		{"malformed", hs256, "a.b", nil, "mal formé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Verify(tt.token)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Verify = %+v, %v, want an error containing %q", claims, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.Subject != "alice" || (tt.scopes != nil && strings.Join(claims.Scopes, " ") != strings.Join(tt.scopes, " ")) {
+				t.Errorf("claims %+v, want subject alice and scopes %v", claims, tt.scopes)
+			}
+		})
+	}
+}
+
+// tamper replaces the claims of a token, keeping its signature.
+func tamper(token string, claims map[string]any) string {
+	parts := strings.Split(token, ".")
+	payload, _ := json.Marshal(claims)
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
+
+// Expiration and not-before are checked with jwtLeeway of tolerance on each
+// side.
+func TestCheckClaimsLeeway(t *testing.T) {
+	now := time.Unix(1718020800, 0)
+	v := NewHS256Verifier(testSecret)
+
+	tests := []struct {
+		name   string
+		claims Claims
+		err    string
+	}{
+		{"expired within the leeway", Claims{ExpiresAt: now.Add(-jwtLeeway).Unix()}, ""},
+		{"expired past the leeway", Claims{ExpiresAt: now.Add(-jwtLeeway - time.Second).Unix()}, "expiré"},
+		{"not before within the leeway", Claims{ExpiresAt: now.Add(time.Hour).Unix(), NotBefore: now.Add(jwtLeeway).Unix()}, ""},
+		{"not before past the leeway", Claims{ExpiresAt: now.Add(time.Hour).Unix(), NotBefore: now.Add(jwtLeeway + time.Second).Unix()}, "pas encore valide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.checkClaims(&tt.claims, now)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("checkClaims = %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("checkClaims = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+}
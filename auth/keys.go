@@ -0,0 +1,85 @@
+// Package auth authenticates the clients of the HTTP API with API keys
+// stored in the database or with JWTs issued by an external SSO, and
+// checks the scopes they were granted.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Scopes granted to API keys and JWTs. ScopeAdmin grants every scope.
+const (
+	ScopeReadPrices = "read:prices"
+	ScopeReadDB     = "read:db"
+	ScopeAdmin      = "admin"
+)
+
+// Scopes lists the known scopes.
+var Scopes = []string{ScopeReadPrices, ScopeReadDB, ScopeAdmin}
+
+const (
+	// keyMarker starts every API key, so that a leaked key is easy to
+	// recognize.
+	keyMarker = "cpk_"
+	// prefixLength is the number of characters of the key, marker included,
+	// stored in clear to identify it.
+	prefixLength = len(keyMarker) + 8
+)
+
+// ParseScopes parses a comma- or space-separated list of scopes.
+func ParseScopes(s string) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("au moins un scope est requis (%s)", strings.Join(Scopes, ", "))
+	}
+	var scopes []string
+	for _, f := range fields {
+		if !isKnownScope(f) {
+			return nil, fmt.Errorf("scope inconnu %q (disponibles: %s)", f, strings.Join(Scopes, ", "))
+		}
+		scopes = append(scopes, f)
+	}
+	return scopes, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func hasScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateKey returns a new random API key, its prefix and its hash. Only
+// the prefix and the hash are meant to be stored.
+func GenerateKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = keyMarker + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:prefixLength], HashKey(key), nil
+}
+
+// HashKey returns the hex SHA-256 of an API key. Keys are random 256-bit
+// values, so a fast unsalted hash is enough to make the stored hashes
+// useless to an attacker.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// APIKeyHeader carries an API key; keys and JWTs are also accepted as an
+// Authorization bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// Authentication methods of a Principal.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+const principalKey = "auth.principal"
+
+// Principal is the authenticated client of a request. KeyID is zero for
+// JWTs.
+type Principal struct {
+	Method  string
+	KeyID   int64
+	Subject string
+	Scopes  []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return hasScope(p.Scopes, scope)
+}
+
+// PrincipalFrom returns the client authenticated by the middleware, or nil
+// on public routes.
+func PrincipalFrom(c *gin.Context) *Principal {
+	if p, ok := c.Get(principalKey); ok {
+		return p.(*Principal)
+	}
+	return nil
+}
+
+// Authenticator checks API keys against the database and, when a verifier
+// is set, JWTs issued by the SSO.
+type Authenticator struct {
+	db  *database.DB
+	jwt *JWTVerifier
+}
+
+func NewAuthenticator(db *database.DB) *Authenticator {
+	return &Authenticator{db: db}
+}
+
+func (a *Authenticator) SetJWTVerifier(v *JWTVerifier) {
+	a.jwt = v
+}
+
+// Middleware enforces the scope returned by scopeFor for the matched route;
+// routes with no scope stay public. The usage of API keys is recorded once
+// the request completes.
+func (a *Authenticator) Middleware(scopeFor func(method, route string) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := scopeFor(c.Request.Method, c.FullPath())
+		if scope == "" {
+			c.Next()
+			return
+		}
+
+		token := requestToken(c.Request)
+		if token == "" {
+			abortUnauthorized(c, errors.New("clé API ou jeton manquant"))
+			return
+		}
+		principal, err := a.authenticate(c, token)
+		var lookupErr *lookupError
+		if errors.As(err, &lookupErr) {
+			slog.ErrorContext(c.Request.Context(), "échec de la vérification de la clé API", logging.Err(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la vérification de la clé API"})
+			return
+		}
+		if err != nil {
+			abortUnauthorized(c, err)
+			return
+		}
+		if !principal.HasScope(scope) {
+			slog.WarnContext(c.Request.Context(), "accès refusé", "subject", principal.Subject, "scope", scope)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("le scope %s est requis", scope)})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(
+			attribute.String("enduser.id", principal.Subject),
+			attribute.String("auth.method", principal.Method),
+		)
+
+		c.Next()
+
+		if principal.KeyID != 0 {
+			db := a.db.WithContext(c.Request.Context())
+			if err := db.RecordAPIKeyUsage(principal.KeyID, c.FullPath(), time.Now(), c.Writer.Status() >= 400); err != nil {
+				slog.ErrorContext(c.Request.Context(), "échec de l'enregistrement de l'utilisation de la clé API", logging.Err(err))
+			}
+		}
+	}
+}
+
+func requestToken(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func (a *Authenticator) authenticate(c *gin.Context, token string) (*Principal, error) {
+	if looksLikeJWT(token) {
+		if a.jwt == nil {
+			return nil, errors.New("authentification JWT non configurée")
+		}
+		claims, err := a.jwt.Verify(token)
+		if err != nil {
+			return nil, err
+		}
+		return &Principal{Method: MethodJWT, Subject: claims.Subject, Scopes: claims.Scopes}, nil
+	}
+
+	key, err := a.db.WithContext(c.Request.Context()).GetAPIKeyByHash(HashKey(token))
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		return nil, errors.New("clé API invalide")
+	case err != nil:
+		return nil, &lookupError{err}
+	case key.RevokedAt != nil:
+		return nil, errors.New("clé API révoquée")
+	case key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt):
+		return nil, errors.New("clé API expirée")
+	}
+	return &Principal{Method: MethodAPIKey, KeyID: key.ID, Subject: key.Name, Scopes: key.Scopes}, nil
+}
+
+// lookupError is a database failure while checking a key, as opposed to
+// invalid credentials.
+type lookupError struct {
+	err error
+}
+
+func (e *lookupError) Error() string { return e.err.Error() }
+
+func (e *lookupError) Unwrap() error { return e.err }
+
+func abortUnauthorized(c *gin.Context, err error) {
+	slog.WarnContext(c.Request.Context(), "échec de l'authentification", "client_ip", c.ClientIP(), logging.Err(err))
+	c.Header("WWW-Authenticate", `Bearer realm="cryptoprice"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
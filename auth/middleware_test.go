@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createKey stores a key with the given scopes and returns it in clear.
+func createKey(t *testing.T, db *database.DB, name string, scopes []string, expiresAt *time.Time) (string, int64) {
+	t.Helper()
+	key, prefix, hash, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := &models.APIKey{Name: name, Prefix: prefix, Hash: hash, Scopes: scopes, ExpiresAt: expiresAt}
+	if err := db.CreateAPIKey(stored); err != nil {
+		t.Fatal(err)
+	}
+	return key, stored.ID
+}
+
+func newTestEngine(a *Authenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(a.Middleware(func(method, route string) string {
+		switch route {
+		case "/public":
+			return ""
+		case "/db":
+			return ScopeReadDB
+		default:
+			return ScopeReadPrices
+		}
+	}))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, PrincipalFrom(c).Subject) }
+	r.GET("/public", func(c *gin.Context) { c.String(http.StatusOK, "") })
+	r.GET("/prices", ok)
+	r.GET("/db", ok)
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	db := newTestDB(t)
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	pricesKey, pricesID := createKey(t, db, "prices", []string{ScopeReadPrices}, &future)
+	adminKey, _ := createKey(t, db, "admin", []string{ScopeAdmin}, nil)
+	expiredKey, _ := createKey(t, db, "expired", []string{ScopeReadPrices}, &past)
+	revokedKey, revokedID := createKey(t, db, "revoked", []string{ScopeReadPrices}, nil)
+	if err := db.RevokeAPIKey(revokedID); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAuthenticator(db)
+	a.SetJWTVerifier(NewHS256Verifier(testSecret))
+	r := newTestEngine(a)
+	jwt := func(scope string) string {
+		return signToken(t, "HS256", map[string]any{"sub": "alice", "exp": future.Unix(), "scope": scope}, testSecret, nil)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		header  string
+		token   string
+		status  int
+		subject string
+	}{
+		{"public", "/public", "", "", http.StatusOK, ""},
+		{"missing", "/prices", "", "", http.StatusUnauthorized, ""},
+		{"unknown key", "/prices", APIKeyHeader, "cpk_unknown", http.StatusUnauthorized, ""},
+		{"key header", "/prices", APIKeyHeader, pricesKey, http.StatusOK, "prices"},
+		{"key bearer", "/prices", "Authorization", "Bearer " + pricesKey, http.StatusOK, "prices"},
+		{"expired key", "/prices", APIKeyHeader, expiredKey, http.StatusUnauthorized, ""},
+		{"revoked key", "/prices", APIKeyHeader, revokedKey, http.StatusUnauthorized, ""},
+		{"missing scope", "/db", APIKeyHeader, pricesKey, http.StatusForbidden, ""},
+		{"admin implies read:db", "/db", APIKeyHeader, adminKey, http.StatusOK, "admin"},
+		{"admin implies read:prices", "/prices", APIKeyHeader, adminKey, http.StatusOK, "admin"},
+		{"jwt", "/prices", "Authorization", "Bearer " + jwt("read:prices"), http.StatusOK, "alice"},
+		{"jwt missing scope", "/db", "Authorization", "Bearer " + jwt("read:prices"), http.StatusForbidden, ""},
+		{"jwt admin", "/db", "Authorization", "Bearer " + jwt("admin"), http.StatusOK, "alice"},
+		{"jwt bad signature", "/prices", "Authorization", "Bearer " + jwt("read:prices") + "x", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate header on a 401")
+			}
+			if tt.status == http.StatusOK && w.Body.String() != tt.subject {
+				t.Errorf("subject %q, want %q", w.Body, tt.subject)
+			}
+		})
+	}
+
+	// The usage of a key is recorded once it was let in: the refused scope
+	// does not count.
+	usage, err := db.GetAPIKeyUsageFromDB(pricesID, time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests, failures int64
+	for _, u := range usage {
+		requests += u.Requests
+		failures += u.Errors
+	}
+	if requests != 2 || failures != 0 {
+		t.Errorf("usage %+v, want 2 requests without errors", usage)
+	}
+}
+
+func TestMiddlewareJWTNotConfigured(t *testing.T) {
+	r := newTestEngine(NewAuthenticator(newTestDB(t)))
+	req := httptest.NewRequest(http.MethodGet, "/prices", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", map[string]any{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}, testSecret, nil))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// A database failure is no reason to reject the client's credentials.
+func TestMiddlewareLookupFailure(t *testing.T) {
+	db := newTestDB(t)
+	key, _ := createKey(t, db, "prices", []string{ScopeReadPrices}, nil)
+	r := newTestEngine(NewAuthenticator(db))
+	db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/prices", nil)
+	req.Header.Set(APIKeyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+}
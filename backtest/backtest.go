@@ -0,0 +1,287 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/stats"
+)
+
+// ErrInvalidRequest marks the errors of RunFromDB caused by the request, such
+// as an unknown strategy, invalid parameters or too few candles, as opposed
+// to a failure of the database.
+var ErrInvalidRequest = errors.New("backtest invalide")
+
+type Side string
+
+const (
+	Buy  Side = "buy"
+	Sell Side = "sell"
+)
+
+// Order is a market order. When Quantity is zero, Percent (0-1] is the share
+// of the available cash (buy) or of the position (sell) to trade.
+type Order struct {
+	Side     Side
+	Quantity float64
+	Percent  float64
+}
+
+type Position struct {
+	Cash     float64
+	Quantity float64
+	AvgPrice float64
+}
+
+// Strategy is called with every closed candle. Returned orders are filled
+// at the open of the next candle.
+type Strategy interface {
+	Name() string
+	OnCandle(c candles.Candle, pos Position) []Order
+}
+
+type Config struct {
+	InitialCash float64
+	FeeBps      float64
+	SlippageBps float64
+}
+
+type Fill struct {
+	Time     time.Time `json:"time"`
+	Side     Side      `json:"side"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Fee      float64   `json:"fee"`
+}
+
+// Trade is a round trip, from a flat position back to flat.
+type Trade struct {
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	PnL        float64   `json:"pnl"`
+	Return     float64   `json:"return"`
+	Open       bool      `json:"open"`
+}
+
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+type Report struct {
+	Strategy    string        `json:"strategy"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Candles     int           `json:"candles"`
+	InitialCash float64       `json:"initial_cash"`
+	FinalEquity float64       `json:"final_equity"`
+	TotalReturn float64       `json:"total_return"`
+	CAGR        float64       `json:"cagr"`
+	MaxDrawdown float64       `json:"max_drawdown"`
+	Sharpe      float64       `json:"sharpe"`
+	WinRate     float64       `json:"win_rate"`
+	TotalFees   float64       `json:"total_fees"`
+	Exposure    float64       `json:"exposure"`
+	BuyAndHold  float64       `json:"buy_and_hold_return"`
+	Trades      []Trade       `json:"trades"`
+	Fills       []Fill        `json:"fills"`
+	EquityCurve []EquityPoint `json:"equity_curve"`
+}
+
+// broker simulates market fills with proportional fees and slippage, long
+// only.
+type broker struct {
+	cfg      Config
+	pos      Position
+	fees     float64
+	fills    []Fill
+	trades   []Trade
+	current  *Trade
+	invested float64
+	received float64
+}
+
+func (b *broker) execute(o Order, c candles.Candle) {
+	slip := b.cfg.SlippageBps / 10000
+	feeRate := b.cfg.FeeBps / 10000
+
+	switch o.Side {
+	case Buy:
+		price := c.Open * (1 + slip)
+		qty := o.Quantity
+		if qty == 0 {
+			qty = b.pos.Cash * math.Min(o.Percent, 1) / (price * (1 + feeRate))
+		}
+		qty = math.Min(qty, b.pos.Cash/(price*(1+feeRate)))
+		if qty <= 0 {
+			return
+		}
+		notional := qty * price
+		fee := notional * feeRate
+		b.pos.AvgPrice = (b.pos.AvgPrice*b.pos.Quantity + notional) / (b.pos.Quantity + qty)
+		b.pos.Quantity += qty
+		b.pos.Cash -= notional + fee
+		b.fees += fee
+		b.fills = append(b.fills, Fill{Time: c.Time, Side: Buy, Quantity: qty, Price: price, Fee: fee})
+
+		if b.current == nil {
+			b.current = &Trade{EntryTime: c.Time}
+			b.invested, b.received = 0, 0
+		}
+		b.invested += notional + fee
+		b.current.Quantity += qty
+
+	case Sell:
+		price := c.Open * (1 - slip)
+		qty := o.Quantity
+		if qty == 0 {
+			qty = b.pos.Quantity * math.Min(o.Percent, 1)
+		}
+		qty = math.Min(qty, b.pos.Quantity)
+		if qty <= 0 {
+			return
+		}
+		notional := qty * price
+		fee := notional * feeRate
+		b.pos.Quantity -= qty
+		b.pos.Cash += notional - fee
+		b.fees += fee
+		b.received += notional - fee
+		b.fills = append(b.fills, Fill{Time: c.Time, Side: Sell, Quantity: qty, Price: price, Fee: fee})
+
+		if b.pos.Quantity <= 1e-12 {
+			b.pos.Quantity, b.pos.AvgPrice = 0, 0
+			b.closeTrade(c.Time, false)
+		}
+	}
+}
+
+func (b *broker) closeTrade(t time.Time, open bool) {
+	if b.current == nil {
+		return
+	}
+	tr := b.current
+	tr.ExitTime = t
+	tr.Open = open
+	tr.PnL = b.received - b.invested
+	if b.invested > 0 {
+		tr.Return = tr.PnL / b.invested
+	}
+	if tr.Quantity > 0 {
+		tr.EntryPrice = b.invested / tr.Quantity
+		tr.ExitPrice = b.received / tr.Quantity
+	}
+	b.trades = append(b.trades, *tr)
+	b.current = nil
+}
+
+func Run(s Strategy, data []candles.Candle, cfg Config, interval time.Duration) (*Report, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("au moins deux bougies sont nécessaires, %d disponibles", len(data))
+	}
+	if cfg.InitialCash <= 0 {
+		return nil, fmt.Errorf("le capital initial doit être positif")
+	}
+
+	b := &broker{cfg: cfg, pos: Position{Cash: cfg.InitialCash}}
+	report := &Report{
+		Strategy:    s.Name(),
+		From:        data[0].Time,
+		To:          data[len(data)-1].Time,
+		Candles:     len(data),
+		InitialCash: cfg.InitialCash,
+	}
+
+	var pending []Order
+	equity := make([]float64, 0, len(data))
+	exposed := 0
+	for _, c := range data {
+		for _, o := range pending {
+			b.execute(o, c)
+		}
+		if b.pos.Quantity > 0 {
+			exposed++
+		}
+
+		value := b.pos.Cash + b.pos.Quantity*c.Close
+		equity = append(equity, value)
+		report.EquityCurve = append(report.EquityCurve, EquityPoint{Time: c.Time, Equity: value})
+
+		pending = s.OnCandle(c, b.pos)
+	}
+
+	last := data[len(data)-1]
+	if b.current != nil {
+		// Mark the open position at the last close.
+		b.received += b.pos.Quantity * last.Close
+		b.closeTrade(last.Time, true)
+	}
+
+	report.FinalEquity = equity[len(equity)-1]
+	report.TotalReturn = report.FinalEquity/cfg.InitialCash - 1
+	if years := report.To.Sub(report.From).Hours() / (365 * 24); years > 0 && report.FinalEquity > 0 {
+		report.CAGR = math.Pow(report.FinalEquity/cfg.InitialCash, 1/years) - 1
+	}
+	report.MaxDrawdown, _, _ = stats.MaxDrawdown(equity)
+	report.Sharpe = stats.Sharpe(stats.LogReturns(equity), stats.PeriodsPerYear(interval))
+	report.TotalFees = b.fees
+	report.Exposure = float64(exposed) / float64(len(data))
+	if data[0].Close > 0 {
+		report.BuyAndHold = last.Close/data[0].Close - 1
+	}
+
+	report.Trades = b.trades
+	report.Fills = b.fills
+	if report.Trades == nil {
+		report.Trades = []Trade{}
+	}
+	if report.Fills == nil {
+		report.Fills = []Fill{}
+	}
+	wins := 0
+	for _, t := range report.Trades {
+		if t.PnL > 0 {
+			wins++
+		}
+	}
+	if len(report.Trades) > 0 {
+		report.WinRate = float64(wins) / float64(len(report.Trades))
+	}
+
+	return report, nil
+}
+
+type Request struct {
+	Pair     string
+	Strategy string
+	Params   map[string]float64
+	Interval time.Duration
+	From     time.Time
+	To       time.Time
+	Config
+}
+
+// RunFromDB backtests a built-in strategy over the candles stored for the
+// pair.
+func RunFromDB(db *database.DB, req Request) (*Report, error) {
+	strategy, err := New(req.Strategy, req.Params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	data, err := candles.Load(db, req.Pair, req.From, req.To, req.Interval)
+	if err != nil {
+		return nil, err
+	}
+	report, err := Run(strategy, data, req.Config, req.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return report, nil
+}
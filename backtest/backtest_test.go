@@ -0,0 +1,161 @@
+package backtest
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/indicators"
+)
+
+// scripted places the orders given for the candle it is called with.
+type scripted map[int][]Order
+
+func (s scripted) Name() string { return "scripted" }
+
+func (s scripted) OnCandle(c candles.Candle, pos Position) []Order {
+	return s[c.Time.Day()-1]
+}
+
+func dailyCandles(prices ...[2]float64) []candles.Candle {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]candles.Candle, len(prices))
+	for i, p := range prices {
+		data[i] = candles.Candle{Time: start.AddDate(0, 0, i), Open: p[0], High: p[0], Low: p[1], Close: p[1]}
+	}
+	return data
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-6*math.Max(1, math.Abs(want))
+}
+
+// A winning then a losing round trip with 1% fees and 0.5% slippage,
+// computed by hand: buying with all the cash at 100 × 1.005 gets
+// 10000 / (100.5 × 1.01) = 98.5173 units, for 9900.99 plus 99.01 of fees.
+func TestRunFillsFeesAndMetrics(t *testing.T) {
+	data := dailyCandles([2]float64{100, 100}, [2]float64{100, 105}, [2]float64{120, 120}, [2]float64{110, 100}, [2]float64{99, 99})
+	strategy := scripted{
+		0: {{Side: Buy, Percent: 1}},
+		1: {{Side: Sell, Percent: 1}},
+		2: {{Side: Buy, Percent: 1}},
+		3: {{Side: Sell, Percent: 1}},
+	}
+	report, err := Run(strategy, data, Config{InitialCash: 10000, FeeBps: 100, SlippageBps: 50}, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFills := []Fill{
+		{Time: data[1].Time, Side: Buy, Quantity: 98.51731441800898, Price: 100.5, Fee: 99.00990099009901},
+		{Time: data[2].Time, Side: Sell, Quantity: 98.51731441800898, Price: 119.4, Fee: 117.62967341510273},
+		{Time: data[3].Time, Side: Buy, Quantity: 104.2970356865105, Price: 110.55, Fee: 115.30037295143734},
+		{Time: data[4].Time, Side: Sell, Quantity: 104.2970356865105, Price: 98.505, Fee: 102.73779500299716},
+	}
+	if len(report.Fills) != len(wantFills) {
+		t.Fatalf("fills %+v, want %+v", report.Fills, wantFills)
+	}
+	for i, want := range wantFills {
+		got := report.Fills[i]
+		if !got.Time.Equal(want.Time) || got.Side != want.Side || !near(got.Quantity, want.Quantity) ||
+			!near(got.Price, want.Price) || !near(got.Fee, want.Fee) {
+			t.Errorf("fill %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	if len(report.Trades) != 2 || !near(report.Trades[0].PnL, 1645.3376680951715) || !near(report.Trades[1].PnL, -1474.295962798451) {
+		t.Errorf("trades %+v", report.Trades)
+	}
+	wantEquity := []float64{10000, 10344.318013890943, 11645.337668095171, 10429.703568651052, 10171.04170529672}
+	for i, want := range wantEquity {
+		if !near(report.EquityCurve[i].Equity, want) {
+			t.Errorf("equity %d = %v, want %v", i, report.EquityCurve[i].Equity, want)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"final equity", report.FinalEquity, 10171.04170529672},
+		{"total return", report.TotalReturn, 0.01710417052967},
+		// 4 days: (10171.04 / 10000)^(365/4) - 1.
+		{"CAGR", report.CAGR, 3.6999793784525483},
+		// From the 11645.34 peak down to 10171.04.
+		{"max drawdown", report.MaxDrawdown, 0.12659967489286222},
+		{"win rate", report.WinRate, 0.5},
+		{"total fees", report.TotalFees, 434.6777423596362},
+		{"exposure", report.Exposure, 0.4},
+		{"buy and hold", report.BuyAndHold, -0.01},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// An order is capped by the cash or the position, and a position still open
+// at the end is marked at the last close.
+func TestRunCapsOrdersAndMarksOpenPosition(t *testing.T) {
+	data := dailyCandles([2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 110}, [2]float64{110, 120})
+	strategy := scripted{
+		0: {{Side: Buy, Quantity: 1000}},
+		1: {{Side: Sell, Quantity: 40}, {Side: Sell, Quantity: 1000}, {Side: Buy, Quantity: 50}},
+	}
+	report, err := Run(strategy, data, Config{InitialCash: 10000}, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQty := []float64{100, 40, 60, 50}
+	if len(report.Fills) != len(wantQty) {
+		t.Fatalf("fills %+v", report.Fills)
+	}
+	for i, want := range wantQty {
+		if !near(report.Fills[i].Quantity, want) {
+			t.Errorf("fill %d quantity %v, want %v", i, report.Fills[i].Quantity, want)
+		}
+	}
+	if len(report.Trades) != 2 || report.Trades[0].Open || !report.Trades[1].Open {
+		t.Fatalf("trades %+v", report.Trades)
+	}
+	// 50 units bought at 100, marked at the last close of 120.
+	if open := report.Trades[1]; !near(open.PnL, 1000) || !near(open.ExitPrice, 120) {
+		t.Errorf("open trade %+v", open)
+	}
+	if !near(report.FinalEquity, 11000) || report.WinRate != 0.5 {
+		t.Errorf("final equity %v, win rate %v", report.FinalEquity, report.WinRate)
+	}
+}
+
+func TestNewValidatesParams(t *testing.T) {
+	tests := []struct {
+		strategy string
+		params   map[string]float64
+		err      string
+	}{
+		{"sma_cross", nil, ""},
+		{"sma_cross", map[string]float64{"fast": 5, "slow": indicators.MaxPeriod}, ""},
+		{"sma_cross", map[string]float64{"slow": 1e12}, "slow doit être un entier"},
+		{"sma_cross", map[string]float64{"fast": 2.5}, "fast doit être un entier"},
+		{"sma_cross", map[string]float64{"fast": 0}, "fast doit être un entier"},
+		{"sma_cross", map[string]float64{"fast": 30, "slow": 10}, "fast < slow"},
+		{"rsi_reversion", map[string]float64{"period": indicators.MaxPeriod + 1}, "period doit être un entier"},
+		{"rsi_reversion", map[string]float64{"period": math.Inf(1)}, "period doit être un entier"},
+		{"rsi_reversion", map[string]float64{"lower": 80}, "lower < upper"},
+		{"rsi_reversion", map[string]float64{"window": 3}, "inconnu"},
+		{"macd", nil, "stratégie inconnue"},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.strategy, tt.params)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("New(%s, %v) = %v", tt.strategy, tt.params, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("New(%s, %v) = %v, want an error containing %q", tt.strategy, tt.params, err, tt.err)
+		}
+	}
+}
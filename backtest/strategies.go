@@ -0,0 +1,162 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/indicators"
+)
+
+type factory struct {
+	defaults map[string]float64
+	build    func(p map[string]float64) (Strategy, error)
+}
+
+var registry = map[string]factory{
+	"buy_hold": {
+		defaults: map[string]float64{},
+		build: func(p map[string]float64) (Strategy, error) {
+			return &buyAndHold{}, nil
+		},
+	},
+	"sma_cross": {
+		defaults: map[string]float64{"fast": 10, "slow": 30},
+		build: func(p map[string]float64) (Strategy, error) {
+			fast, err := period(p, "fast", 1)
+			if err != nil {
+				return nil, fmt.Errorf("sma_cross: %v", err)
+			}
+			slow, err := period(p, "slow", 2)
+			if err != nil {
+				return nil, fmt.Errorf("sma_cross: %v", err)
+			}
+			if slow <= fast {
+				return nil, fmt.Errorf("sma_cross: fast < slow requis")
+			}
+			return &smaCross{fast: indicators.NewSMA(fast), slow: indicators.NewSMA(slow)}, nil
+		},
+	},
+	"rsi_reversion": {
+		defaults: map[string]float64{"period": 14, "lower": 30, "upper": 70},
+		build: func(p map[string]float64) (Strategy, error) {
+			n, err := period(p, "period", 2)
+			if err != nil {
+				return nil, fmt.Errorf("rsi_reversion: %v", err)
+			}
+			if p["lower"] >= p["upper"] {
+				return nil, fmt.Errorf("rsi_reversion: lower < upper requis")
+			}
+			return &rsiReversion{rsi: indicators.NewRSI(n), lower: p["lower"], upper: p["upper"]}, nil
+		},
+	},
+}
+
+// period reads the integer period parameter name, between min and
+// indicators.MaxPeriod: the indicators allocate their window up front.
+func period(p map[string]float64, name string, min int) (int, error) {
+	v := p[name]
+	if v != math.Trunc(v) || v < float64(min) || v > indicators.MaxPeriod {
+		return 0, fmt.Errorf("%s doit être un entier entre %d et %d, reçu %v", name, min, indicators.MaxPeriod, v)
+	}
+	return int(v), nil
+}
+
+func Strategies() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds a built-in strategy. Parameters not given take their defaults.
+func New(name string, params map[string]float64) (Strategy, error) {
+	f, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("stratégie inconnue %q (disponibles: %v)", name, Strategies())
+	}
+
+	merged := make(map[string]float64, len(f.defaults))
+	for k, v := range f.defaults {
+		merged[k] = v
+	}
+	for k, v := range params {
+		if _, ok := f.defaults[k]; !ok {
+			return nil, fmt.Errorf("paramètre %q inconnu pour %s", k, name)
+		}
+		merged[k] = v
+	}
+	return f.build(merged)
+}
+
+type buyAndHold struct {
+	bought bool
+}
+
+func (s *buyAndHold) Name() string { return "buy_hold" }
+
+func (s *buyAndHold) OnCandle(c candles.Candle, pos Position) []Order {
+	if s.bought {
+		return nil
+	}
+	s.bought = true
+	return []Order{{Side: Buy, Percent: 1}}
+}
+
+// smaCross goes long when the fast SMA crosses above the slow one and exits
+// when it crosses back below.
+type smaCross struct {
+	fast, slow *indicators.SMA
+	prevDiff   float64
+	hasPrev    bool
+}
+
+func (s *smaCross) Name() string { return "sma_cross" }
+
+func (s *smaCross) OnCandle(c candles.Candle, pos Position) []Order {
+	fast, fastOK := s.fast.Update(c.Close)
+	slow, slowOK := s.slow.Update(c.Close)
+	if !fastOK || !slowOK {
+		return nil
+	}
+
+	diff := fast - slow
+	defer func() { s.prevDiff, s.hasPrev = diff, true }()
+	if !s.hasPrev {
+		return nil
+	}
+
+	switch {
+	case s.prevDiff <= 0 && diff > 0 && pos.Quantity == 0:
+		return []Order{{Side: Buy, Percent: 1}}
+	case s.prevDiff >= 0 && diff < 0 && pos.Quantity > 0:
+		return []Order{{Side: Sell, Percent: 1}}
+	}
+	return nil
+}
+
+// rsiReversion buys when the RSI drops below lower and sells when it rises
+// above upper.
+type rsiReversion struct {
+	rsi          *indicators.RSI
+	lower, upper float64
+}
+
+func (s *rsiReversion) Name() string { return "rsi_reversion" }
+
+func (s *rsiReversion) OnCandle(c candles.Candle, pos Position) []Order {
+	value, ok := s.rsi.Update(c.Close)
+	if !ok {
+		return nil
+	}
+	switch {
+	case value < s.lower && pos.Quantity == 0:
+		return []Order{{Side: Buy, Percent: 1}}
+	case value > s.upper && pos.Quantity > 0:
+		return []Order{{Side: Sell, Percent: 1}}
+	}
+	return nil
+}
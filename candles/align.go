@@ -0,0 +1,110 @@
+package candles
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type FillMode string
+
+const (
+	// FillForward repeats the previous close in missing buckets. Buckets
+	// before a series' first candle cannot be filled and are dropped.
+	FillForward FillMode = "ffill"
+	// FillDrop keeps only the buckets where every series has a candle.
+	FillDrop FillMode = "drop"
+)
+
+func ParseFillMode(s string) (FillMode, error) {
+	switch FillMode(s) {
+	case FillForward, FillDrop:
+		return FillMode(s), nil
+	}
+	return "", fmt.Errorf("unknown fill mode %q (expected ffill or drop)", s)
+}
+
+type Aligned struct {
+	Times  []time.Time          `json:"times"`
+	Closes map[string][]float64 `json:"closes"`
+	// Missing counts, per series, the buckets that had no candle before
+	// filling or dropping.
+	Missing map[string]int `json:"missing"`
+}
+
+// Align puts the closes of several candle series on a common grid of
+// interval-wide buckets spanning all series, then resolves missing buckets
+// according to mode.
+func Align(series map[string][]Candle, interval time.Duration, mode FillMode) Aligned {
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aligned := Aligned{Closes: make(map[string][]float64), Missing: make(map[string]int)}
+
+	var first, last time.Time
+	buckets := make(map[string]map[time.Time]float64, len(names))
+	for _, name := range names {
+		byTime := make(map[time.Time]float64)
+		for _, c := range Resample(series[name], interval) {
+			t := c.Time.Truncate(interval)
+			byTime[t] = c.Close
+			if first.IsZero() || t.Before(first) {
+				first = t
+			}
+			if t.After(last) {
+				last = t
+			}
+		}
+		buckets[name] = byTime
+	}
+	if first.IsZero() {
+		return aligned
+	}
+
+	var grid []time.Time
+	for t := first; !t.After(last); t = t.Add(interval) {
+		grid = append(grid, t)
+	}
+
+	raw := make(map[string][]float64, len(names))
+	for _, name := range names {
+		values := make([]float64, len(grid))
+		prev := math.NaN()
+		for i, t := range grid {
+			v, ok := buckets[name][t]
+			if !ok {
+				aligned.Missing[name]++
+				v = math.NaN()
+				if mode == FillForward {
+					v = prev
+				}
+			}
+			values[i] = v
+			prev = v
+		}
+		raw[name] = values
+	}
+
+	for i, t := range grid {
+		complete := true
+		for _, name := range names {
+			if math.IsNaN(raw[name][i]) {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			continue
+		}
+		aligned.Times = append(aligned.Times, t)
+		for _, name := range names {
+			aligned.Closes[name] = append(aligned.Closes[name], raw[name][i])
+		}
+	}
+
+	return aligned
+}
@@ -0,0 +1,85 @@
+package candles
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+func at(minutes int, close float64) Candle {
+	return Candle{Time: start.Add(time.Duration(minutes) * time.Minute), Open: close, High: close, Low: close, Close: close}
+}
+
+func TestResample(t *testing.T) {
+	data := []Candle{
+		{Time: start.Add(5 * time.Minute), Open: 10, High: 12, Low: 9, Close: 11, Volume: 1},
+		{Time: start.Add(55 * time.Minute), Open: 11, High: 15, Low: 8, Close: 14, Volume: 2},
+		{Time: start.Add(3 * time.Hour), Open: 14, High: 14, Low: 13, Close: 13, Volume: 3},
+	}
+	want := []Candle{
+		{Time: start, Open: 10, High: 15, Low: 8, Close: 14, Volume: 3},
+		{Time: start.Add(3 * time.Hour), Open: 14, High: 14, Low: 13, Close: 13, Volume: 3},
+	}
+	if got := Resample(data, time.Hour); !reflect.DeepEqual(got, want) {
+		t.Errorf("Resample(1h) = %+v, want %+v", got, want)
+	}
+
+	// June 10, 2024 is a Monday, June 13 a Thursday.
+	weekly := Resample([]Candle{{Time: start.Add(3*24*time.Hour + time.Hour), Close: 1}}, 7*24*time.Hour)
+	if len(weekly) != 1 || !weekly[0].Time.Equal(start) {
+		t.Errorf("weekly bucket %+v, want it to start on Monday %v", weekly, start)
+	}
+}
+
+func TestAlign(t *testing.T) {
+	series := map[string][]Candle{
+		// 00:00 closes at 2, then 01:00 and 02:00; 03:00 is missing.
+		"a": {at(0, 1), at(30, 2), at(70, 3), at(125, 4)},
+		// 01:00 is missing.
+		"b": {at(5, 10), at(120, 12), at(180, 13)},
+		// Starts at 01:00.
+		"c": {at(60, 100), at(120, 101), at(180, 102)},
+	}
+	hours := func(h ...int) []time.Time {
+		times := make([]time.Time, len(h))
+		for i, n := range h {
+			times[i] = start.Add(time.Duration(n) * time.Hour)
+		}
+		return times
+	}
+
+	tests := []struct {
+		mode   FillMode
+		names  []string
+		times  []time.Time
+		closes map[string][]float64
+	}{
+		{FillForward, []string{"a", "b"}, hours(0, 1, 2, 3), map[string][]float64{"a": {2, 3, 4, 4}, "b": {10, 10, 12, 13}}},
+		{FillDrop, []string{"a", "b"}, hours(0, 2), map[string][]float64{"a": {2, 4}, "b": {10, 12}}},
+		// The bucket before the first candle of c cannot be filled.
+		{FillForward, []string{"a", "c"}, hours(1, 2, 3), map[string][]float64{"a": {3, 4, 4}, "c": {100, 101, 102}}},
+		{FillDrop, []string{"a", "b", "c"}, hours(2), map[string][]float64{"a": {4}, "b": {12}, "c": {101}}},
+	}
+	for _, tt := range tests {
+		input := make(map[string][]Candle, len(tt.names))
+		for _, name := range tt.names {
+			input[name] = series[name]
+		}
+		got := Align(input, time.Hour, tt.mode)
+		if !reflect.DeepEqual(got.Times, tt.times) || !reflect.DeepEqual(got.Closes, tt.closes) {
+			t.Errorf("%s %v: times %v, closes %v, want %v, %v", tt.mode, tt.names, got.Times, got.Closes, tt.times, tt.closes)
+		}
+		wantMissing := map[string]int{"a": 1, "b": 1, "c": 1}
+		for _, name := range tt.names {
+			if got.Missing[name] != wantMissing[name] {
+				t.Errorf("%s %v: missing %v", tt.mode, tt.names, got.Missing)
+			}
+		}
+	}
+
+	if empty := Align(map[string][]Candle{"a": nil}, time.Hour, FillDrop); len(empty.Times) != 0 {
+		t.Errorf("empty series aligned on %v", empty.Times)
+	}
+}
@@ -0,0 +1,116 @@
+package candles
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const BaseInterval = 5 * time.Minute
+
+// ParseDuration accepts the units understood by time.ParseDuration plus
+// days ("7d") and weeks ("2w").
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("durée vide")
+	}
+
+	unit := s[len(s)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("durée invalide: %s", s)
+		}
+		day := 24 * time.Hour
+		if unit == 'w' {
+			day *= 7
+		}
+		return time.Duration(n * float64(day)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("durée invalide: %s", s)
+	}
+	return d, nil
+}
+
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+}
+
+// FromHistorical converts stored rows into candles sorted by time. The
+// collector may store the same candle several times, the latest row wins.
+func FromHistorical(data []models.HistoricalData) []Candle {
+	sorted := make([]models.HistoricalData, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	result := make([]Candle, 0, len(sorted))
+	for _, d := range sorted {
+		c := Candle{
+			Time:   d.Timestamp,
+			Open:   d.Open,
+			High:   d.High,
+			Low:    d.Low,
+			Close:  d.Close,
+			Volume: d.Volume,
+		}
+		if n := len(result); n > 0 && result[n-1].Time.Equal(c.Time) {
+			result[n-1] = c
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// Resample aggregates sorted candles into buckets of the given interval,
+// truncated like time.Time.Truncate, i.e. from the zero time: buckets of a
+// day or a divisor of a day start at midnight UTC, weekly buckets on
+// Mondays. Empty buckets are omitted.
+func Resample(data []Candle, interval time.Duration) []Candle {
+	if interval <= BaseInterval {
+		return data
+	}
+
+	var result []Candle
+	for _, c := range data {
+		start := c.Time.Truncate(interval)
+		if n := len(result); n > 0 && result[n-1].Time.Equal(start) {
+			last := &result[n-1]
+			last.High = max(last.High, c.High)
+			last.Low = min(last.Low, c.Low)
+			last.Close = c.Close
+			last.Volume += c.Volume
+			continue
+		}
+		c.Time = start
+		result = append(result, c)
+	}
+	return result
+}
+
+// Load reads the stored candles of a pair between from and to and resamples
+// them to interval.
+func Load(db *database.DB, pair string, from, to time.Time, interval time.Duration) ([]Candle, error) {
+	data, err := db.GetCandlesFromDB(pair, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return Resample(FromHistorical(data), interval), nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/auth"
+	"github.com/antonyloussararian/Go-CryptoPrice/backtest"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func runCommand(name string, args []string) error {
+	switch name {
+	case "backtest":
+		return runBacktestCommand(args)
+	case "keys":
+		return runKeysCommand(args)
+	default:
+		return fmt.Errorf("commande inconnue %q (disponibles: backtest, keys)", name)
+	}
+}
+
+func parseParams(s string) (map[string]float64, error) {
+	params := make(map[string]float64)
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("paramètre invalide %q, format attendu: nom=valeur", item)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("valeur invalide pour %s: %q", key, value)
+		}
+		params[strings.TrimSpace(key)] = v
+	}
+	return params, nil
+}
+
+func runBacktestCommand(args []string) error {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	dbPath := fs.String("db", "crypto.db", "chemin de la base SQLite")
+	pair := fs.String("pair", "", "paire à tester, par exemple XXBTZUSD")
+	strategy := fs.String("strategy", "sma_cross", "stratégie: "+strings.Join(backtest.Strategies(), ", "))
+	paramsStr := fs.String("params", "", "paramètres de la stratégie, par exemple fast=10,slow=30")
+	intervalStr := fs.String("interval", "1h", "taille des bougies (5m, 1h, 1d...)")
+	windowStr := fs.String("window", "90d", "période testée, se terminant maintenant")
+	cash := fs.Float64("cash", 10000, "capital initial")
+	feeBps := fs.Float64("fee-bps", 26, "frais par ordre en points de base")
+	slippageBps := fs.Float64("slippage-bps", 5, "glissement par ordre en points de base")
+	asJSON := fs.Bool("json", false, "affiche le rapport complet en JSON")
+	fs.Parse(args)
+
+	if *pair == "" {
+		return fmt.Errorf("-pair est obligatoire")
+	}
+	params, err := parseParams(*paramsStr)
+	if err != nil {
+		return err
+	}
+	interval, err := candles.ParseDuration(*intervalStr)
+	if err != nil {
+		return err
+	}
+	window, err := candles.ParseDuration(*windowStr)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	now := time.Now()
+	report, err := backtest.RunFromDB(db, backtest.Request{
+		Pair:     *pair,
+		Strategy: *strategy,
+		Params:   params,
+		Interval: interval,
+		From:     now.Add(-window),
+		To:       now,
+		Config: backtest.Config{
+			InitialCash: *cash,
+			FeeBps:      *feeBps,
+			SlippageBps: *slippageBps,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Printf("Stratégie      %s sur %s (%s)\n", report.Strategy, *pair, *intervalStr)
+	fmt.Printf("Période        %s → %s (%d bougies)\n", report.From.Format(time.DateTime), report.To.Format(time.DateTime), report.Candles)
+	fmt.Printf("Capital        %.2f → %.2f\n", report.InitialCash, report.FinalEquity)
+	fmt.Printf("Rendement      %.2f%% (buy & hold %.2f%%)\n", report.TotalReturn*100, report.BuyAndHold*100)
+	fmt.Printf("CAGR           %.2f%%\n", report.CAGR*100)
+	fmt.Printf("Max drawdown   %.2f%%\n", report.MaxDrawdown*100)
+	fmt.Printf("Sharpe         %.2f\n", report.Sharpe)
+	fmt.Printf("Trades         %d (%.1f%% gagnants)\n", len(report.Trades), report.WinRate*100)
+	fmt.Printf("Frais          %.2f\n", report.TotalFees)
+	for _, t := range report.Trades {
+		status := ""
+		if t.Open {
+			status = " (ouvert)"
+		}
+		fmt.Printf("  %s → %s  %.6f @ %.2f → %.2f  PnL %.2f (%.2f%%)%s\n",
+			t.EntryTime.Format(time.DateTime), t.ExitTime.Format(time.DateTime),
+			t.Quantity, t.EntryPrice, t.ExitPrice, t.PnL, t.Return*100, status)
+	}
+	return nil
+}
+
+func runKeysCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sous-commande requise: create, list, revoke ou usage")
+	}
+	fs := flag.NewFlagSet("keys "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "crypto.db", "chemin de la base SQLite")
+
+	switch args[0] {
+	case "create":
+		name := fs.String("name", "", "nom du client de la clé")
+		scopesStr := fs.String("scopes", auth.ScopeReadPrices, "scopes: "+strings.Join(auth.Scopes, ", "))
+		expiresStr := fs.String("expires", "", "durée de validité (30d, 1y...), illimitée par défaut")
+		fs.Parse(args[1:])
+		if *name == "" {
+			return fmt.Errorf("-name est obligatoire")
+		}
+		scopes, err := auth.ParseScopes(*scopesStr)
+		if err != nil {
+			return err
+		}
+		key := &models.APIKey{Name: *name, Scopes: scopes}
+		if *expiresStr != "" {
+			validity, err := candles.ParseDuration(*expiresStr)
+			if err != nil {
+				return err
+			}
+			expiresAt := time.Now().Add(validity)
+			key.ExpiresAt = &expiresAt
+		}
+
+		db, err := openKeysDB(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		plain, prefix, hash, err := auth.GenerateKey()
+		if err != nil {
+			return err
+		}
+		key.Prefix, key.Hash = prefix, hash
+		if err := db.CreateAPIKey(key); err != nil {
+			return err
+		}
+		fmt.Printf("Clé %d créée pour %s (%s)\n", key.ID, key.Name, strings.Join(key.Scopes, " "))
+		fmt.Println("Conservez-la, elle ne sera plus affichée :")
+		fmt.Println(plain)
+		return nil
+
+	case "list":
+		fs.Parse(args[1:])
+		db, err := openKeysDB(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		keys, err := db.GetAPIKeys()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNOM\tPRÉFIXE\tSCOPES\tCRÉÉE\tÉTAT\tDERNIÈRE UTILISATION")
+		for _, k := range keys {
+			state := "active"
+			switch {
+			case k.RevokedAt != nil:
+				state = "révoquée"
+			case k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt):
+				state = "expirée"
+			case k.ExpiresAt != nil:
+				state = "expire le " + k.ExpiresAt.Format(time.DateOnly)
+			}
+			lastUsed := "jamais"
+			if k.LastUsedAt != nil {
+				lastUsed = k.LastUsedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, " "),
+				k.CreatedAt.Format(time.DateOnly), state, lastUsed)
+		}
+		return w.Flush()
+
+	case "revoke":
+		id := fs.Int64("id", 0, "identifiant de la clé")
+		fs.Parse(args[1:])
+		if *id == 0 {
+			return fmt.Errorf("-id est obligatoire")
+		}
+		db, err := openKeysDB(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := db.RevokeAPIKey(*id); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return fmt.Errorf("clé %d introuvable ou déjà révoquée", *id)
+			}
+			return err
+		}
+		fmt.Printf("Clé %d révoquée\n", *id)
+		return nil
+
+	case "usage":
+		id := fs.Int64("id", 0, "identifiant de la clé, toutes par défaut")
+		windowStr := fs.String("window", "30d", "période, se terminant aujourd'hui")
+		fs.Parse(args[1:])
+		window, err := candles.ParseDuration(*windowStr)
+		if err != nil {
+			return err
+		}
+		db, err := openKeysDB(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		usage, err := db.GetAPIKeyUsageFromDB(*id, time.Now().Add(-window))
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "JOUR\tCLÉ\tROUTE\tREQUÊTES\tERREURS")
+		for _, u := range usage {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\n", u.Day, u.KeyID, u.Route, u.Requests, u.Errors)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("sous-commande inconnue %q (disponibles: create, list, revoke, usage)", args[0])
+	}
+}
+
+// openKeysDB opens the database and creates the key tables if the server has
+// never run.
+func openKeysDB(path string) (*database.DB, error) {
+	db, err := database.NewDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.InitSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// The methods below are named after the operationId of their endpoint in
+// the OpenAPI document, e.g. PairTrades for "pairTrades".
+
+// call sends the request and decodes the response into a new T.
+func call[T any](ctx context.Context, c *Client, method, path string, query, body any) (*T, error) {
+	var out T
+	if err := c.do(ctx, method, path, query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	return call[Health](ctx, c, http.MethodGet, "/healthz", nil, nil)
+}
+
+// Ready returns the readiness of the service. A service that is not ready
+// answers with a 503, returned as the readiness with an *Error.
+func (c *Client) Ready(ctx context.Context) (*Readiness, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/readyz", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+	}
+	var out Readiness
+	if err := decode(resp, &out); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return &out, &Error{StatusCode: resp.StatusCode, Message: out.Status}
+	}
+	return &out, nil
+}
+
+func (c *Client) ServerStatus(ctx context.Context) (*ServerTime, error) {
+	return call[ServerTime](ctx, c, http.MethodGet, "/api/status", nil, nil)
+}
+
+func (c *Client) StatusHistory(ctx context.Context, q TimeRange) (*StatusHistory, error) {
+	return call[StatusHistory](ctx, c, http.MethodGet, "/api/status/history", q, nil)
+}
+
+func (c *Client) TradingPairs(ctx context.Context) (*TradingPairs, error) {
+	return call[TradingPairs](ctx, c, http.MethodGet, "/api/pairs", nil, nil)
+}
+
+func (c *Client) PairTicker(ctx context.Context, pair string) (*PairTicker, error) {
+	return call[PairTicker](ctx, c, http.MethodGet, escape("/api/pairs/%s", pair), nil, nil)
+}
+
+func (c *Client) Depth(ctx context.Context, pair string, q DepthQuery) (*Depth, error) {
+	return call[Depth](ctx, c, http.MethodGet, escape("/api/pairs/%s/depth", pair), q, nil)
+}
+
+func (c *Client) DepthHistory(ctx context.Context, pair string, q LimitQuery) (*DepthHistory, error) {
+	return call[DepthHistory](ctx, c, http.MethodGet, escape("/api/pairs/%s/depth/history", pair), q, nil)
+}
+
+func (c *Client) PairTrades(ctx context.Context, pair string, q TradesQuery) (*PairTrades, error) {
+	return call[PairTrades](ctx, c, http.MethodGet, escape("/api/pairs/%s/trades", pair), q, nil)
+}
+
+func (c *Client) TradeVolume(ctx context.Context, pair string, q BucketQuery) (*TradeVolume, error) {
+	return call[TradeVolume](ctx, c, http.MethodGet, escape("/api/pairs/%s/trades/volume", pair), q, nil)
+}
+
+func (c *Client) LargeTrades(ctx context.Context, pair string, q LargeTradesQuery) (*LargeTrades, error) {
+	return call[LargeTrades](ctx, c, http.MethodGet, escape("/api/pairs/%s/trades/large", pair), q, nil)
+}
+
+func (c *Client) VWAP(ctx context.Context, pair string, q BucketQuery) (*VWAP, error) {
+	return call[VWAP](ctx, c, http.MethodGet, escape("/api/pairs/%s/vwap", pair), q, nil)
+}
+
+func (c *Client) Spread(ctx context.Context, pair string, q TimeRange) (*Spread, error) {
+	return call[Spread](ctx, c, http.MethodGet, escape("/api/pairs/%s/spread", pair), q, nil)
+}
+
+func (c *Client) Indicators(ctx context.Context, pair string, q IndicatorsQuery) (*Indicators, error) {
+	return call[Indicators](ctx, c, http.MethodGet, escape("/api/pairs/%s/indicators", pair), q, nil)
+}
+
+func (c *Client) PairStats(ctx context.Context, pair string, q StatsQuery) (*PairStats, error) {
+	return call[PairStats](ctx, c, http.MethodGet, escape("/api/pairs/%s/stats", pair), q, nil)
+}
+
+func (c *Client) Stats(ctx context.Context, q MultiStatsQuery) (*Stats, error) {
+	return call[Stats](ctx, c, http.MethodGet, "/api/stats", q, nil)
+}
+
+func (c *Client) Correlation(ctx context.Context, q CorrelationQuery) (*Correlation, error) {
+	return call[Correlation](ctx, c, http.MethodGet, "/api/correlation", q, nil)
+}
+
+func (c *Client) CreatePortfolio(ctx context.Context, req PortfolioRequest) (*models.Portfolio, error) {
+	return call[models.Portfolio](ctx, c, http.MethodPost, "/api/portfolios", nil, req)
+}
+
+func (c *Client) Portfolios(ctx context.Context) (*Portfolios, error) {
+	return call[Portfolios](ctx, c, http.MethodGet, "/api/portfolios", nil, nil)
+}
+
+func (c *Client) Portfolio(ctx context.Context, id int64) (*PortfolioDetail, error) {
+	return call[PortfolioDetail](ctx, c, http.MethodGet, escape("/api/portfolios/%s", id), nil, nil)
+}
+
+func (c *Client) UpdatePortfolio(ctx context.Context, id int64, req PortfolioRequest) (*models.Portfolio, error) {
+	return call[models.Portfolio](ctx, c, http.MethodPut, escape("/api/portfolios/%s", id), nil, req)
+}
+
+func (c *Client) DeletePortfolio(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, escape("/api/portfolios/%s", id), nil, nil, nil)
+}
+
+func (c *Client) CreateHolding(ctx context.Context, portfolioID int64, req HoldingRequest) (*models.Holding, error) {
+	return call[models.Holding](ctx, c, http.MethodPost, escape("/api/portfolios/%s/holdings", portfolioID), nil, req)
+}
+
+func (c *Client) UpdateHolding(ctx context.Context, portfolioID, holdingID int64, req HoldingRequest) (*models.Holding, error) {
+	return call[models.Holding](ctx, c, http.MethodPut, escape("/api/portfolios/%s/holdings/%s", portfolioID, holdingID), nil, req)
+}
+
+func (c *Client) DeleteHolding(ctx context.Context, portfolioID, holdingID int64) error {
+	return c.do(ctx, http.MethodDelete, escape("/api/portfolios/%s/holdings/%s", portfolioID, holdingID), nil, nil, nil)
+}
+
+func (c *Client) Valuation(ctx context.Context, portfolioID int64, q ValuationQuery) (*Valuation, error) {
+	return call[Valuation](ctx, c, http.MethodGet, escape("/api/portfolios/%s/valuation", portfolioID), q, nil)
+}
+
+func (c *Client) CreateTransaction(ctx context.Context, portfolioID int64, req TransactionRequest) (*models.Transaction, error) {
+	return call[models.Transaction](ctx, c, http.MethodPost, escape("/api/portfolios/%s/transactions", portfolioID), nil, req)
+}
+
+func (c *Client) Transactions(ctx context.Context, portfolioID int64) (*Transactions, error) {
+	return call[Transactions](ctx, c, http.MethodGet, escape("/api/portfolios/%s/transactions", portfolioID), nil, nil)
+}
+
+func (c *Client) DeleteTransaction(ctx context.Context, portfolioID, txID int64) error {
+	return c.do(ctx, http.MethodDelete, escape("/api/portfolios/%s/transactions/%s", portfolioID, txID), nil, nil, nil)
+}
+
+// ImportTransactions imports the transactions of a CSV file, in the format
+// given by q.
+func (c *Client) ImportTransactions(ctx context.Context, portfolioID int64, q ImportQuery, csv io.Reader) (*ImportResult, error) {
+	resp, err := c.send(ctx, http.MethodPost, escape("/api/portfolios/%s/transactions/import", portfolioID), q, csv, "text/csv")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	var out ImportResult
+	if err := decode(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) Lots(ctx context.Context, portfolioID int64, q LotsQuery) (*LotReport, error) {
+	return call[LotReport](ctx, c, http.MethodGet, escape("/api/portfolios/%s/lots", portfolioID), q, nil)
+}
+
+func (c *Client) BacktestStrategies(ctx context.Context) (*BacktestStrategies, error) {
+	return call[BacktestStrategies](ctx, c, http.MethodGet, "/api/backtests/strategies", nil, nil)
+}
+
+func (c *Client) RunBacktest(ctx context.Context, req BacktestRequest) (*BacktestResult, error) {
+	return call[BacktestResult](ctx, c, http.MethodPost, "/api/backtests", nil, req)
+}
+
+func (c *Client) CreatePaperAccount(ctx context.Context, req PaperAccountRequest) (*models.PaperAccount, error) {
+	return call[models.PaperAccount](ctx, c, http.MethodPost, "/api/paper/accounts", nil, req)
+}
+
+func (c *Client) PaperAccounts(ctx context.Context) (*PaperAccounts, error) {
+	return call[PaperAccounts](ctx, c, http.MethodGet, "/api/paper/accounts", nil, nil)
+}
+
+func (c *Client) PaperAccount(ctx context.Context, id int64) (*PaperAccountDetail, error) {
+	return call[PaperAccountDetail](ctx, c, http.MethodGet, escape("/api/paper/accounts/%s", id), nil, nil)
+}
+
+func (c *Client) PaperFills(ctx context.Context, accountID int64) (*PaperFills, error) {
+	return call[PaperFills](ctx, c, http.MethodGet, escape("/api/paper/accounts/%s/fills", accountID), nil, nil)
+}
+
+func (c *Client) CreatePaperOrder(ctx context.Context, req PaperOrderRequest) (*models.PaperOrder, error) {
+	return call[models.PaperOrder](ctx, c, http.MethodPost, "/api/paper/orders", nil, req)
+}
+
+func (c *Client) PaperOrders(ctx context.Context, q PaperOrdersQuery) (*PaperOrders, error) {
+	return call[PaperOrders](ctx, c, http.MethodGet, "/api/paper/orders", q, nil)
+}
+
+func (c *Client) CancelPaperOrder(ctx context.Context, id int64) (*models.PaperOrder, error) {
+	return call[models.PaperOrder](ctx, c, http.MethodDelete, escape("/api/paper/orders/%s", id), nil, nil)
+}
+
+func (c *Client) SyncAccount(ctx context.Context) (*AccountSync, error) {
+	return call[AccountSync](ctx, c, http.MethodPost, "/api/account/sync", nil, nil)
+}
+
+func (c *Client) AccountBalances(ctx context.Context, q QuoteQuery) (*AccountBalances, error) {
+	return call[AccountBalances](ctx, c, http.MethodGet, "/api/account/balances", q, nil)
+}
+
+func (c *Client) AccountHistory(ctx context.Context, q AccountHistoryQuery) (*AccountHistory, error) {
+	return call[AccountHistory](ctx, c, http.MethodGet, "/api/account/history", q, nil)
+}
+
+func (c *Client) AccountLedger(ctx context.Context, q LedgerQuery) (*Ledger, error) {
+	return call[Ledger](ctx, c, http.MethodGet, "/api/account/ledger", q, nil)
+}
+
+func (c *Client) AccountReconciliation(ctx context.Context) (*Reconciliation, error) {
+	return call[Reconciliation](ctx, c, http.MethodGet, "/api/account/reconciliation", nil, nil)
+}
+
+func (c *Client) Exchanges(ctx context.Context) (*Exchanges, error) {
+	return call[Exchanges](ctx, c, http.MethodGet, "/api/exchanges", nil, nil)
+}
+
+func (c *Client) ExchangeMarkets(ctx context.Context, exchange string, q MarketsQuery) (*Markets, error) {
+	return call[Markets](ctx, c, http.MethodGet, escape("/api/exchanges/%s/markets", exchange), q, nil)
+}
+
+func (c *Client) ExchangeTicker(ctx context.Context, exchange string, q SymbolQuery) (*VenueTicker, error) {
+	return call[VenueTicker](ctx, c, http.MethodGet, escape("/api/exchanges/%s/ticker", exchange), q, nil)
+}
+
+func (c *Client) ExchangeOHLC(ctx context.Context, exchange string, q OHLCQuery) (*VenueCandles, error) {
+	return call[VenueCandles](ctx, c, http.MethodGet, escape("/api/exchanges/%s/ohlc", exchange), q, nil)
+}
+
+func (c *Client) ExchangeDepth(ctx context.Context, exchange string, q VenueDepthQuery) (*VenueOrderBook, error) {
+	return call[VenueOrderBook](ctx, c, http.MethodGet, escape("/api/exchanges/%s/depth", exchange), q, nil)
+}
+
+func (c *Client) ExchangeTrades(ctx context.Context, exchange string, q VenueTradesQuery) (*VenueTrades, error) {
+	return call[VenueTrades](ctx, c, http.MethodGet, escape("/api/exchanges/%s/trades", exchange), q, nil)
+}
+
+func (c *Client) ComparePrices(ctx context.Context, asset string, q CompareQuery) (*PriceComparison, error) {
+	return call[PriceComparison](ctx, c, http.MethodGet, escape("/api/compare/%s", asset), q, nil)
+}
+
+func (c *Client) CompareHistory(ctx context.Context, asset string, q CompareHistoryQuery) (*CompareHistory, error) {
+	return call[CompareHistory](ctx, c, http.MethodGet, escape("/api/compare/%s/history", asset), q, nil)
+}
+
+func (c *Client) SpreadAlerts(ctx context.Context, asset string, q SpreadAlertsQuery) (*SpreadAlerts, error) {
+	return call[SpreadAlerts](ctx, c, http.MethodGet, escape("/api/compare/%s/alerts", asset), q, nil)
+}
+
+func (c *Client) PriceIndex(ctx context.Context, asset string, q IndexQuery) (*PriceIndex, error) {
+	return call[PriceIndex](ctx, c, http.MethodGet, escape("/api/index/%s", asset), q, nil)
+}
+
+// HistoricalCSV downloads the CSV export of a day.
+func (c *Client) HistoricalCSV(ctx context.Context, q HistoricalQuery) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/api/historical", q, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) DBData(ctx context.Context) (*DBData, error) {
+	return call[DBData](ctx, c, http.MethodGet, "/api/db", nil, nil)
+}
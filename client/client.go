@@ -0,0 +1,229 @@
+// Package client is a typed Go client of the HTTP API. Its request and
+// response types are the ones the OpenAPI document served at /openapi.json
+// is built from.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIKeyHeader carries the API key of the client.
+const APIKeyHeader = "X-API-Key"
+
+// Client calls the API of one service. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	token      string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default client, which times out after 30
+// seconds.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAPIKey authenticates the requests with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates the requests with a JWT.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New returns a client of the API at baseURL, e.g.
+// "http://cryptoprice:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a response with an error status. Message is the "error" field of
+// the body, joined when the API returns a list of errors. RetryAfter is set
+// on a 429.
+type Error struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("cryptoprice: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("cryptoprice: %d %s", e.StatusCode, e.Message)
+}
+
+// do sends a request with a JSON body, if not nil, and decodes the JSON
+// response into out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query, body, out any) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, path, query, reader, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	return decode(resp, out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query any, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.baseURL + path
+	if values := encodeQuery(query); len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.apiKey != "":
+		req.Header.Set(APIKeyHeader, c.apiKey)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.httpClient.Do(req)
+}
+
+func decode(resp *http.Response, out any) error {
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("cryptoprice: invalid response: %w", err)
+	}
+	return nil
+}
+
+// checkResponse returns an *Error for the statuses above 299.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(secs) * time.Second
+	}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && len(body.Error) > 0 {
+		var message string
+		var messages []string
+		switch {
+		case json.Unmarshal(body.Error, &message) == nil:
+			apiErr.Message = message
+		case json.Unmarshal(body.Error, &messages) == nil:
+			apiErr.Message = strings.Join(messages, "; ")
+		}
+	}
+	return apiErr
+}
+
+// encodeQuery encodes the fields of a struct with a "query" tag, including
+// those of its embedded structs. Zero values are left out; times are
+// formatted as RFC 3339 and lists joined with commas.
+func encodeQuery(query any) url.Values {
+	values := url.Values{}
+	if query == nil {
+		return values
+	}
+	v := reflect.ValueOf(query)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return values
+		}
+		v = v.Elem()
+	}
+	encodeFields(v, values)
+	return values
+}
+
+func encodeFields(v reflect.Value, values url.Values) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			encodeFields(value, values)
+			continue
+		}
+		name, _ := QueryName(field)
+		if name == "" || value.IsZero() {
+			continue
+		}
+		switch x := value.Interface().(type) {
+		case time.Time:
+			values.Set(name, x.Format(time.RFC3339))
+		case []string:
+			values.Set(name, strings.Join(x, ","))
+		default:
+			values.Set(name, fmt.Sprint(x))
+		}
+	}
+}
+
+// QueryName reads the "query" tag of a field: the parameter name and
+// whether it is required, as in `query:"symbol,required"`.
+func QueryName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("query")
+	if !ok {
+		return "", false
+	}
+	name, option, _ := strings.Cut(tag, ",")
+	return name, option == "required"
+}
+
+// escape formats a path, escaping its parameters.
+func escape(format string, params ...any) string {
+	escaped := make([]any, len(params))
+	for i, p := range params {
+		escaped[i] = url.PathEscape(fmt.Sprint(p))
+	}
+	return fmt.Sprintf(format, escaped...)
+}
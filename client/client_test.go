@@ -0,0 +1,349 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// request is what the fake API saw of the last call.
+type request struct {
+	method, path, contentType string
+	query                     url.Values
+	header                    http.Header
+	body                      string
+}
+
+func newTestServer(t *testing.T, status int, body string, header http.Header) (*httptest.Server, *request) {
+	t.Helper()
+	var last request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		last = request{
+			method:      r.Method,
+			path:        r.URL.EscapedPath(),
+			contentType: r.Header.Get("Content-Type"),
+			query:       r.URL.Query(),
+			header:      r.Header.Clone(),
+			body:        string(data),
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &last
+}
+
+func TestMethods(t *testing.T) {
+	server, last := newTestServer(t, http.StatusOK, "{}", nil)
+	c := New(server.URL + "/")
+	ctx := context.Background()
+	from := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	week := TimeRange{Window: "7d"}
+
+	tests := []struct {
+		name   string
+		call   func() error
+		method string
+		path   string
+		query  string
+	}{
+		{"Health", func() error { _, err := c.Health(ctx); return err }, "GET", "/healthz", ""},
+		{"Ready", func() error { _, err := c.Ready(ctx); return err }, "GET", "/readyz", ""},
+		{"ServerStatus", func() error { _, err := c.ServerStatus(ctx); return err }, "GET", "/api/status", ""},
+		{"StatusHistory", func() error { _, err := c.StatusHistory(ctx, TimeRange{From: from, To: to}); return err },
+			"GET", "/api/status/history", "from=2024-06-10T00:00:00Z&to=2024-06-11T00:00:00Z"},
+		{"TradingPairs", func() error { _, err := c.TradingPairs(ctx); return err }, "GET", "/api/pairs", ""},
+		{"PairTicker", func() error { _, err := c.PairTicker(ctx, "XBT/USD"); return err }, "GET", "/api/pairs/XBT%2FUSD", ""},
+		// A zero count is left to the server default.
+		{"Depth", func() error { _, err := c.Depth(ctx, "XXBTZUSD", DepthQuery{}); return err }, "GET", "/api/pairs/XXBTZUSD/depth", ""},
+		{"DepthHistory", func() error { _, err := c.DepthHistory(ctx, "XXBTZUSD", LimitQuery{Limit: 10}); return err },
+			"GET", "/api/pairs/XXBTZUSD/depth/history", "limit=10"},
+		{"PairTrades", func() error {
+			_, err := c.PairTrades(ctx, "XXBTZUSD", TradesQuery{TimeRange: TimeRange{Window: "1h"}, Limit: 50})
+			return err
+		}, "GET", "/api/pairs/XXBTZUSD/trades", "window=1h&limit=50"},
+		{"TradeVolume", func() error {
+			_, err := c.TradeVolume(ctx, "XXBTZUSD", BucketQuery{TimeRange: week, Bucket: "1h"})
+			return err
+		}, "GET", "/api/pairs/XXBTZUSD/trades/volume", "window=7d&bucket=1h"},
+		{"LargeTrades", func() error {
+			_, err := c.LargeTrades(ctx, "XXBTZUSD", LargeTradesQuery{MinVolume: 0.5, Limit: 5})
+			return err
+		}, "GET", "/api/pairs/XXBTZUSD/trades/large", "min_volume=0.5&limit=5"},
+		{"VWAP", func() error { _, err := c.VWAP(ctx, "XXBTZUSD", BucketQuery{Bucket: "15m"}); return err },
+			"GET", "/api/pairs/XXBTZUSD/vwap", "bucket=15m"},
+		{"Spread", func() error { _, err := c.Spread(ctx, "XXBTZUSD", TimeRange{From: from}); return err },
+			"GET", "/api/pairs/XXBTZUSD/spread", "from=2024-06-10T00:00:00Z"},
+		{"Indicators", func() error {
+			_, err := c.Indicators(ctx, "XXBTZUSD", IndicatorsQuery{Names: []string{"sma:20", "rsi:14"}, Interval: "4h"})
+			return err
+		}, "GET", "/api/pairs/XXBTZUSD/indicators", "names=sma:20,rsi:14&interval=4h"},
+		{"PairStats", func() error {
+			_, err := c.PairStats(ctx, "XXBTZUSD", StatsQuery{TimeRange: week, Interval: "1d"})
+			return err
+		}, "GET", "/api/pairs/XXBTZUSD/stats", "window=7d&interval=1d"},
+		{"Stats", func() error {
+			_, err := c.Stats(ctx, MultiStatsQuery{Pairs: []string{"XXBTZUSD", "XETHZUSD"}})
+			return err
+		}, "GET", "/api/stats", "pairs=XXBTZUSD,XETHZUSD"},
+		{"Correlation", func() error {
+			_, err := c.Correlation(ctx, CorrelationQuery{Pairs: []string{"XXBTZUSD", "XETHZUSD"}, Fill: "drop", Rolling: 24})
+			return err
+		}, "GET", "/api/correlation", "pairs=XXBTZUSD,XETHZUSD&fill=drop&rolling=24"},
+		{"CreatePortfolio", func() error { _, err := c.CreatePortfolio(ctx, PortfolioRequest{Name: "main"}); return err },
+			"POST", "/api/portfolios", ""},
+		{"Portfolios", func() error { _, err := c.Portfolios(ctx); return err }, "GET", "/api/portfolios", ""},
+		{"Portfolio", func() error { _, err := c.Portfolio(ctx, 3); return err }, "GET", "/api/portfolios/3", ""},
+		{"UpdatePortfolio", func() error { _, err := c.UpdatePortfolio(ctx, 3, PortfolioRequest{Name: "main"}); return err },
+			"PUT", "/api/portfolios/3", ""},
+		{"DeletePortfolio", func() error { return c.DeletePortfolio(ctx, 3) }, "DELETE", "/api/portfolios/3", ""},
+		{"CreateHolding", func() error { _, err := c.CreateHolding(ctx, 3, HoldingRequest{Asset: "BTC"}); return err },
+			"POST", "/api/portfolios/3/holdings", ""},
+		{"UpdateHolding", func() error { _, err := c.UpdateHolding(ctx, 3, 7, HoldingRequest{Quantity: 1}); return err },
+			"PUT", "/api/portfolios/3/holdings/7", ""},
+		{"DeleteHolding", func() error { return c.DeleteHolding(ctx, 3, 7) }, "DELETE", "/api/portfolios/3/holdings/7", ""},
+		{"Valuation", func() error {
+			_, err := c.Valuation(ctx, 3, ValuationQuery{Quote: "EUR", Interval: "1d"})
+			return err
+		}, "GET", "/api/portfolios/3/valuation", "quote=EUR&interval=1d"},
+		{"CreateTransaction", func() error { _, err := c.CreateTransaction(ctx, 3, TransactionRequest{Type: "buy"}); return err },
+			"POST", "/api/portfolios/3/transactions", ""},
+		{"Transactions", func() error { _, err := c.Transactions(ctx, 3); return err }, "GET", "/api/portfolios/3/transactions", ""},
+		{"DeleteTransaction", func() error { return c.DeleteTransaction(ctx, 3, 9) }, "DELETE", "/api/portfolios/3/transactions/9", ""},
+		{"ImportTransactions", func() error {
+			_, err := c.ImportTransactions(ctx, 3, ImportQuery{Format: "kraken"}, strings.NewReader("txid\n"))
+			return err
+		}, "POST", "/api/portfolios/3/transactions/import", "format=kraken"},
+		{"Lots", func() error { _, err := c.Lots(ctx, 3, LotsQuery{Method: "lifo"}); return err },
+			"GET", "/api/portfolios/3/lots", "method=lifo"},
+		{"BacktestStrategies", func() error { _, err := c.BacktestStrategies(ctx); return err }, "GET", "/api/backtests/strategies", ""},
+		{"RunBacktest", func() error { _, err := c.RunBacktest(ctx, BacktestRequest{Pair: "XXBTZUSD"}); return err },
+			"POST", "/api/backtests", ""},
+		{"CreatePaperAccount", func() error { _, err := c.CreatePaperAccount(ctx, PaperAccountRequest{Name: "test"}); return err },
+			"POST", "/api/paper/accounts", ""},
+		{"PaperAccounts", func() error { _, err := c.PaperAccounts(ctx); return err }, "GET", "/api/paper/accounts", ""},
+		{"PaperAccount", func() error { _, err := c.PaperAccount(ctx, 2); return err }, "GET", "/api/paper/accounts/2", ""},
+		{"PaperFills", func() error { _, err := c.PaperFills(ctx, 2); return err }, "GET", "/api/paper/accounts/2/fills", ""},
+		{"CreatePaperOrder", func() error { _, err := c.CreatePaperOrder(ctx, PaperOrderRequest{AccountID: 2}); return err },
+			"POST", "/api/paper/orders", ""},
+		{"PaperOrders", func() error { _, err := c.PaperOrders(ctx, PaperOrdersQuery{AccountID: 2, Status: "open"}); return err },
+			"GET", "/api/paper/orders", "account_id=2&status=open"},
+		{"CancelPaperOrder", func() error { _, err := c.CancelPaperOrder(ctx, 4); return err }, "DELETE", "/api/paper/orders/4", ""},
+		{"SyncAccount", func() error { _, err := c.SyncAccount(ctx); return err }, "POST", "/api/account/sync", ""},
+		{"AccountBalances", func() error { _, err := c.AccountBalances(ctx, QuoteQuery{Quote: "EUR"}); return err },
+			"GET", "/api/account/balances", "quote=EUR"},
+		{"AccountHistory", func() error {
+			_, err := c.AccountHistory(ctx, AccountHistoryQuery{QuoteQuery: QuoteQuery{Quote: "USD"}, TimeRange: week})
+			return err
+		}, "GET", "/api/account/history", "quote=USD&window=7d"},
+		{"AccountLedger", func() error { _, err := c.AccountLedger(ctx, LedgerQuery{Asset: "XXBT", Limit: 20}); return err },
+			"GET", "/api/account/ledger", "asset=XXBT&limit=20"},
+		{"AccountReconciliation", func() error { _, err := c.AccountReconciliation(ctx); return err }, "GET", "/api/account/reconciliation", ""},
+		{"Exchanges", func() error { _, err := c.Exchanges(ctx); return err }, "GET", "/api/exchanges", ""},
+		{"ExchangeMarkets", func() error { _, err := c.ExchangeMarkets(ctx, "binance", MarketsQuery{Base: "BTC"}); return err },
+			"GET", "/api/exchanges/binance/markets", "base=BTC"},
+		{"ExchangeTicker", func() error {
+			_, err := c.ExchangeTicker(ctx, "binance", SymbolQuery{Symbol: "BTC/USD"})
+			return err
+		}, "GET", "/api/exchanges/binance/ticker", "symbol=BTC/USD"},
+		{"ExchangeOHLC", func() error {
+			_, err := c.ExchangeOHLC(ctx, "coinbase", OHLCQuery{SymbolQuery: SymbolQuery{Symbol: "BTC/USD"}, Since: from})
+			return err
+		}, "GET", "/api/exchanges/coinbase/ohlc", "symbol=BTC/USD&since=2024-06-10T00:00:00Z"},
+		{"ExchangeDepth", func() error {
+			_, err := c.ExchangeDepth(ctx, "kraken", VenueDepthQuery{SymbolQuery: SymbolQuery{Symbol: "BTC/USD"}, Count: 10})
+			return err
+		}, "GET", "/api/exchanges/kraken/depth", "symbol=BTC/USD&count=10"},
+		{"ExchangeTrades", func() error {
+			_, err := c.ExchangeTrades(ctx, "kraken", VenueTradesQuery{SymbolQuery: SymbolQuery{Symbol: "BTC/USD"}})
+			return err
+		}, "GET", "/api/exchanges/kraken/trades", "symbol=BTC/USD"},
+		{"ComparePrices", func() error { _, err := c.ComparePrices(ctx, "BTC", CompareQuery{ThresholdBps: 25}); return err },
+			"GET", "/api/compare/BTC", "threshold_bps=25"},
+		{"CompareHistory", func() error {
+			_, err := c.CompareHistory(ctx, "BTC", CompareHistoryQuery{CompareQuery: CompareQuery{Quote: "EUR"}, TimeRange: week})
+			return err
+		}, "GET", "/api/compare/BTC/history", "quote=EUR&window=7d"},
+		{"SpreadAlerts", func() error { _, err := c.SpreadAlerts(ctx, "BTC", SpreadAlertsQuery{TimeRange: week}); return err },
+			"GET", "/api/compare/BTC/alerts", "window=7d"},
+		// A false bool is a zero value.
+		{"PriceIndex", func() error { _, err := c.PriceIndex(ctx, "BTC", IndexQuery{Method: "median"}); return err },
+			"GET", "/api/index/BTC", "method=median"},
+		{"live PriceIndex", func() error { _, err := c.PriceIndex(ctx, "BTC", IndexQuery{Live: true}); return err },
+			"GET", "/api/index/BTC", "live=true"},
+		{"HistoricalCSV", func() error { _, err := c.HistoricalCSV(ctx, HistoricalQuery{Date: "2024-06-10"}); return err },
+			"GET", "/api/historical", "date=2024-06-10"},
+		{"DBData", func() error { _, err := c.DBData(ctx); return err }, "GET", "/api/db", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != nil {
+				t.Fatal(err)
+			}
+			want, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last.method != tt.method || last.path != tt.path || !reflect.DeepEqual(last.query, want) {
+				t.Errorf("%s %s?%s, want %s %s?%s", last.method, last.path, last.query.Encode(), tt.method, tt.path, want.Encode())
+			}
+			wantType := ""
+			switch {
+			case tt.name == "ImportTransactions":
+				wantType = "text/csv"
+			case last.body != "":
+				wantType = "application/json"
+			}
+			if last.contentType != wantType {
+				t.Errorf("content type %q, want %q", last.contentType, wantType)
+			}
+		})
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	server, last := newTestServer(t, http.StatusCreated, `{"id":5,"name":"main","base_currency":"EUR"}`, nil)
+	c := New(server.URL)
+
+	p, err := c.CreatePortfolio(context.Background(), PortfolioRequest{Name: "main", BaseCurrency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 5 || p.BaseCurrency != "EUR" {
+		t.Errorf("portfolio %+v", p)
+	}
+	var sent map[string]any
+	if err := json.Unmarshal([]byte(last.body), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"name": "main", "base_currency": "EUR"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("body %v, want %v", sent, want)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	server, last := newTestServer(t, http.StatusOK, "{}", nil)
+
+	tests := []struct {
+		opts       []Option
+		key, authz string
+	}{
+		{nil, "", ""},
+		{[]Option{WithAPIKey("secret")}, "secret", ""},
+		{[]Option{WithBearerToken("jwt")}, "", "Bearer jwt"},
+		// The API key wins over the token.
+		{[]Option{WithBearerToken("jwt"), WithAPIKey("secret")}, "secret", ""},
+	}
+	for _, tt := range tests {
+		if _, err := New(server.URL, tt.opts...).Health(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := last.header.Get(APIKeyHeader); got != tt.key {
+			t.Errorf("API key %q, want %q", got, tt.key)
+		}
+		if got := last.header.Get("Authorization"); got != tt.authz {
+			t.Errorf("Authorization %q, want %q", got, tt.authz)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		header http.Header
+		want   Error
+	}{
+		{"message", http.StatusNotFound, `{"error":"paire inconnue"}`, nil, Error{StatusCode: 404, Message: "paire inconnue"}},
+		{"list", http.StatusBadRequest, `{"error":["EOrder:Order minimum not met","EGeneral:Invalid arguments:volume"]}`, nil,
+			Error{StatusCode: 400, Message: "EOrder:Order minimum not met; EGeneral:Invalid arguments:volume"}},
+		{"rate limited", http.StatusTooManyRequests, `{"error":"trop de requêtes"}`, http.Header{"Retry-After": {"30"}},
+			Error{StatusCode: 429, Message: "trop de requêtes", RetryAfter: 30 * time.Second}},
+		{"no body", http.StatusBadGateway, "", nil, Error{StatusCode: 502}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestServer(t, tt.status, tt.body, tt.header)
+			_, err := New(server.URL).TradingPairs(context.Background())
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || *apiErr != tt.want {
+				t.Errorf("error %v, want %+v", err, tt.want)
+			}
+		})
+	}
+}
+
+// A service that is not ready still returns its readiness.
+func TestReadyUnavailable(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusServiceUnavailable, `{"status":"unavailable"}`, nil)
+	ready, err := New(server.URL).Ready(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("error %v, want a 503", err)
+	}
+	if ready == nil || ready.Status != "unavailable" {
+		t.Errorf("readiness %+v", ready)
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		query    any
+		field    string
+		name     string
+		required bool
+	}{
+		{IndicatorsQuery{}, "Names", "names", true},
+		{IndicatorsQuery{}, "Interval", "interval", false},
+		{MultiStatsQuery{}, "Pairs", "pairs", true},
+		{CorrelationQuery{}, "Pairs", "pairs", true},
+		{PaperOrdersQuery{}, "AccountID", "account_id", true},
+		{SymbolQuery{}, "Symbol", "symbol", true},
+		{TimeRange{}, "From", "from", false},
+		// Embedded structs carry no tag of their own.
+		{OHLCQuery{}, "SymbolQuery", "", false},
+	}
+	for _, tt := range tests {
+		field, ok := reflect.TypeOf(tt.query).FieldByName(tt.field)
+		if !ok {
+			t.Fatalf("%T has no field %s", tt.query, tt.field)
+		}
+		if name, required := QueryName(field); name != tt.name || required != tt.required {
+			t.Errorf("%T.%s: %q, %v, want %q, %v", tt.query, tt.field, name, required, tt.name, tt.required)
+		}
+	}
+}
+
+// Required parameters are encoded like the others.
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		query any
+		want  string
+	}{
+		{nil, ""},
+		{(*TimeRange)(nil), ""},
+		{&OHLCQuery{SymbolQuery: SymbolQuery{Symbol: "BTC/USD"}, Interval: "1d"}, "interval=1d&symbol=BTC%2FUSD"},
+		{IndicatorsQuery{Names: []string{"macd"}}, "names=macd"},
+		{MultiStatsQuery{Pairs: []string{"A", "B"}, StatsQuery: StatsQuery{Interval: "1h"}}, "interval=1h&pairs=A%2CB"},
+		{CompareQuery{ThresholdBps: 12.5}, "threshold_bps=12.5"},
+	}
+	for _, tt := range tests {
+		if got := encodeQuery(tt.query).Encode(); got != tt.want {
+			t.Errorf("encodeQuery(%+v) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import "time"
+
+// The query parameters of the endpoints. Fields left to their zero value
+// are not sent, so the server defaults apply. Durations such as Window or
+// Interval are strings like "30m", "24h" or "7d".
+
+// TimeRange selects From to To, or the Window ending at To (now by
+// default). Every endpoint has its own default window.
+type TimeRange struct {
+	From   time.Time `query:"from" doc:"Start of the range (RFC 3339, YYYY-MM-DD or Unix timestamp)"`
+	To     time.Time `query:"to" doc:"End of the range, now by default"`
+	Window string    `query:"window" doc:"Length of the range ending at to when from is not set, e.g. 24h or 7d"`
+}
+
+type LimitQuery struct {
+	Limit int `query:"limit" doc:"Maximum number of items"`
+}
+
+type DepthQuery struct {
+	Count int `query:"count" doc:"Number of levels per side, from 1 to 500 (100 by default)"`
+}
+
+type TradesQuery struct {
+	TimeRange
+	Limit int `query:"limit" doc:"Maximum number of trades (1000 by default)"`
+}
+
+type BucketQuery struct {
+	TimeRange
+	Bucket string `query:"bucket" doc:"Width of the buckets of the series, e.g. 1h; no series when not set"`
+}
+
+type LargeTradesQuery struct {
+	TimeRange
+	MinVolume float64 `query:"min_volume" doc:"Minimum volume of a trade"`
+	Multiple  float64 `query:"multiple" doc:"Without min_volume, the multiple of the average volume above which a trade is large (10 by default)"`
+	Limit     int     `query:"limit" doc:"Maximum number of trades, most recent first (1000 by default)"`
+}
+
+type IndicatorsQuery struct {
+	Names    []string `query:"names,required" doc:"Comma-separated indicators with their parameters, e.g. sma:20,rsi:14,macd (sma, ema, rsi, macd, bbands or atr)"`
+	Interval string   `query:"interval" doc:"Candle interval, 5m minimum (1h by default)"`
+	Limit    int      `query:"limit" doc:"Number of points per indicator (100 by default)"`
+}
+
+type StatsQuery struct {
+	TimeRange
+	Interval string `query:"interval" doc:"Candle interval, 5m minimum (1h by default)"`
+}
+
+type MultiStatsQuery struct {
+	Pairs []string `query:"pairs,required" doc:"Comma-separated pairs"`
+	StatsQuery
+}
+
+type CorrelationQuery struct {
+	Pairs []string `query:"pairs,required" doc:"Comma-separated pairs, at least two"`
+	TimeRange
+	Interval string `query:"interval" doc:"Candle interval, 5m minimum (1h by default)"`
+	Fill     string `query:"fill" doc:"Handling of missing candles: ffill or drop (ffill by default)"`
+	Rolling  int    `query:"rolling" doc:"Window, in intervals, of a rolling correlation between exactly two pairs (3 minimum)"`
+}
+
+type ValuationQuery struct {
+	Quote string `query:"quote" doc:"Currency of the valuation, the portfolio base currency by default"`
+	TimeRange
+	Interval string `query:"interval" doc:"Interval of the value series (1d by default)"`
+}
+
+type ImportQuery struct {
+	Format string `query:"format" doc:"csv (default) or kraken, for a Kraken ledger export"`
+}
+
+type LotsQuery struct {
+	Method string `query:"method" doc:"fifo (default), lifo or average"`
+}
+
+type PaperOrdersQuery struct {
+	AccountID int64  `query:"account_id,required" doc:"Paper trading account"`
+	Status    string `query:"status" doc:"open, filled, canceled or rejected"`
+}
+
+type QuoteQuery struct {
+	Quote string `query:"quote" doc:"Valuation currency (USD by default)"`
+}
+
+type AccountHistoryQuery struct {
+	QuoteQuery
+	TimeRange
+}
+
+type LedgerQuery struct {
+	Asset string `query:"asset" doc:"Kraken asset code"`
+	TimeRange
+	Limit int `query:"limit" doc:"Maximum number of entries (500 by default)"`
+}
+
+type MarketsQuery struct {
+	Base  string `query:"base" doc:"Base asset filter"`
+	Quote string `query:"quote" doc:"Quote asset filter"`
+}
+
+type SymbolQuery struct {
+	Symbol string `query:"symbol,required" doc:"Market, canonical (BTC/USD) or native to the exchange"`
+}
+
+type OHLCQuery struct {
+	SymbolQuery
+	Interval string    `query:"interval" doc:"Candle interval (1h by default)"`
+	Since    time.Time `query:"since" doc:"Oldest candle"`
+}
+
+type VenueDepthQuery struct {
+	SymbolQuery
+	Count int `query:"count" doc:"Number of levels per side (100 by default)"`
+}
+
+type VenueTradesQuery struct {
+	SymbolQuery
+	Limit int `query:"limit" doc:"Maximum number of trades (100 by default)"`
+}
+
+type CompareQuery struct {
+	Quote        string  `query:"quote" doc:"Quote currency (USD by default); the asset may also be a full pair name"`
+	ThresholdBps float64 `query:"threshold_bps" doc:"Spread, in basis points, above which the venues are in alert (SPREAD_ALERT_BPS by default)"`
+}
+
+type CompareHistoryQuery struct {
+	CompareQuery
+	TimeRange
+}
+
+type SpreadAlertsQuery struct {
+	Quote string `query:"quote" doc:"Quote currency (USD by default)"`
+	TimeRange
+}
+
+type IndexQuery struct {
+	Quote  string `query:"quote" doc:"Quote currency of the index (USD by default)"`
+	Method string `query:"method" doc:"vwap (default) or median"`
+	TimeRange
+	Live bool `query:"live" doc:"Compute the index from the current tickers instead of the latest stored point"`
+}
+
+type HistoricalQuery struct {
+	Date string `query:"date" doc:"Day of the CSV file (YYYY-MM-DD), the latest file by default"`
+}
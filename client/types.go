@@ -0,0 +1,822 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// ErrorResponse is the body of the error responses. Error is a message, or
+// a list of messages for a rejected paper order.
+type ErrorResponse struct {
+	Error json.RawMessage `json:"error"`
+}
+
+// Health and readiness.
+
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Readiness is returned by /readyz with a 200 when Status is "ready" and a
+// 503 when it is "not_ready".
+type Readiness struct {
+	Status string          `json:"status"`
+	Checks ReadinessChecks `json:"checks"`
+}
+
+type ReadinessChecks struct {
+	Database   DatabaseCheck   `json:"database"`
+	Collection CollectionCheck `json:"collection"`
+	Kraken     KrakenCheck     `json:"kraken"`
+}
+
+type DatabaseCheck struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// CollectionCheck is "paused" during a Kraken maintenance.
+type CollectionCheck struct {
+	Status        string     `json:"status"`
+	MaxAgeSeconds float64    `json:"max_age_seconds"`
+	LastSuccess   *time.Time `json:"last_success,omitempty"`
+	AgeSeconds    *float64   `json:"age_seconds,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// KrakenCheck is "degraded" when Kraken is reachable but not online.
+// Circuit is the state of the circuit breaker of the Kraken client.
+type KrakenCheck struct {
+	Status       string     `json:"status"`
+	Cached       bool       `json:"cached"`
+	SystemStatus string     `json:"system_status"`
+	LatencyMs    float64    `json:"latency_ms"`
+	CheckedAt    time.Time  `json:"checked_at"`
+	Circuit      string     `json:"circuit"`
+	SystemTime   *time.Time `json:"system_time,omitempty"`
+	ClockSkewMs  *float64   `json:"clock_skew_ms,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Kraken status and pairs.
+
+// ServerTime is Kraken's /public/Time response, passed through.
+type ServerTime struct {
+	Error  []string `json:"error"`
+	Result struct {
+		UnixTime int64  `json:"unixtime"`
+		RFC1123  string `json:"rfc1123"`
+	} `json:"result"`
+}
+
+type StatusHistory struct {
+	From             time.Time             `json:"from"`
+	To               time.Time             `json:"to"`
+	Checks           int                   `json:"checks"`
+	Timeline         []StatusPeriod        `json:"timeline"`
+	Transitions      []models.ServerStatus `json:"transitions"`
+	DurationsSeconds map[string]float64    `json:"durations_seconds"`
+	ClockSkewMs      *ClockSkewStats       `json:"clock_skew_ms"`
+}
+
+type StatusPeriod struct {
+	Status          string    `json:"status"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Checks          int       `json:"checks"`
+}
+
+type ClockSkewStats struct {
+	Latest float64 `json:"latest"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+// TradingPairs holds the top 10 pairs by volume. When Stale is true,
+// because Kraken failed, the pairs come from the latest collection.
+type TradingPairs struct {
+	Pairs map[string]AssetPair `json:"pairs"`
+	Count int                  `json:"count"`
+	Stale bool                 `json:"stale,omitempty"`
+	AsOf  *time.Time           `json:"as_of,omitempty"`
+}
+
+// AssetPair is an entry of Kraken's AssetPairs with V, today's and the 24
+// hours volume of its ticker. Kraken's other fields, such as altname or
+// pair_decimals, are not listed and are missing from the stored pairs.
+type AssetPair struct {
+	Base  string   `json:"base"`
+	Quote string   `json:"quote"`
+	V     []string `json:"v"`
+}
+
+// PairTicker is Kraken's /public/Ticker response, passed through. When
+// Stale is true, because Kraken failed, Result holds the latest stored
+// ticker of the pair.
+type PairTicker struct {
+	Error  []string          `json:"error"`
+	Result map[string]Ticker `json:"result"`
+	Stale  bool              `json:"stale,omitempty"`
+	AsOf   *time.Time        `json:"as_of,omitempty"`
+}
+
+// Ticker is a Kraken ticker. The arrays hold today's figure then the 24
+// hours one; A and B start with the price, C is the last trade's price and
+// volume. Only the 24 hours figures are stored, so a stored ticker repeats
+// them for today and has no lot volume in A and B.
+type Ticker struct {
+	A []string `json:"a"`
+	B []string `json:"b"`
+	C []string `json:"c"`
+	V []string `json:"v"`
+	P []string `json:"p"`
+	T []int64  `json:"t"`
+	L []string `json:"l"`
+	H []string `json:"h"`
+	O string   `json:"o"`
+}
+
+// Market data of a pair.
+
+type Depth struct {
+	Pair      string                  `json:"pair"`
+	Timestamp time.Time               `json:"timestamp"`
+	Bids      []models.OrderBookLevel `json:"bids"`
+	Asks      []models.OrderBookLevel `json:"asks"`
+	Metrics   models.DepthMetrics     `json:"metrics"`
+}
+
+type DepthHistory struct {
+	Pair      string                     `json:"pair"`
+	Snapshots []models.OrderBookSnapshot `json:"snapshots"`
+	Count     int                        `json:"count"`
+}
+
+type PairTrades struct {
+	Pair   string         `json:"pair"`
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Trades []models.Trade `json:"trades"`
+	Count  int            `json:"count"`
+}
+
+type SideVolume struct {
+	BuyVolume    float64 `json:"buy_volume"`
+	SellVolume   float64 `json:"sell_volume"`
+	BuyCount     int     `json:"buy_count"`
+	SellCount    int     `json:"sell_count"`
+	BuyNotional  float64 `json:"buy_notional"`
+	SellNotional float64 `json:"sell_notional"`
+	BuyRatio     float64 `json:"buy_ratio"`
+}
+
+type VolumeBucket struct {
+	Start time.Time `json:"start"`
+	SideVolume
+}
+
+// TradeVolume has Buckets when a bucket width is requested.
+type TradeVolume struct {
+	Pair    string         `json:"pair"`
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Total   SideVolume     `json:"total"`
+	Buckets []VolumeBucket `json:"buckets,omitempty"`
+}
+
+type LargeTrades struct {
+	Pair      string         `json:"pair"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	MinVolume float64        `json:"min_volume"`
+	Trades    []models.Trade `json:"trades"`
+	Count     int            `json:"count"`
+}
+
+type VWAPPoint struct {
+	Start  time.Time `json:"start"`
+	VWAP   float64   `json:"vwap"`
+	Volume float64   `json:"volume"`
+	Trades int       `json:"trades"`
+}
+
+// VWAP has Buckets when a bucket width is requested.
+type VWAP struct {
+	Pair    string      `json:"pair"`
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	VWAP    float64     `json:"vwap"`
+	Volume  float64     `json:"volume"`
+	Trades  int         `json:"trades"`
+	Buckets []VWAPPoint `json:"buckets,omitempty"`
+}
+
+type SpreadSummary struct {
+	AvgSpreadBps    float64 `json:"avg_spread_bps"`
+	MinSpreadBps    float64 `json:"min_spread_bps"`
+	MaxSpreadBps    float64 `json:"max_spread_bps"`
+	LatestSpreadBps float64 `json:"latest_spread_bps"`
+}
+
+// Spread has a Summary when the series is not empty.
+type Spread struct {
+	Pair    string               `json:"pair"`
+	From    time.Time            `json:"from"`
+	To      time.Time            `json:"to"`
+	Series  []models.SpreadPoint `json:"series"`
+	Count   int                  `json:"count"`
+	Summary *SpreadSummary       `json:"summary,omitempty"`
+}
+
+type IndicatorPoint struct {
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Indicators holds the series of every requested indicator, by canonical
+// name such as "sma:20".
+type Indicators struct {
+	Pair       string                      `json:"pair"`
+	Interval   string                      `json:"interval"`
+	Indicators map[string][]IndicatorPoint `json:"indicators"`
+}
+
+type ReturnPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type StatsSummary struct {
+	Observations         int           `json:"observations"`
+	From                 time.Time     `json:"from"`
+	To                   time.Time     `json:"to"`
+	FirstClose           float64       `json:"first_close"`
+	LastClose            float64       `json:"last_close"`
+	PercentChange        float64       `json:"percent_change"`
+	MeanReturn           float64       `json:"mean_return"`
+	Volatility           float64       `json:"volatility"`
+	AnnualizedVolatility float64       `json:"annualized_volatility"`
+	Sharpe               float64       `json:"sharpe"`
+	Skewness             float64       `json:"skewness"`
+	Kurtosis             float64       `json:"kurtosis"`
+	MaxDrawdown          float64       `json:"max_drawdown"`
+	MaxDrawdownPeak      time.Time     `json:"max_drawdown_peak"`
+	MaxDrawdownTrough    time.Time     `json:"max_drawdown_trough"`
+	LogReturns           []ReturnPoint `json:"log_returns"`
+}
+
+// StatsResult gives the statistics over the range and the percent changes
+// over 24h, 7d and 30d.
+type StatsResult struct {
+	Summary        StatsSummary       `json:"summary"`
+	PercentChanges map[string]float64 `json:"percent_changes"`
+}
+
+type PairStats struct {
+	Pair     string `json:"pair"`
+	Interval string `json:"interval"`
+	StatsResult
+}
+
+type Stats struct {
+	Interval string                 `json:"interval"`
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	Pairs    map[string]StatsResult `json:"pairs"`
+	Count    int                    `json:"count"`
+}
+
+type RollingCorrelation struct {
+	Time     time.Time `json:"time"`
+	Pearson  float64   `json:"pearson"`
+	Spearman float64   `json:"spearman"`
+}
+
+// Correlation has the Pearson and Spearman matrices, in the order of Pairs,
+// or the Series of a rolling correlation when Rolling is set.
+type Correlation struct {
+	Pairs        []string             `json:"pairs"`
+	Interval     string               `json:"interval"`
+	From         time.Time            `json:"from"`
+	To           time.Time            `json:"to"`
+	Fill         string               `json:"fill"`
+	Observations int                  `json:"observations"`
+	Missing      map[string]int       `json:"missing"`
+	Rolling      int                  `json:"rolling,omitempty"`
+	Series       []RollingCorrelation `json:"series,omitempty"`
+	Pearson      [][]float64          `json:"pearson,omitempty"`
+	Spearman     [][]float64          `json:"spearman,omitempty"`
+}
+
+// Portfolios.
+
+type PortfolioRequest struct {
+	Name         string `json:"name"`
+	BaseCurrency string `json:"base_currency,omitempty"`
+}
+
+// HoldingRequest creates or updates a holding; the asset cannot be
+// changed.
+type HoldingRequest struct {
+	Asset     string  `json:"asset,omitempty"`
+	Quantity  float64 `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"`
+}
+
+type Portfolios struct {
+	Portfolios []models.Portfolio `json:"portfolios"`
+	Count      int                `json:"count"`
+}
+
+type PortfolioDetail struct {
+	Portfolio models.Portfolio `json:"portfolio"`
+	Holdings  []models.Holding `json:"holdings"`
+}
+
+type HoldingValuation struct {
+	HoldingID  int64     `json:"holding_id"`
+	Asset      string    `json:"asset"`
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	PriceAsOf  time.Time `json:"price_as_of"`
+	Value      float64   `json:"value"`
+	CostBasis  float64   `json:"cost_basis"`
+	PnL        float64   `json:"pnl"`
+	PnLPercent float64   `json:"pnl_percent"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type ValuePoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type Valuation struct {
+	PortfolioID     int64              `json:"portfolio_id"`
+	Quote           string             `json:"quote"`
+	AsOf            time.Time          `json:"as_of"`
+	Holdings        []HoldingValuation `json:"holdings"`
+	TotalValue      float64            `json:"total_value"`
+	TotalCost       float64            `json:"total_cost"`
+	TotalPnL        float64            `json:"total_pnl"`
+	TotalPnLPercent float64            `json:"total_pnl_percent"`
+	Series          []ValuePoint       `json:"series"`
+	SeriesError     string             `json:"series_error,omitempty"`
+}
+
+// TransactionRequest records a transaction; Type is buy, sell,
+// transfer_in, transfer_out or fee, and Time defaults to now.
+type TransactionRequest struct {
+	Time     time.Time `json:"time,omitempty"`
+	Type     string    `json:"type"`
+	Asset    string    `json:"asset"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Quote    string    `json:"quote,omitempty"`
+	Fee      float64   `json:"fee,omitempty"`
+	FeeAsset string    `json:"fee_asset,omitempty"`
+	RefID    string    `json:"refid,omitempty"`
+}
+
+type Transactions struct {
+	Transactions []models.Transaction `json:"transactions"`
+	Count        int                  `json:"count"`
+}
+
+// ImportResult counts the parsed transactions and those already stored.
+type ImportResult struct {
+	Parsed   int `json:"parsed"`
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+type Lot struct {
+	Asset         string    `json:"asset"`
+	TransactionID int64     `json:"transaction_id"`
+	Acquired      time.Time `json:"acquired"`
+	Quantity      float64   `json:"quantity"`
+	CostPerUnit   float64   `json:"cost_per_unit"`
+	Cost          float64   `json:"cost"`
+	Price         float64   `json:"price"`
+	Value         float64   `json:"value"`
+	Unrealized    float64   `json:"unrealized"`
+}
+
+type Disposal struct {
+	Asset         string    `json:"asset"`
+	TransactionID int64     `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Acquired      time.Time `json:"acquired"`
+	Quantity      float64   `json:"quantity"`
+	Proceeds      float64   `json:"proceeds"`
+	CostBasis     float64   `json:"cost_basis"`
+	Gain          float64   `json:"gain"`
+	HoldingDays   int       `json:"holding_days"`
+	LongTerm      bool      `json:"long_term"`
+}
+
+type AssetSummary struct {
+	Asset      string  `json:"asset"`
+	Quantity   float64 `json:"quantity"`
+	Cost       float64 `json:"cost"`
+	Value      float64 `json:"value"`
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+}
+
+type LotReport struct {
+	Method          string         `json:"method"`
+	Currency        string         `json:"currency"`
+	OpenLots        []Lot          `json:"open_lots"`
+	Disposals       []Disposal     `json:"disposals"`
+	Assets          []AssetSummary `json:"assets"`
+	TotalRealized   float64        `json:"total_realized"`
+	TotalUnrealized float64        `json:"total_unrealized"`
+	Warnings        []string       `json:"warnings"`
+}
+
+// Backtests.
+
+type BacktestStrategies struct {
+	Strategies []string `json:"strategies"`
+}
+
+// BacktestRequest runs a strategy over the stored candles. The interval
+// defaults to 1h, the range to the last 90 days, the initial cash to 10000
+// and the fees and slippage to 26 and 5 basis points.
+type BacktestRequest struct {
+	Pair        string             `json:"pair"`
+	Strategy    string             `json:"strategy"`
+	Params      map[string]float64 `json:"params,omitempty"`
+	Interval    string             `json:"interval,omitempty"`
+	From        time.Time          `json:"from,omitempty"`
+	To          time.Time          `json:"to,omitempty"`
+	InitialCash float64            `json:"initial_cash,omitempty"`
+	FeeBps      *float64           `json:"fee_bps,omitempty"`
+	SlippageBps *float64           `json:"slippage_bps,omitempty"`
+}
+
+type BacktestTrade struct {
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	PnL        float64   `json:"pnl"`
+	Return     float64   `json:"return"`
+	Open       bool      `json:"open"`
+}
+
+type BacktestFill struct {
+	Time     time.Time `json:"time"`
+	Side     string    `json:"side"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Fee      float64   `json:"fee"`
+}
+
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+type BacktestReport struct {
+	Strategy    string          `json:"strategy"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Candles     int             `json:"candles"`
+	InitialCash float64         `json:"initial_cash"`
+	FinalEquity float64         `json:"final_equity"`
+	TotalReturn float64         `json:"total_return"`
+	CAGR        float64         `json:"cagr"`
+	MaxDrawdown float64         `json:"max_drawdown"`
+	Sharpe      float64         `json:"sharpe"`
+	WinRate     float64         `json:"win_rate"`
+	TotalFees   float64         `json:"total_fees"`
+	Exposure    float64         `json:"exposure"`
+	BuyAndHold  float64         `json:"buy_and_hold_return"`
+	Trades      []BacktestTrade `json:"trades"`
+	Fills       []BacktestFill  `json:"fills"`
+	EquityCurve []EquityPoint   `json:"equity_curve"`
+}
+
+type BacktestResult struct {
+	Pair        string             `json:"pair"`
+	Interval    string             `json:"interval"`
+	Params      map[string]float64 `json:"params"`
+	FeeBps      float64            `json:"fee_bps"`
+	SlippageBps float64            `json:"slippage_bps"`
+	Report      BacktestReport     `json:"report"`
+}
+
+// Paper trading.
+
+// PaperAccountRequest creates an account funded with InitialBalance of
+// QuoteCurrency, 10000 USD by default.
+type PaperAccountRequest struct {
+	Name           string  `json:"name"`
+	QuoteCurrency  string  `json:"quote_currency,omitempty"`
+	InitialBalance float64 `json:"initial_balance,omitempty"`
+}
+
+// PaperOrderRequest places an order; Side is buy or sell and Type market
+// (default), limit or stop.
+type PaperOrderRequest struct {
+	AccountID  int64   `json:"account_id"`
+	Pair       string  `json:"pair"`
+	Side       string  `json:"side"`
+	Type       string  `json:"type,omitempty"`
+	Quantity   float64 `json:"quantity"`
+	LimitPrice float64 `json:"limit_price,omitempty"`
+	StopPrice  float64 `json:"stop_price,omitempty"`
+}
+
+type PaperAccounts struct {
+	Accounts []models.PaperAccount `json:"accounts"`
+	Count    int                   `json:"count"`
+}
+
+type PaperBalance struct {
+	Asset    string  `json:"asset"`
+	Amount   float64 `json:"amount"`
+	Price    float64 `json:"price"`
+	Value    float64 `json:"value"`
+	Priced   bool    `json:"priced"`
+	Reserved float64 `json:"reserved"`
+}
+
+type PaperAccountDetail struct {
+	Account    models.PaperAccount `json:"account"`
+	Balances   []PaperBalance      `json:"balances"`
+	Equity     float64             `json:"equity"`
+	PnL        float64             `json:"pnl"`
+	PnLPercent float64             `json:"pnl_percent"`
+	Complete   bool                `json:"complete"`
+	OpenOrders int                 `json:"open_orders"`
+}
+
+type PaperFills struct {
+	Fills     []models.PaperFill `json:"fills"`
+	Count     int                `json:"count"`
+	TotalFees float64            `json:"total_fees"`
+}
+
+type PaperOrders struct {
+	Orders []models.PaperOrder `json:"orders"`
+	Count  int                 `json:"count"`
+}
+
+// Kraken account.
+
+type AccountSync struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Assets        int       `json:"assets"`
+	LedgerEntries int       `json:"ledger_entries"`
+}
+
+type AccountBalance struct {
+	Asset  string  `json:"asset"`
+	Code   string  `json:"code"`
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+	Value  float64 `json:"value"`
+	Priced bool    `json:"priced"`
+}
+
+type AccountBalances struct {
+	AsOf     time.Time        `json:"as_of"`
+	Quote    string           `json:"quote"`
+	Balances []AccountBalance `json:"balances"`
+	Total    float64          `json:"total"`
+	Complete bool             `json:"complete"`
+}
+
+type AccountHistoryPoint struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Value     float64            `json:"value"`
+	Complete  bool               `json:"complete"`
+	Balances  map[string]float64 `json:"balances"`
+}
+
+type AccountHistory struct {
+	Quote  string                `json:"quote"`
+	From   time.Time             `json:"from"`
+	To     time.Time             `json:"to"`
+	Points []AccountHistoryPoint `json:"points"`
+	Count  int                   `json:"count"`
+}
+
+type Ledger struct {
+	Entries []models.LedgerEntry `json:"entries"`
+	Count   int                  `json:"count"`
+}
+
+type AssetReconciliation struct {
+	Asset             string     `json:"asset"`
+	Code              string     `json:"code"`
+	Reported          float64    `json:"reported"`
+	LedgerNet         float64    `json:"ledger_net"`
+	LedgerBalance     float64    `json:"ledger_balance"`
+	Entries           int        `json:"entries"`
+	LastEntry         *time.Time `json:"last_entry,omitempty"`
+	NetDifference     float64    `json:"net_difference"`
+	BalanceDifference float64    `json:"balance_difference"`
+	Status            string     `json:"status"`
+	Issues            []string   `json:"issues,omitempty"`
+}
+
+type Reconciliation struct {
+	BalancesAt time.Time             `json:"balances_at"`
+	Assets     []AssetReconciliation `json:"assets"`
+	Mismatches int                   `json:"mismatches"`
+}
+
+// Exchanges.
+
+type Exchanges struct {
+	Exchanges []string `json:"exchanges"`
+}
+
+type Market struct {
+	Symbol   string   `json:"symbol"`
+	Native   string   `json:"native"`
+	Base     string   `json:"base"`
+	Quote    string   `json:"quote"`
+	Active   bool     `json:"active"`
+	AltNames []string `json:"alt_names,omitempty"`
+}
+
+type Markets struct {
+	Exchange string   `json:"exchange"`
+	Markets  []Market `json:"markets"`
+	Count    int      `json:"count"`
+}
+
+type VenueTicker struct {
+	Symbol    string    `json:"symbol"`
+	Native    string    `json:"native"`
+	Last      float64   `json:"last"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	Open      float64   `json:"open"`
+	High24h   float64   `json:"high_24h"`
+	Low24h    float64   `json:"low_24h"`
+	Volume24h float64   `json:"volume_24h"`
+	VWAP24h   float64   `json:"vwap_24h,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+}
+
+type VenueCandles struct {
+	Exchange string   `json:"exchange"`
+	Symbol   string   `json:"symbol"`
+	Interval string   `json:"interval"`
+	Candles  []Candle `json:"candles"`
+	Count    int      `json:"count"`
+}
+
+type VenueOrderBook struct {
+	Symbol    string                  `json:"symbol"`
+	Bids      []models.OrderBookLevel `json:"bids"`
+	Asks      []models.OrderBookLevel `json:"asks"`
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+// VenueTrade is a public trade; Side is the side of the taker.
+type VenueTrade struct {
+	ID     string    `json:"id"`
+	Price  float64   `json:"price"`
+	Volume float64   `json:"volume"`
+	Side   string    `json:"side"`
+	Time   time.Time `json:"time"`
+}
+
+type VenueTrades struct {
+	Exchange string       `json:"exchange"`
+	Symbol   string       `json:"symbol"`
+	Trades   []VenueTrade `json:"trades"`
+	Count    int          `json:"count"`
+}
+
+// Cross-exchange comparison and price index.
+
+type VenueQuote struct {
+	Source    string    `json:"source"`
+	Native    string    `json:"native"`
+	Price     float64   `json:"price"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	Mid       float64   `json:"mid"`
+	SpreadBps float64   `json:"spread_bps"`
+	Volume24h float64   `json:"volume_24h"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CrossVenue compares the last prices of the venues, and the best bid of
+// one venue with the best ask of another when they cross.
+type CrossVenue struct {
+	HighSource   string  `json:"high_source"`
+	HighPrice    float64 `json:"high_price"`
+	LowSource    string  `json:"low_source"`
+	LowPrice     float64 `json:"low_price"`
+	SpreadBps    float64 `json:"spread_bps"`
+	BuyOn        string  `json:"buy_on,omitempty"`
+	SellOn       string  `json:"sell_on,omitempty"`
+	ArbitrageBps float64 `json:"arbitrage_bps"`
+}
+
+// PriceComparison has no CrossVenue when fewer than two venues have a
+// price. Unavailable gives the error of each venue that failed.
+type PriceComparison struct {
+	Symbol       string            `json:"symbol"`
+	Venues       []VenueQuote      `json:"venues"`
+	Unavailable  map[string]string `json:"unavailable"`
+	CrossVenue   *CrossVenue       `json:"cross_venue"`
+	ThresholdBps float64           `json:"threshold_bps"`
+	Alert        bool              `json:"alert"`
+}
+
+type ComparePoint struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Prices    map[string]float64 `json:"prices"`
+	CrossVenue
+}
+
+// SpreadEpisode groups consecutive points above the threshold.
+type SpreadEpisode struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Points       int       `json:"points"`
+	MaxSpreadBps float64   `json:"max_spread_bps"`
+	HighSource   string    `json:"high_source"`
+	LowSource    string    `json:"low_source"`
+}
+
+// CompareHistory gives Sources, the native pair of every venue.
+type CompareHistory struct {
+	Symbol       string            `json:"symbol"`
+	Sources      map[string]string `json:"sources"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Points       []ComparePoint    `json:"points"`
+	Count        int               `json:"count"`
+	AvgSpreadBps float64           `json:"avg_spread_bps"`
+	MaxSpreadBps float64           `json:"max_spread_bps"`
+	ThresholdBps float64           `json:"threshold_bps"`
+	Alerts       []SpreadEpisode   `json:"alerts"`
+}
+
+type SpreadAlerts struct {
+	Symbol string               `json:"symbol"`
+	Alerts []models.SpreadAlert `json:"alerts"`
+	Count  int                  `json:"count"`
+}
+
+type IndexValue struct {
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	Used      int       `json:"used"`
+	Rejected  int       `json:"rejected"`
+}
+
+// PriceIndex gives the latest index, computed from the current tickers
+// when Live is true, and the stored series over the range. A live request
+// gets the latest stored point, with Stale set, when no venue answers.
+type PriceIndex struct {
+	Asset  string            `json:"asset"`
+	Quote  string            `json:"quote"`
+	Method string            `json:"method"`
+	Price  float64           `json:"price"`
+	Latest models.IndexPoint `json:"latest"`
+	Live   bool              `json:"live"`
+	Series []IndexValue      `json:"series"`
+	Stale  bool              `json:"stale,omitempty"`
+	AsOf   *time.Time        `json:"as_of,omitempty"`
+}
+
+// Raw data.
+
+type PairData struct {
+	PairInfo   models.TradingPair      `json:"pair_info"`
+	Info       []models.PairInfo       `json:"info"`
+	Historical []models.HistoricalData `json:"historical"`
+}
+
+type DBData struct {
+	Pairs map[string]PairData `json:"pairs"`
+	Count int                 `json:"count"`
+}
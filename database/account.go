@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// SaveAccountBalances stores one snapshot of the account balances.
+func (d *DB) SaveAccountBalances(timestamp time.Time, balances map[string]float64) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "account_balances"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO account_balances (timestamp, asset, amount) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for asset, amount := range balances {
+		if _, err := stmt.Exec(timestamp.UTC(), asset, amount); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.recordWrite("account_balances", int64(len(balances)))
+	return nil
+}
+
+// GetLatestAccountBalances returns the most recent balance snapshot.
+func (d *DB) GetLatestAccountBalances() ([]models.AccountBalance, error) {
+	rows, err := d.query(`SELECT id, timestamp, asset, amount FROM account_balances
+		WHERE timestamp = (SELECT MAX(timestamp) FROM account_balances)
+		ORDER BY asset`)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := scanAccountBalances(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		return nil, ErrNotFound
+	}
+	return balances, nil
+}
+
+// GetAccountBalancesFromDB returns the balance snapshots taken between from
+// and to, oldest first.
+func (d *DB) GetAccountBalancesFromDB(from, to time.Time) ([]models.AccountBalance, error) {
+	rows, err := d.query(`SELECT id, timestamp, asset, amount FROM account_balances
+		WHERE timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp, asset`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return scanAccountBalances(rows)
+}
+
+func scanAccountBalances(rows *sql.Rows) ([]models.AccountBalance, error) {
+	defer rows.Close()
+
+	var balances []models.AccountBalance
+	for rows.Next() {
+		var b models.AccountBalance
+		if err := rows.Scan(&b.ID, &b.Timestamp, &b.Asset, &b.Amount); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+// SaveLedgerEntries stores ledger entries, skipping the ones already known,
+// and returns the number of new entries.
+func (d *DB) SaveLedgerEntries(entries []models.LedgerEntry) (_ int, err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "account_ledger"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO account_ledger (id, refid, time, type, subtype, aclass, asset, amount, fee, balance)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, e := range entries {
+		result, err := stmt.Exec(e.ID, e.RefID, e.Time.UTC(), e.Type, e.Subtype, e.AClass, e.Asset, e.Amount, e.Fee, e.Balance)
+		if err != nil {
+			return 0, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	d.recordWrite("account_ledger", int64(inserted))
+	return inserted, nil
+}
+
+// GetLatestLedgerTime returns the time of the most recent stored ledger
+// entry, or the zero time when the ledger is empty.
+func (d *DB) GetLatestLedgerTime() (time.Time, error) {
+	var latest time.Time
+	err := d.queryRow(`SELECT time FROM account_ledger ORDER BY time DESC LIMIT 1`).Scan(&latest)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return latest, err
+}
+
+func (d *DB) GetLedgerEntriesFromDB(asset string, from, to time.Time, limit int) ([]models.LedgerEntry, error) {
+	query := `SELECT id, refid, time, type, subtype, aclass, asset, amount, fee, balance
+		FROM account_ledger
+		WHERE time >= ? AND time <= ?`
+	args := []any{from.UTC(), to.UTC()}
+	if asset != "" {
+		query += ` AND asset = ?`
+		args = append(args, asset)
+	}
+	query += ` ORDER BY time DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LedgerEntry
+	for rows.Next() {
+		var e models.LedgerEntry
+		var subtype, aclass sql.NullString
+		err := rows.Scan(&e.ID, &e.RefID, &e.Time, &e.Type, &subtype, &aclass, &e.Asset, &e.Amount, &e.Fee, &e.Balance)
+		if err != nil {
+			return nil, err
+		}
+		e.Subtype, e.AClass = subtype.String, aclass.String
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// GetLedgerTotalsFromDB returns, for every asset of the ledger, the net of
+// all entries (amount minus fee) and the balance reported by the latest one.
+func (d *DB) GetLedgerTotalsFromDB() ([]models.LedgerTotal, error) {
+	query := `SELECT t.asset, t.entries, t.net, l.balance, l.time FROM (
+			SELECT asset, COUNT(*) AS entries, SUM(amount - fee) AS net
+			FROM account_ledger GROUP BY asset
+		) t
+		JOIN (
+			SELECT asset, balance, time,
+				ROW_NUMBER() OVER (PARTITION BY asset ORDER BY time DESC, id DESC) AS rn
+			FROM account_ledger
+		) l ON l.asset = t.asset AND l.rn = 1
+		ORDER BY t.asset`
+
+	rows, err := d.query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []models.LedgerTotal
+	for rows.Next() {
+		var t models.LedgerTotal
+		if err := rows.Scan(&t.Asset, &t.Entries, &t.Net, &t.LastBalance, &t.LastTime); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, nil
+}
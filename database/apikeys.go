@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const apiKeyColumns = `id, name, prefix, hash, scopes, created_at, expires_at, revoked_at, last_used_at`
+
+func (d *DB) CreateAPIKey(key *models.APIKey) error {
+	key.CreatedAt = time.Now().UTC()
+	var expiresAt any
+	if key.ExpiresAt != nil {
+		expiresAt = key.ExpiresAt.UTC()
+	}
+	result, err := d.exec(`INSERT INTO api_keys (name, prefix, hash, scopes, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, " "), key.CreatedAt, expiresAt)
+	if err != nil {
+		return constraintError(err)
+	}
+	key.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetAPIKeyByHash returns the key whose hash is given, revoked or not.
+func (d *DB) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	rows, err := d.query(`SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = ?`, hash)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, ErrNotFound
+	}
+	return &keys[0], nil
+}
+
+func (d *DB) GetAPIKeys() ([]models.APIKey, error) {
+	rows, err := d.query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAPIKeys(rows)
+}
+
+// RevokeAPIKey disables a key; its usage history is kept.
+func (d *DB) RevokeAPIKey(id int64) error {
+	result, err := d.exec(`UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]models.APIKey, error) {
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var k models.APIKey
+		var scopes string
+		var expiresAt, revokedAt, lastUsedAt sql.NullTime
+		err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, &scopes, &k.CreatedAt, &expiresAt, &revokedAt, &lastUsedAt)
+		if err != nil {
+			return nil, err
+		}
+		k.Scopes = strings.Fields(scopes)
+		k.ExpiresAt = nullTime(expiresAt)
+		k.RevokedAt = nullTime(revokedAt)
+		k.LastUsedAt = nullTime(lastUsedAt)
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// RecordAPIKeyUsage counts one request made with the key on route at t.
+func (d *DB) RecordAPIKeyUsage(keyID int64, route string, t time.Time, failed bool) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "api_key_usage"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	failures := 0
+	if failed {
+		failures = 1
+	}
+	_, err = tx.Exec(`
+		INSERT INTO api_key_usage (key_id, day, route, requests, errors) VALUES (?, ?, ?, 1, ?)
+		ON CONFLICT(key_id, day, route) DO UPDATE SET
+			requests = requests + 1,
+			errors = errors + excluded.errors
+	`, keyID, t.UTC().Format(time.DateOnly), route, failures)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, t.UTC(), keyID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAPIKeyUsageFromDB returns the usage of every key, or of keyID when it
+// is not zero, from the UTC day of from onwards, most recent day first.
+func (d *DB) GetAPIKeyUsageFromDB(keyID int64, from time.Time) ([]models.APIKeyUsage, error) {
+	query := `SELECT key_id, day, route, requests, errors FROM api_key_usage WHERE day >= ?`
+	args := []any{from.UTC().Format(time.DateOnly)}
+	if keyID != 0 {
+		query += ` AND key_id = ?`
+		args = append(args, keyID)
+	}
+	query += ` ORDER BY day DESC, key_id, route`
+
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []models.APIKeyUsage
+	for rows.Next() {
+		var u models.APIKeyUsage
+		if err := rows.Scan(&u.KeyID, &u.Day, &u.Route, &u.Requests, &u.Errors); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
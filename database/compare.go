@@ -0,0 +1,77 @@
+package database
+
+import (
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// GetDistinctPairsFromDB returns every pair stored for every source once.
+func (d *DB) GetDistinctPairsFromDB() ([]models.TradingPair, error) {
+	query := `SELECT DISTINCT name, base, quote, source
+		FROM trading_pairs
+		ORDER BY source, name`
+	rows, err := d.query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []models.TradingPair
+	for rows.Next() {
+		var pair models.TradingPair
+		if err := rows.Scan(&pair.Name, &pair.Base, &pair.Quote, &pair.Source); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+func (d *DB) SaveSpreadAlert(alert *models.SpreadAlert) error {
+	result, err := d.exec(`INSERT INTO spread_alerts (symbol, timestamp, high_source, high_price, low_source, low_price, spread_bps)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		alert.Symbol, alert.Timestamp.UTC(), alert.HighSource, alert.HighPrice, alert.LowSource, alert.LowPrice, alert.SpreadBps)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	alert.ID = id
+	return nil
+}
+
+// GetSpreadAlertsFromDB returns the alerts of the window, most recent first,
+// optionally restricted to one symbol.
+func (d *DB) GetSpreadAlertsFromDB(symbol string, from, to time.Time) ([]models.SpreadAlert, error) {
+	query := `SELECT id, symbol, timestamp, high_source, high_price, low_source, low_price, spread_bps
+		FROM spread_alerts
+		WHERE timestamp >= ? AND timestamp <= ?`
+	args := []any{from.UTC(), to.UTC()}
+	if symbol != "" {
+		query += ` AND symbol = ?`
+		args = append(args, symbol)
+	}
+	query += ` ORDER BY timestamp DESC, id DESC`
+
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.SpreadAlert
+	for rows.Next() {
+		var a models.SpreadAlert
+		err := rows.Scan(&a.ID, &a.Symbol, &a.Timestamp, &a.HighSource, &a.HighPrice, &a.LowSource, &a.LowPrice, &a.SpreadBps)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
@@ -1,16 +1,30 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/antonyloussararian/Go-CryptoPrice/models"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// SourceKraken is the source of the data collected from Kraken, the default
+// of every source column.
+const SourceKraken = "kraken"
+
+var (
+	ErrNotFound = errors.New("enregistrement introuvable")
+	ErrConflict = errors.New("enregistrement déjà existant")
+)
+
 type DB struct {
-	db *sql.DB
+	db  *sql.DB
+	ctx context.Context
 }
 
 func NewDB(dbPath string) (*DB, error) {
@@ -23,7 +37,13 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, err
 	}
 
-	return &DB{db: db}, nil
+	return &DB{db: db, ctx: context.Background()}, nil
+}
+
+// WithContext returns a copy of the DB whose queries run with ctx, so their
+// logs include the request or cycle identifier.
+func (d *DB) WithContext(ctx context.Context) *DB {
+	return &DB{db: d.db, ctx: ctx}
 }
 
 func (d *DB) InitSchema() error {
@@ -40,7 +60,8 @@ func (d *DB) InitSchema() error {
 			base TEXT NOT NULL,
 			quote TEXT NOT NULL,
 			last_updated DATETIME NOT NULL,
-			UNIQUE(name, last_updated)
+			source TEXT NOT NULL DEFAULT 'kraken',
+			UNIQUE(name, source, last_updated)
 		)`,
 		`CREATE TABLE IF NOT EXISTS pair_info (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -49,6 +70,12 @@ func (d *DB) InitSchema() error {
 			volume_24h REAL NOT NULL,
 			high_24h REAL NOT NULL,
 			low_24h REAL NOT NULL,
+			bid REAL NOT NULL DEFAULT 0,
+			ask REAL NOT NULL DEFAULT 0,
+			last_trade_volume REAL NOT NULL DEFAULT 0,
+			vwap_24h REAL NOT NULL DEFAULT 0,
+			trade_count_24h INTEGER NOT NULL DEFAULT 0,
+			open REAL NOT NULL DEFAULT 0,
 			timestamp DATETIME NOT NULL,
 			FOREIGN KEY (pair_id) REFERENCES trading_pairs(id)
 		)`,
@@ -63,26 +90,327 @@ func (d *DB) InitSchema() error {
 			volume REAL NOT NULL,
 			FOREIGN KEY (pair_id) REFERENCES trading_pairs(id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS order_book_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pair_id INTEGER NOT NULL,
+			timestamp DATETIME NOT NULL,
+			best_bid REAL NOT NULL,
+			best_ask REAL NOT NULL,
+			spread REAL NOT NULL,
+			mid_price REAL NOT NULL,
+			bid_depth_1pct REAL NOT NULL,
+			ask_depth_1pct REAL NOT NULL,
+			bid_depth_2pct REAL NOT NULL,
+			ask_depth_2pct REAL NOT NULL,
+			imbalance REAL NOT NULL,
+			bids TEXT NOT NULL,
+			asks TEXT NOT NULL,
+			FOREIGN KEY (pair_id) REFERENCES trading_pairs(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_order_book_snapshots_pair_id ON order_book_snapshots(pair_id)`,
+		`CREATE TABLE IF NOT EXISTS trades (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pair TEXT NOT NULL,
+			trade_id INTEGER,
+			price REAL NOT NULL,
+			volume REAL NOT NULL,
+			side TEXT NOT NULL,
+			order_type TEXT NOT NULL,
+			misc TEXT,
+			timestamp DATETIME NOT NULL,
+			UNIQUE(pair, trade_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_pair_timestamp ON trades(pair, timestamp)`,
+		`CREATE TABLE IF NOT EXISTS portfolios (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			base_currency TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS holdings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			portfolio_id INTEGER NOT NULL,
+			asset TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			cost_basis REAL NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			UNIQUE(portfolio_id, asset),
+			FOREIGN KEY (portfolio_id) REFERENCES portfolios(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			portfolio_id INTEGER NOT NULL,
+			time DATETIME NOT NULL,
+			type TEXT NOT NULL,
+			asset TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			price REAL NOT NULL DEFAULT 0,
+			quote TEXT,
+			fee REAL NOT NULL DEFAULT 0,
+			fee_asset TEXT,
+			refid TEXT,
+			external_id TEXT,
+			source TEXT NOT NULL,
+			UNIQUE(portfolio_id, source, external_id),
+			FOREIGN KEY (portfolio_id) REFERENCES portfolios(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_time ON transactions(portfolio_id, time)`,
+		`CREATE TABLE IF NOT EXISTS paper_accounts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			quote_currency TEXT NOT NULL,
+			initial_value REAL NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS paper_balances (
+			account_id INTEGER NOT NULL,
+			asset TEXT NOT NULL,
+			amount REAL NOT NULL,
+			PRIMARY KEY (account_id, asset),
+			FOREIGN KEY (account_id) REFERENCES paper_accounts(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS paper_orders (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			account_id INTEGER NOT NULL,
+			pair TEXT NOT NULL,
+			base TEXT NOT NULL,
+			quote TEXT NOT NULL,
+			side TEXT NOT NULL,
+			type TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			limit_price REAL NOT NULL DEFAULT 0,
+			stop_price REAL NOT NULL DEFAULT 0,
+			status TEXT NOT NULL,
+			reason TEXT,
+			created_at DATETIME NOT NULL,
+			closed_at DATETIME,
+			FOREIGN KEY (account_id) REFERENCES paper_accounts(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_paper_orders_pair_status ON paper_orders(pair, status)`,
+		`CREATE TABLE IF NOT EXISTS paper_fills (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			order_id INTEGER NOT NULL,
+			account_id INTEGER NOT NULL,
+			pair TEXT NOT NULL,
+			side TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			price REAL NOT NULL,
+			fee REAL NOT NULL,
+			timestamp DATETIME NOT NULL,
+			FOREIGN KEY (order_id) REFERENCES paper_orders(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS account_balances (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp DATETIME NOT NULL,
+			asset TEXT NOT NULL,
+			amount REAL NOT NULL,
+			UNIQUE(timestamp, asset)
+		)`,
+		`CREATE TABLE IF NOT EXISTS account_ledger (
+			id TEXT PRIMARY KEY,
+			refid TEXT NOT NULL,
+			time DATETIME NOT NULL,
+			type TEXT NOT NULL,
+			subtype TEXT,
+			aclass TEXT,
+			asset TEXT NOT NULL,
+			amount REAL NOT NULL,
+			fee REAL NOT NULL DEFAULT 0,
+			balance REAL NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_account_ledger_asset_time ON account_ledger(asset, time)`,
+		`CREATE TABLE IF NOT EXISTS spread_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			symbol TEXT NOT NULL,
+			timestamp DATETIME NOT NULL,
+			high_source TEXT NOT NULL,
+			high_price REAL NOT NULL,
+			low_source TEXT NOT NULL,
+			low_price REAL NOT NULL,
+			spread_bps REAL NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_spread_alerts_symbol_time ON spread_alerts(symbol, timestamp)`,
+		`CREATE TABLE IF NOT EXISTS price_index (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			asset TEXT NOT NULL,
+			quote TEXT NOT NULL,
+			timestamp DATETIME NOT NULL,
+			vwap REAL NOT NULL,
+			median REAL NOT NULL,
+			used INTEGER NOT NULL,
+			rejected INTEGER NOT NULL,
+			components TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_price_index_asset_time ON price_index(asset, quote, timestamp)`,
+		`CREATE TABLE IF NOT EXISTS trade_cursors (
+			pair TEXT PRIMARY KEY,
+			last TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			prefix TEXT NOT NULL,
+			hash TEXT NOT NULL UNIQUE,
+			scopes TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME,
+			revoked_at DATETIME,
+			last_used_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS client_quotas (
+			client TEXT NOT NULL,
+			day TEXT NOT NULL,
+			requests INTEGER NOT NULL,
+			PRIMARY KEY (client, day)
+		)`,
+		`CREATE TABLE IF NOT EXISTS api_key_usage (
+			key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+			day TEXT NOT NULL,
+			route TEXT NOT NULL,
+			requests INTEGER NOT NULL DEFAULT 0,
+			errors INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (key_id, day, route)
+		)`,
 	}
 
 	for _, query := range queries {
-		_, err := d.db.Exec(query)
+		_, err := d.exec(query)
 		if err != nil {
 			return fmt.Errorf("erreur lors de l'exécution de la requête %s: %v", query, err)
 		}
 	}
 
-	log.Println("Schéma de la base de données initialisé avec succès")
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"pair_info", "bid", "REAL NOT NULL DEFAULT 0"},
+		{"pair_info", "ask", "REAL NOT NULL DEFAULT 0"},
+		{"pair_info", "last_trade_volume", "REAL NOT NULL DEFAULT 0"},
+		{"pair_info", "vwap_24h", "REAL NOT NULL DEFAULT 0"},
+		{"pair_info", "trade_count_24h", "INTEGER NOT NULL DEFAULT 0"},
+		{"pair_info", "open", "REAL NOT NULL DEFAULT 0"},
+		{"trading_pairs", "source", "TEXT NOT NULL DEFAULT 'kraken'"},
+		{"trades", "source", "TEXT NOT NULL DEFAULT 'kraken'"},
+		{"server_status", "latency_ms", "REAL NOT NULL DEFAULT 0"},
+		{"server_status", "system_time", "DATETIME"},
+		{"server_status", "previous_status", "TEXT NOT NULL DEFAULT ''"},
+		{"server_status", "clock_skew_ms", "REAL"},
+	}
+
+	for _, c := range columns {
+		if err := d.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("erreur lors de l'ajout de la colonne %s.%s: %v", c.table, c.column, err)
+		}
+	}
+
+	// Tickers, candles, status checks and spread alerts used to be stored in
+	// local time, which does not compare with the UTC bounds of range queries.
+	for _, table := range []string{"pair_info", "historical_data", "server_status", "spread_alerts"} {
+		_, err := d.exec(fmt.Sprintf(`UPDATE %s SET timestamp = strftime('%%Y-%%m-%%d %%H:%%M:%%f', timestamp) || '+00:00'
+			WHERE timestamp NOT LIKE '%%+00:00'`, table))
+		if err != nil {
+			return fmt.Errorf("erreur lors de la conversion en UTC de %s: %v", table, err)
+		}
+	}
+
+	if err := d.migrateTradingPairsKey(); err != nil {
+		return fmt.Errorf("erreur lors de la migration de la table trading_pairs: %v", err)
+	}
+	if err := d.migrateTradeIDs(); err != nil {
+		return fmt.Errorf("erreur lors de la migration de la table trades: %v", err)
+	}
+	// Trades without a Kraken id are told apart by their time, price and
+	// volume.
+	_, err := d.exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_untracked
+		ON trades(pair, timestamp, price, volume) WHERE trade_id IS NULL`)
+	if err != nil {
+		return fmt.Errorf("erreur lors de la création de l'index idx_trades_untracked: %v", err)
+	}
+
+	slog.Info("schéma de la base de données initialisé")
+	return nil
+}
+
+// migrateTradingPairsKey adds the source to the unique key of trading_pairs
+// in databases created without it: a venue market named like a Kraken pair
+// could not be stored in the same collection cycle.
+func (d *DB) migrateTradingPairsKey() error {
+	var schema string
+	err := d.queryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'trading_pairs'`).Scan(&schema)
+	if err != nil || !strings.Contains(schema, "UNIQUE(name, last_updated)") {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`CREATE TABLE trading_pairs_migrated (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			base TEXT NOT NULL,
+			quote TEXT NOT NULL,
+			last_updated DATETIME NOT NULL,
+			source TEXT NOT NULL DEFAULT 'kraken',
+			UNIQUE(name, source, last_updated)
+		)`,
+		`INSERT INTO trading_pairs_migrated (id, name, base, quote, last_updated, source)
+			SELECT id, name, base, quote, last_updated, source FROM trading_pairs`,
+		`DROP TABLE trading_pairs`,
+		`ALTER TABLE trading_pairs_migrated RENAME TO trading_pairs`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("source ajoutée à la clé unique de trading_pairs")
 	return nil
 }
 
+// addColumnIfMissing upgrades databases created before a column was added
+// to the CREATE TABLE statements above.
+func (d *DB) addColumnIfMissing(table, column, definition string) error {
+	rows, err := d.query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	_, err = d.exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 func (d *DB) Close() error {
 	return d.db.Close()
 }
 
 func (d *DB) GetTradingPairsFromDB() ([]models.TradingPair, error) {
-	query := `SELECT id, name, base, quote, last_updated FROM trading_pairs ORDER BY last_updated DESC`
-	rows, err := d.db.Query(query)
+	query := `SELECT id, name, base, quote, source, last_updated FROM trading_pairs ORDER BY last_updated DESC`
+	rows, err := d.query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +419,7 @@ func (d *DB) GetTradingPairsFromDB() ([]models.TradingPair, error) {
 	var pairs []models.TradingPair
 	for rows.Next() {
 		var pair models.TradingPair
-		err := rows.Scan(&pair.ID, &pair.Name, &pair.Base, &pair.Quote, &pair.LastUpdated)
+		err := rows.Scan(&pair.ID, &pair.Name, &pair.Base, &pair.Quote, &pair.Source, &pair.LastUpdated)
 		if err != nil {
 			return nil, err
 		}
@@ -101,8 +429,8 @@ func (d *DB) GetTradingPairsFromDB() ([]models.TradingPair, error) {
 }
 
 func (d *DB) GetPairInfoFromDB(pairID int64) ([]models.PairInfo, error) {
-	query := `SELECT id, pair_id, price, volume_24h, high_24h, low_24h, timestamp FROM pair_info WHERE pair_id = ? ORDER BY timestamp DESC`
-	rows, err := d.db.Query(query, pairID)
+	query := `SELECT id, pair_id, price, volume_24h, high_24h, low_24h, bid, ask, last_trade_volume, vwap_24h, trade_count_24h, open, timestamp FROM pair_info WHERE pair_id = ? ORDER BY timestamp DESC`
+	rows, err := d.query(query, pairID)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +439,8 @@ func (d *DB) GetPairInfoFromDB(pairID int64) ([]models.PairInfo, error) {
 	var infos []models.PairInfo
 	for rows.Next() {
 		var info models.PairInfo
-		err := rows.Scan(&info.ID, &info.PairID, &info.Price, &info.Volume24h, &info.High24h, &info.Low24h, &info.Timestamp)
+		err := rows.Scan(&info.ID, &info.PairID, &info.Price, &info.Volume24h, &info.High24h, &info.Low24h,
+			&info.Bid, &info.Ask, &info.LastTradeVolume, &info.VWAP24h, &info.TradeCount24h, &info.Open, &info.Timestamp)
 		if err != nil {
 			return nil, err
 		}
@@ -122,7 +451,7 @@ func (d *DB) GetPairInfoFromDB(pairID int64) ([]models.PairInfo, error) {
 
 func (d *DB) GetHistoricalDataFromDB(pairID int64) ([]models.HistoricalData, error) {
 	query := `SELECT id, pair_id, timestamp, open, high, low, close, volume FROM historical_data WHERE pair_id = ? ORDER BY timestamp DESC`
-	rows, err := d.db.Query(query, pairID)
+	rows, err := d.query(query, pairID)
 	if err != nil {
 		return nil, err
 	}
@@ -141,14 +470,32 @@ func (d *DB) GetHistoricalDataFromDB(pairID int64) ([]models.HistoricalData, err
 }
 
 func (d *DB) SaveServerStatus(status *models.ServerStatus) error {
-	query := `INSERT INTO server_status (timestamp, status, error) VALUES (?, ?, ?)`
-	_, err := d.db.Exec(query, status.Timestamp, status.Status, status.Error)
+	var systemTime any
+	if !status.SystemTime.IsZero() {
+		systemTime = status.SystemTime.UTC()
+	}
+	query := `INSERT INTO server_status (timestamp, status, error, latency_ms, system_time, previous_status, clock_skew_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := d.exec(query, status.Timestamp.UTC(), status.Status, status.Error, status.LatencyMs, systemTime,
+		status.PreviousStatus, status.ClockSkewMs)
+	if err != nil {
+		return err
+	}
+	status.ID, err = result.LastInsertId()
 	return err
 }
 
+// Ping checks that the database answers.
+func (d *DB) Ping() error {
+	return d.db.PingContext(d.ctx)
+}
+
 func (d *DB) SaveTradingPair(pair *models.TradingPair) error {
-	query := `INSERT INTO trading_pairs (name, base, quote, last_updated) VALUES (?, ?, ?, ?)`
-	result, err := d.db.Exec(query, pair.Name, pair.Base, pair.Quote, pair.LastUpdated)
+	if pair.Source == "" {
+		pair.Source = SourceKraken
+	}
+	query := `INSERT INTO trading_pairs (name, base, quote, source, last_updated) VALUES (?, ?, ?, ?, ?)`
+	result, err := d.exec(query, pair.Name, pair.Base, pair.Quote, pair.Source, pair.LastUpdated)
 	if err != nil {
 		return err
 	}
@@ -163,27 +510,31 @@ func (d *DB) SaveTradingPair(pair *models.TradingPair) error {
 }
 
 func (d *DB) SavePairInfo(info *models.PairInfo) error {
-	query := `INSERT INTO pair_info (pair_id, price, volume_24h, high_24h, low_24h, timestamp) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := d.db.Exec(query, info.PairID, info.Price, info.Volume24h, info.High24h, info.Low24h, info.Timestamp)
+	query := `INSERT INTO pair_info (pair_id, price, volume_24h, high_24h, low_24h, bid, ask, last_trade_volume, vwap_24h, trade_count_24h, open, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.exec(query, info.PairID, info.Price, info.Volume24h, info.High24h, info.Low24h,
+		info.Bid, info.Ask, info.LastTradeVolume, info.VWAP24h, info.TradeCount24h, info.Open, info.Timestamp.UTC())
 	return err
 }
 
 func (d *DB) SaveHistoricalData(data *models.HistoricalData) error {
 	query := `INSERT INTO historical_data (pair_id, timestamp, open, high, low, close, volume) VALUES (?, ?, ?, ?, ?, ?, ?)`
-	_, err := d.db.Exec(query, data.PairID, data.Timestamp, data.Open, data.High, data.Low, data.Close, data.Volume)
+	_, err := d.exec(query, data.PairID, data.Timestamp.UTC(), data.Open, data.High, data.Low, data.Close, data.Volume)
 	return err
 }
 
-func (d *DB) SaveTradingPairBatch(pairs []models.TradingPair) error {
-	tx, err := d.db.Begin()
+func (d *DB) SaveTradingPairBatch(pairs []models.TradingPair) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "trading_pairs"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO trading_pairs (name, base, quote, last_updated)
-		VALUES (?, ?, ?, ?)
+		INSERT OR REPLACE INTO trading_pairs (name, base, quote, source, last_updated)
+		VALUES (?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -191,25 +542,36 @@ func (d *DB) SaveTradingPairBatch(pairs []models.TradingPair) error {
 	defer stmt.Close()
 
 	for _, pair := range pairs {
-		_, err := stmt.Exec(pair.Name, pair.Base, pair.Quote, pair.LastUpdated)
+		source := pair.Source
+		if source == "" {
+			source = SourceKraken
+		}
+		_, err := stmt.Exec(pair.Name, pair.Base, pair.Quote, source, pair.LastUpdated)
 		if err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.recordWrite("trading_pairs", int64(len(pairs)))
+	return nil
 }
 
-func (d *DB) SavePairInfoBatch(infos []models.PairInfo) error {
-	tx, err := d.db.Begin()
+func (d *DB) SavePairInfoBatch(infos []models.PairInfo) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "pair_info"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO pair_info (pair_id, price, volume_24h, high_24h, low_24h, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO pair_info (pair_id, price, volume_24h, high_24h, low_24h, bid, ask, last_trade_volume, vwap_24h, trade_count_24h, open, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -217,17 +579,25 @@ func (d *DB) SavePairInfoBatch(infos []models.PairInfo) error {
 	defer stmt.Close()
 
 	for _, info := range infos {
-		_, err := stmt.Exec(info.PairID, info.Price, info.Volume24h, info.High24h, info.Low24h, info.Timestamp)
+		_, err := stmt.Exec(info.PairID, info.Price, info.Volume24h, info.High24h, info.Low24h,
+			info.Bid, info.Ask, info.LastTradeVolume, info.VWAP24h, info.TradeCount24h, info.Open, info.Timestamp.UTC())
 		if err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.recordWrite("pair_info", int64(len(infos)))
+	return nil
 }
 
-func (d *DB) SaveHistoricalDataBatch(data []models.HistoricalData) error {
-	tx, err := d.db.Begin()
+func (d *DB) SaveHistoricalDataBatch(data []models.HistoricalData) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "historical_data"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -243,11 +613,115 @@ func (d *DB) SaveHistoricalDataBatch(data []models.HistoricalData) error {
 	defer stmt.Close()
 
 	for _, d := range data {
-		_, err := stmt.Exec(d.PairID, d.Timestamp, d.Open, d.High, d.Low, d.Close, d.Volume)
+		_, err := stmt.Exec(d.PairID, d.Timestamp.UTC(), d.Open, d.High, d.Low, d.Close, d.Volume)
 		if err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.recordWrite("historical_data", int64(len(data)))
+	return nil
+}
+
+func (d *DB) GetPairInfoByNameFromDB(pairName string, from, to time.Time) ([]models.PairInfo, error) {
+	return d.GetSourcePairInfoFromDB(SourceKraken, pairName, from, to)
+}
+
+// GetSourcePairInfoFromDB returns the ticker snapshots of a pair collected
+// from the given source, oldest first.
+func (d *DB) GetSourcePairInfoFromDB(source, pairName string, from, to time.Time) ([]models.PairInfo, error) {
+	query := `SELECT i.id, i.pair_id, i.price, i.volume_24h, i.high_24h, i.low_24h, i.bid, i.ask,
+		i.last_trade_volume, i.vwap_24h, i.trade_count_24h, i.open, i.timestamp
+		FROM pair_info i
+		JOIN trading_pairs t ON t.id = i.pair_id
+		WHERE t.source = ? AND t.name = ? AND i.timestamp >= ? AND i.timestamp <= ?
+		ORDER BY i.timestamp ASC`
+	rows, err := d.query(query, source, pairName, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []models.PairInfo
+	for rows.Next() {
+		var info models.PairInfo
+		err := rows.Scan(&info.ID, &info.PairID, &info.Price, &info.Volume24h, &info.High24h, &info.Low24h,
+			&info.Bid, &info.Ask, &info.LastTradeVolume, &info.VWAP24h, &info.TradeCount24h, &info.Open, &info.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (d *DB) GetCandlesFromDB(pairName string, from, to time.Time) ([]models.HistoricalData, error) {
+	query := `SELECT h.id, h.pair_id, h.timestamp, h.open, h.high, h.low, h.close, h.volume
+		FROM historical_data h
+		JOIN trading_pairs t ON t.id = h.pair_id
+		WHERE t.name = ? AND t.source = 'kraken' AND h.timestamp >= ? AND h.timestamp <= ?
+		ORDER BY h.timestamp ASC`
+	rows, err := d.query(query, pairName, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []models.HistoricalData
+	for rows.Next() {
+		var h models.HistoricalData
+		err := rows.Scan(&h.ID, &h.PairID, &h.Timestamp, &h.Open, &h.High, &h.Low, &h.Close, &h.Volume)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, h)
+	}
+	return data, nil
+}
+
+// GetLatestPairPricesFromDB returns the most recent ticker price of every
+// stored pair of every source.
+func (d *DB) GetLatestPairPricesFromDB() ([]models.PairPrice, error) {
+	query := `SELECT source, name, base, quote, price, timestamp FROM (
+			SELECT t.source, t.name, t.base, t.quote, i.price, i.timestamp,
+				ROW_NUMBER() OVER (PARTITION BY t.source, t.name ORDER BY i.timestamp DESC, i.id DESC) AS rn
+			FROM pair_info i
+			JOIN trading_pairs t ON t.id = i.pair_id
+		) WHERE rn = 1`
+	rows, err := d.query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []models.PairPrice
+	for rows.Next() {
+		var p models.PairPrice
+		if err := rows.Scan(&p.Source, &p.Name, &p.Base, &p.Quote, &p.Price, &p.Timestamp); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
+
+// GetCloseAtOrBeforeFromDB returns the close of the last stored candle of the
+// pair at or before t.
+func (d *DB) GetCloseAtOrBeforeFromDB(pairName string, t time.Time) (float64, time.Time, error) {
+	query := `SELECT h.close, h.timestamp
+		FROM historical_data h
+		JOIN trading_pairs p ON p.id = h.pair_id
+		WHERE p.name = ? AND p.source = 'kraken' AND h.timestamp <= ?
+		ORDER BY h.timestamp DESC, h.id DESC
+		LIMIT 1`
+	var close float64
+	var ts time.Time
+	err := d.queryRow(query, pairName, t.UTC()).Scan(&close, &ts)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, ErrNotFound
+	}
+	return close, ts, err
 }
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := NewDB(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// A venue market may be named like a Kraken pair and is stored in the same
+// collection cycle, with the same last_updated.
+func TestTradingPairsKeyIncludesSource(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy=%v", legacy), func(t *testing.T) {
+			db := newTestDB(t)
+			cycle := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+			if legacy {
+				_, err := db.exec(`CREATE TABLE trading_pairs (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					name TEXT NOT NULL,
+					base TEXT NOT NULL,
+					quote TEXT NOT NULL,
+					last_updated DATETIME NOT NULL,
+					UNIQUE(name, last_updated)
+				)`)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = db.exec(`INSERT INTO trading_pairs (name, base, quote, last_updated) VALUES ('ETHUSDT', 'XETH', 'USDT', ?)`, cycle)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := db.InitSchema(); err != nil {
+				t.Fatal(err)
+			}
+			if !legacy {
+				if err := db.SaveTradingPair(&models.TradingPair{Name: "ETHUSDT", Base: "XETH", Quote: "USDT", LastUpdated: cycle}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			venue := &models.TradingPair{Name: "ETHUSDT", Base: "ETH", Quote: "USDT", Source: "binance", LastUpdated: cycle}
+			if err := db.SaveTradingPair(venue); err != nil {
+				t.Fatalf("venue pair: %v", err)
+			}
+			duplicate := &models.TradingPair{Name: "ETHUSDT", Base: "ETH", Quote: "USDT", Source: "binance", LastUpdated: cycle}
+			if err := db.SaveTradingPair(duplicate); err == nil {
+				t.Error("same pair of the same source stored twice in a cycle")
+			}
+
+			pairs, err := db.GetTradingPairsFromDB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sources := map[string]bool{}
+			for _, p := range pairs {
+				sources[p.Source] = true
+			}
+			if len(pairs) != 2 || !sources[SourceKraken] || !sources["binance"] {
+				t.Errorf("pairs %+v", pairs)
+			}
+		})
+	}
+}
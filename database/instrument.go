@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tablePattern = regexp.MustCompile(`(?i)\b(?:from|into|update|table(?:\s+if\s+not\s+exists)?|table_info\()\s*(\w+)`)
+
+type sqlTarget struct {
+	operation string
+	table     string
+}
+
+// targets caches the operation and table parsed from each query text.
+var targets sync.Map
+
+// targetOf returns the metric labels and span name of a query: its first keyword and the
+// first table it reads from or writes to.
+func targetOf(query string) sqlTarget {
+	if t, ok := targets.Load(query); ok {
+		return t.(sqlTarget)
+	}
+	t := sqlTarget{operation: "other", table: "other"}
+	if fields := strings.Fields(query); len(fields) > 0 {
+		t.operation = strings.ToLower(fields[0])
+	}
+	if m := tablePattern.FindStringSubmatch(query); m != nil {
+		t.table = strings.ToLower(m[1])
+	}
+	targets.Store(query, t)
+	return t
+}
+
+var tracer = tracing.Tracer("database")
+
+// startQuery starts the span of a query, child of the context of the DB,
+// and returns its context with the function that ends the span and records
+// the query latency.
+func (d *DB) startQuery(t sqlTarget, statement string) (context.Context, func(error)) {
+	start := time.Now()
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemSqlite,
+		semconv.DBOperationName(t.operation),
+		semconv.DBCollectionName(t.table),
+	}
+	if statement != "" {
+		attrs = append(attrs, semconv.DBQueryText(statement))
+	}
+	ctx, span := tracer.Start(d.ctx, t.operation+" "+t.table,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	return ctx, func(err error) {
+		metrics.DBQueryDuration.WithLabelValues(t.operation, t.table).Observe(time.Since(start).Seconds())
+		tracing.RecordError(span, err)
+		span.End()
+	}
+}
+
+// recordWrite counts the rows written to a table and logs them with the
+// context of the DB, e.g. the identifier of the collection cycle.
+func (d *DB) recordWrite(table string, n int64) {
+	if n <= 0 {
+		return
+	}
+	metrics.DBRowsWritten.WithLabelValues(table).Add(float64(n))
+	slog.DebugContext(d.ctx, "écriture en base", "table", table, "rows", n)
+}
+
+func (d *DB) query(query string, args ...any) (*sql.Rows, error) {
+	ctx, end := d.startQuery(targetOf(query), query)
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	end(err)
+	return rows, err
+}
+
+// queryRow ends its span before the row is scanned, so the span of a query
+// returning no row is not marked as failed.
+func (d *DB) queryRow(query string, args ...any) *sql.Row {
+	ctx, end := d.startQuery(targetOf(query), query)
+	row := d.db.QueryRowContext(ctx, query, args...)
+	end(nil)
+	return row
+}
+
+// exec also counts the rows written by INSERT, UPDATE and REPLACE
+// statements.
+func (d *DB) exec(query string, args ...any) (sql.Result, error) {
+	t := targetOf(query)
+	ctx, end := d.startQuery(t, query)
+	result, err := d.db.ExecContext(ctx, query, args...)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	switch t.operation {
+	case "insert", "update", "replace":
+		if n, err := result.RowsAffected(); err == nil {
+			d.recordWrite(t.table, n)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func (d *DB) SaveOrderBookSnapshot(snapshot *models.OrderBookSnapshot) error {
+	bids, err := json.Marshal(snapshot.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := json.Marshal(snapshot.Asks)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO order_book_snapshots (pair_id, timestamp, best_bid, best_ask, spread, mid_price,
+		bid_depth_1pct, ask_depth_1pct, bid_depth_2pct, ask_depth_2pct, imbalance, bids, asks)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := d.exec(query, snapshot.PairID, snapshot.Timestamp, snapshot.BestBid, snapshot.BestAsk,
+		snapshot.Spread, snapshot.MidPrice, snapshot.BidDepth1Pct, snapshot.AskDepth1Pct,
+		snapshot.BidDepth2Pct, snapshot.AskDepth2Pct, snapshot.Imbalance, string(bids), string(asks))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = id
+	return nil
+}
+
+func (d *DB) GetOrderBookSnapshotsFromDB(pairName string, limit int) ([]models.OrderBookSnapshot, error) {
+	query := `SELECT o.id, o.pair_id, o.timestamp, o.best_bid, o.best_ask, o.spread, o.mid_price,
+		o.bid_depth_1pct, o.ask_depth_1pct, o.bid_depth_2pct, o.ask_depth_2pct, o.imbalance, o.bids, o.asks
+		FROM order_book_snapshots o
+		JOIN trading_pairs t ON t.id = o.pair_id
+		WHERE t.name = ? AND t.source = 'kraken'
+		ORDER BY o.timestamp DESC
+		LIMIT ?`
+	rows, err := d.query(query, pairName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.OrderBookSnapshot
+	for rows.Next() {
+		var s models.OrderBookSnapshot
+		var bids, asks string
+		err := rows.Scan(&s.ID, &s.PairID, &s.Timestamp, &s.BestBid, &s.BestAsk, &s.Spread, &s.MidPrice,
+			&s.BidDepth1Pct, &s.AskDepth1Pct, &s.BidDepth2Pct, &s.AskDepth2Pct, &s.Imbalance, &bids, &asks)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(bids), &s.Bids); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(asks), &s.Asks); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
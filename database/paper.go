@@ -0,0 +1,242 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const paperOrderColumns = `id, account_id, pair, base, quote, side, type, quantity, limit_price, stop_price,
+	status, reason, created_at, closed_at`
+
+func (d *DB) CreatePaperAccount(account *models.PaperAccount) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	account.CreatedAt = time.Now()
+	result, err := tx.Exec(`INSERT INTO paper_accounts (name, quote_currency, initial_value, created_at) VALUES (?, ?, ?, ?)`,
+		account.Name, account.QuoteCurrency, account.InitialValue, account.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO paper_balances (account_id, asset, amount) VALUES (?, ?, ?)`,
+		id, account.QuoteCurrency, account.InitialValue)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	account.ID = id
+	return nil
+}
+
+func (d *DB) GetPaperAccounts() ([]models.PaperAccount, error) {
+	rows, err := d.query(`SELECT id, name, quote_currency, initial_value, created_at FROM paper_accounts ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []models.PaperAccount
+	for rows.Next() {
+		var a models.PaperAccount
+		if err := rows.Scan(&a.ID, &a.Name, &a.QuoteCurrency, &a.InitialValue, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+func (d *DB) GetPaperAccount(id int64) (*models.PaperAccount, error) {
+	var a models.PaperAccount
+	err := d.queryRow(`SELECT id, name, quote_currency, initial_value, created_at FROM paper_accounts WHERE id = ?`, id).
+		Scan(&a.ID, &a.Name, &a.QuoteCurrency, &a.InitialValue, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (d *DB) GetPaperBalances(accountID int64) (map[string]float64, error) {
+	rows, err := d.query(`SELECT asset, amount FROM paper_balances WHERE account_id = ?`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[string]float64)
+	for rows.Next() {
+		var asset string
+		var amount float64
+		if err := rows.Scan(&asset, &amount); err != nil {
+			return nil, err
+		}
+		balances[asset] = amount
+	}
+	return balances, nil
+}
+
+func (d *DB) CreatePaperOrder(order *models.PaperOrder) error {
+	order.CreatedAt = time.Now()
+	result, err := d.exec(`INSERT INTO paper_orders (account_id, pair, base, quote, side, type, quantity, limit_price, stop_price, status, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		order.AccountID, order.Pair, order.Base, order.Quote, order.Side, order.Type, order.Quantity,
+		order.LimitPrice, order.StopPrice, order.Status, order.Reason, order.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	order.ID = id
+	return nil
+}
+
+func scanPaperOrders(rows *sql.Rows) ([]models.PaperOrder, error) {
+	defer rows.Close()
+
+	var orders []models.PaperOrder
+	for rows.Next() {
+		var o models.PaperOrder
+		var reason sql.NullString
+		var closedAt sql.NullTime
+		err := rows.Scan(&o.ID, &o.AccountID, &o.Pair, &o.Base, &o.Quote, &o.Side, &o.Type, &o.Quantity,
+			&o.LimitPrice, &o.StopPrice, &o.Status, &reason, &o.CreatedAt, &closedAt)
+		if err != nil {
+			return nil, err
+		}
+		o.Reason = reason.String
+		if closedAt.Valid {
+			o.ClosedAt = &closedAt.Time
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// GetPaperOrders lists the orders of an account, optionally filtered by
+// status.
+func (d *DB) GetPaperOrders(accountID int64, status string) ([]models.PaperOrder, error) {
+	query := `SELECT ` + paperOrderColumns + ` FROM paper_orders WHERE account_id = ?`
+	args := []any{accountID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	rows, err := d.query(query+` ORDER BY id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanPaperOrders(rows)
+}
+
+func (d *DB) GetOpenPaperOrdersForPair(pair string) ([]models.PaperOrder, error) {
+	rows, err := d.query(`SELECT `+paperOrderColumns+` FROM paper_orders WHERE pair = ? AND status = 'open' ORDER BY id`, pair)
+	if err != nil {
+		return nil, err
+	}
+	return scanPaperOrders(rows)
+}
+
+func (d *DB) GetPaperOrder(id int64) (*models.PaperOrder, error) {
+	rows, err := d.query(`SELECT `+paperOrderColumns+` FROM paper_orders WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := scanPaperOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrNotFound
+	}
+	return &orders[0], nil
+}
+
+// ClosePaperOrder moves an open order to a final status without a fill.
+func (d *DB) ClosePaperOrder(id int64, status, reason string) error {
+	result, err := d.exec(`UPDATE paper_orders SET status = ?, reason = ?, closed_at = ? WHERE id = ? AND status = 'open'`,
+		status, reason, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// FillPaperOrder records the fill, applies the balance changes and closes
+// the order in a single transaction.
+func (d *DB) FillPaperOrder(fill *models.PaperFill, deltas map[string]float64) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE paper_orders SET status = 'filled', closed_at = ? WHERE id = ? AND status = 'open'`,
+		fill.Timestamp, fill.OrderID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	for asset, delta := range deltas {
+		_, err := tx.Exec(`INSERT INTO paper_balances (account_id, asset, amount) VALUES (?, ?, ?)
+			ON CONFLICT(account_id, asset) DO UPDATE SET amount = amount + excluded.amount`,
+			fill.AccountID, asset, delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err = tx.Exec(`INSERT INTO paper_fills (order_id, account_id, pair, side, quantity, price, fee, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		fill.OrderID, fill.AccountID, fill.Pair, fill.Side, fill.Quantity, fill.Price, fill.Fee, fill.Timestamp)
+	if err != nil {
+		return err
+	}
+	if fill.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *DB) GetPaperFills(accountID int64) ([]models.PaperFill, error) {
+	rows, err := d.query(`SELECT id, order_id, account_id, pair, side, quantity, price, fee, timestamp
+		FROM paper_fills WHERE account_id = ? ORDER BY timestamp DESC, id DESC`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []models.PaperFill
+	for rows.Next() {
+		var f models.PaperFill
+		err := rows.Scan(&f.ID, &f.OrderID, &f.AccountID, &f.Pair, &f.Side, &f.Quantity, &f.Price, &f.Fee, &f.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, f)
+	}
+	return fills, nil
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/mattn/go-sqlite3"
+)
+
+func (d *DB) CreatePortfolio(p *models.Portfolio) error {
+	p.CreatedAt = time.Now()
+	result, err := d.exec(`INSERT INTO portfolios (name, base_currency, created_at) VALUES (?, ?, ?)`,
+		p.Name, p.BaseCurrency, p.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+	return nil
+}
+
+func (d *DB) GetPortfolios() ([]models.Portfolio, error) {
+	rows, err := d.query(`SELECT id, name, base_currency, created_at FROM portfolios ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var portfolios []models.Portfolio
+	for rows.Next() {
+		var p models.Portfolio
+		if err := rows.Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, p)
+	}
+	return portfolios, nil
+}
+
+func (d *DB) GetPortfolio(id int64) (*models.Portfolio, error) {
+	var p models.Portfolio
+	err := d.queryRow(`SELECT id, name, base_currency, created_at FROM portfolios WHERE id = ?`, id).
+		Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (d *DB) UpdatePortfolio(p *models.Portfolio) error {
+	result, err := d.exec(`UPDATE portfolios SET name = ?, base_currency = ? WHERE id = ?`,
+		p.Name, p.BaseCurrency, p.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (d *DB) DeletePortfolio(id int64) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM holdings WHERE portfolio_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM transactions WHERE portfolio_id = ?`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM portfolios WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *DB) GetHoldings(portfolioID int64) ([]models.Holding, error) {
+	rows, err := d.query(`SELECT id, portfolio_id, asset, quantity, cost_basis, created_at, updated_at
+		FROM holdings WHERE portfolio_id = ? ORDER BY asset`, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holdings []models.Holding
+	for rows.Next() {
+		var h models.Holding
+		err := rows.Scan(&h.ID, &h.PortfolioID, &h.Asset, &h.Quantity, &h.CostBasis, &h.CreatedAt, &h.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, nil
+}
+
+func (d *DB) GetHolding(portfolioID, id int64) (*models.Holding, error) {
+	var h models.Holding
+	err := d.queryRow(`SELECT id, portfolio_id, asset, quantity, cost_basis, created_at, updated_at
+		FROM holdings WHERE id = ? AND portfolio_id = ?`, id, portfolioID).
+		Scan(&h.ID, &h.PortfolioID, &h.Asset, &h.Quantity, &h.CostBasis, &h.CreatedAt, &h.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (d *DB) CreateHolding(h *models.Holding) error {
+	h.CreatedAt = time.Now()
+	h.UpdatedAt = h.CreatedAt
+	result, err := d.exec(`INSERT INTO holdings (portfolio_id, asset, quantity, cost_basis, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, h.PortfolioID, h.Asset, h.Quantity, h.CostBasis, h.CreatedAt, h.UpdatedAt)
+	if err != nil {
+		return constraintError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	h.ID = id
+	return nil
+}
+
+func (d *DB) UpdateHolding(h *models.Holding) error {
+	h.UpdatedAt = time.Now()
+	result, err := d.exec(`UPDATE holdings SET quantity = ?, cost_basis = ?, updated_at = ?
+		WHERE id = ? AND portfolio_id = ?`, h.Quantity, h.CostBasis, h.UpdatedAt, h.ID, h.PortfolioID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (d *DB) DeleteHolding(portfolioID, id int64) error {
+	result, err := d.exec(`DELETE FROM holdings WHERE id = ? AND portfolio_id = ?`, id, portfolioID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func checkAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SaveTransactions inserts the transactions in one batch. Transactions with
+// an external ID already imported into the portfolio are skipped; the number
+// of inserted rows is returned.
+func (d *DB) SaveTransactions(txs []models.Transaction) (int, error) {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO transactions (portfolio_id, time, type, asset, quantity, price, quote, fee, fee_asset, refid, external_id, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for i := range txs {
+		t := &txs[i]
+		var externalID any
+		if t.ExternalID != "" {
+			externalID = t.ExternalID
+		}
+		result, err := stmt.Exec(t.PortfolioID, t.Time.UTC(), t.Type, t.Asset, t.Quantity, t.Price, t.Quote,
+			t.Fee, t.FeeAsset, t.RefID, externalID, t.Source)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			t.ID, _ = result.LastInsertId()
+			inserted++
+		}
+	}
+
+	return inserted, tx.Commit()
+}
+
+func (d *DB) GetTransactions(portfolioID int64) ([]models.Transaction, error) {
+	rows, err := d.query(`SELECT id, portfolio_id, time, type, asset, quantity, price, quote, fee, fee_asset, refid, external_id, source
+		FROM transactions WHERE portfolio_id = ? ORDER BY time, id`, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		var quote, feeAsset, refID, externalID sql.NullString
+		err := rows.Scan(&t.ID, &t.PortfolioID, &t.Time, &t.Type, &t.Asset, &t.Quantity, &t.Price, &quote,
+			&t.Fee, &feeAsset, &refID, &externalID, &t.Source)
+		if err != nil {
+			return nil, err
+		}
+		t.Quote, t.FeeAsset, t.RefID, t.ExternalID = quote.String, feeAsset.String, refID.String, externalID.String
+		txs = append(txs, t)
+	}
+	return txs, nil
+}
+
+func (d *DB) DeleteTransaction(portfolioID, id int64) error {
+	result, err := d.exec(`DELETE FROM transactions WHERE id = ? AND portfolio_id = ?`, id, portfolioID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func constraintError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func (d *DB) SaveIndexPoint(point *models.IndexPoint) error {
+	components, err := json.Marshal(point.Components)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO price_index (asset, quote, timestamp, vwap, median, used, rejected, components)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := d.exec(query, point.Asset, point.Quote, point.Timestamp.UTC(), point.VWAP, point.Median,
+		point.Used, point.Rejected, string(components))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	point.ID = id
+	return nil
+}
+
+// GetIndexPointsFromDB returns the index series of an asset, oldest first.
+// Components are only loaded when withComponents is set.
+func (d *DB) GetIndexPointsFromDB(asset, quote string, from, to time.Time, withComponents bool) ([]models.IndexPoint, error) {
+	query := `SELECT id, asset, quote, timestamp, vwap, median, used, rejected, components
+		FROM price_index
+		WHERE asset = ? AND quote = ? AND timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp ASC`
+	rows, err := d.query(query, asset, quote, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []models.IndexPoint
+	for rows.Next() {
+		var p models.IndexPoint
+		var components string
+		err := rows.Scan(&p.ID, &p.Asset, &p.Quote, &p.Timestamp, &p.VWAP, &p.Median, &p.Used, &p.Rejected, &components)
+		if err != nil {
+			return nil, err
+		}
+		if withComponents {
+			if err := json.Unmarshal([]byte(components), &p.Components); err != nil {
+				return nil, err
+			}
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// GetLatestIndexPointFromDB returns the most recent index point of an asset
+// with its components.
+func (d *DB) GetLatestIndexPointFromDB(asset, quote string) (*models.IndexPoint, error) {
+	query := `SELECT id, asset, quote, timestamp, vwap, median, used, rejected, components
+		FROM price_index
+		WHERE asset = ? AND quote = ?
+		ORDER BY timestamp DESC, id DESC
+		LIMIT 1`
+	var p models.IndexPoint
+	var components string
+	err := d.queryRow(query, asset, quote).
+		Scan(&p.ID, &p.Asset, &p.Quote, &p.Timestamp, &p.VWAP, &p.Median, &p.Used, &p.Rejected, &components)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(components), &p.Components); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
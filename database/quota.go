@@ -0,0 +1,33 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ConsumeDailyQuota counts one request of client on the UTC day of t unless
+// it already made limit requests that day. It returns the number of
+// requests counted and whether this one was.
+func (d *DB) ConsumeDailyQuota(client string, t time.Time, limit int) (int, bool, error) {
+	day := t.UTC().Format(time.DateOnly)
+	var requests int
+	err := d.queryRow(`
+		INSERT INTO client_quotas (client, day, requests) VALUES (?, ?, 1)
+		ON CONFLICT(client, day) DO UPDATE SET requests = requests + 1
+		WHERE requests < ?
+		RETURNING requests
+	`, client, day, limit).Scan(&requests)
+	if err == sql.ErrNoRows {
+		return limit, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return requests, true, nil
+}
+
+// DeleteQuotasBefore removes the counters of the days before t.
+func (d *DB) DeleteQuotasBefore(t time.Time) error {
+	_, err := d.exec(`DELETE FROM client_quotas WHERE day < ?`, t.UTC().Format(time.DateOnly))
+	return err
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const pairSnapshotColumns = `t.name, t.base, t.quote, i.id, i.pair_id, i.price, i.volume_24h, i.high_24h, i.low_24h,
+	i.bid, i.ask, i.last_trade_volume, i.vwap_24h, i.trade_count_24h, i.open, i.timestamp`
+
+// GetLatestTopPairsFromDB returns the pairs of the source stored by the
+// latest collection, by decreasing 24 hours volume.
+func (d *DB) GetLatestTopPairsFromDB(source string, limit int) ([]models.PairSnapshot, error) {
+	rows, err := d.query(`SELECT `+pairSnapshotColumns+`
+		FROM pair_info i
+		JOIN trading_pairs t ON t.id = i.pair_id
+		WHERE t.source = ? AND i.timestamp = (
+			SELECT MAX(i2.timestamp) FROM pair_info i2
+			JOIN trading_pairs t2 ON t2.id = i2.pair_id
+			WHERE t2.source = ?
+		)
+		ORDER BY i.volume_24h DESC
+		LIMIT ?`, source, source, limit)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := scanPairSnapshots(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNotFound
+	}
+	return snapshots, nil
+}
+
+// GetLatestPairSnapshotFromDB returns the most recent ticker snapshot of a
+// pair of the source.
+func (d *DB) GetLatestPairSnapshotFromDB(source, pairName string) (*models.PairSnapshot, error) {
+	rows, err := d.query(`SELECT `+pairSnapshotColumns+`
+		FROM pair_info i
+		JOIN trading_pairs t ON t.id = i.pair_id
+		WHERE t.source = ? AND t.name = ?
+		ORDER BY i.timestamp DESC, i.id DESC
+		LIMIT 1`, source, pairName)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := scanPairSnapshots(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNotFound
+	}
+	return &snapshots[0], nil
+}
+
+func scanPairSnapshots(rows *sql.Rows) ([]models.PairSnapshot, error) {
+	defer rows.Close()
+
+	var snapshots []models.PairSnapshot
+	for rows.Next() {
+		var s models.PairSnapshot
+		err := rows.Scan(&s.Name, &s.Base, &s.Quote, &s.ID, &s.PairID, &s.Price, &s.Volume24h, &s.High24h, &s.Low24h,
+			&s.Bid, &s.Ask, &s.LastTradeVolume, &s.VWAP24h, &s.TradeCount24h, &s.Open, &s.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const serverStatusColumns = `id, timestamp, status, error, latency_ms, system_time, previous_status, clock_skew_ms`
+
+// GetLatestServerStatusFromDB returns the last Kraken check made at or
+// before t.
+func (d *DB) GetLatestServerStatusFromDB(t time.Time) (*models.ServerStatus, error) {
+	rows, err := d.query(`SELECT `+serverStatusColumns+` FROM server_status
+		WHERE timestamp <= ? ORDER BY timestamp DESC, id DESC LIMIT 1`, t.UTC())
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := scanServerStatuses(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return nil, ErrNotFound
+	}
+	return &statuses[0], nil
+}
+
+// GetServerStatusesFromDB returns the Kraken checks made between from and
+// to, oldest first.
+func (d *DB) GetServerStatusesFromDB(from, to time.Time) ([]models.ServerStatus, error) {
+	rows, err := d.query(`SELECT `+serverStatusColumns+` FROM server_status
+		WHERE timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp, id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return scanServerStatuses(rows)
+}
+
+func scanServerStatuses(rows *sql.Rows) ([]models.ServerStatus, error) {
+	defer rows.Close()
+
+	var statuses []models.ServerStatus
+	for rows.Next() {
+		var s models.ServerStatus
+		var errorJSON sql.NullString
+		var systemTime sql.NullTime
+		var skew sql.NullFloat64
+		err := rows.Scan(&s.ID, &s.Timestamp, &s.Status, &errorJSON, &s.LatencyMs, &systemTime, &s.PreviousStatus, &skew)
+		if err != nil {
+			return nil, err
+		}
+		s.Error = errorJSON.String
+		s.SystemTime = systemTime.Time
+		if skew.Valid {
+			s.ClockSkewMs = &skew.Float64
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func (d *DB) GetTradeCursor(pair string) (string, error) {
+	var last string
+	err := d.queryRow(`SELECT last FROM trade_cursors WHERE pair = ?`, pair).Scan(&last)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return last, err
+}
+
+// SaveTradesBatch stores the trades and advances the pair cursor in the same
+// transaction, so a failed write never skips trades on the next cycle.
+func (d *DB) SaveTradesBatch(pair string, trades []models.Trade, last string) (err error) {
+	ctx, end := d.startQuery(sqlTarget{operation: "insert", table: "trades"}, "")
+	defer func() { end(err) }()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO trades (pair, trade_id, price, volume, side, order_type, misc, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var inserted int64
+	for _, t := range trades {
+		// Without a Kraken id, the trade is deduplicated on its time, price
+		// and volume by idx_trades_untracked.
+		tradeID := sql.NullInt64{Int64: t.TradeID, Valid: t.TradeID != 0}
+		result, err := stmt.Exec(pair, tradeID, t.Price, t.Volume, t.Side, t.OrderType, t.Misc, t.Timestamp.UTC())
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			inserted += n
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO trade_cursors (pair, last, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(pair) DO UPDATE SET last = excluded.last, updated_at = excluded.updated_at
+	`, pair, last, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.recordWrite("trades", inserted)
+	return nil
+}
+
+func (d *DB) GetTradesFromDB(pair string, from, to time.Time, limit int) ([]models.Trade, error) {
+	return d.getTrades(pair, from, to, 0, limit)
+}
+
+// GetLargeTradesFromDB returns the trades of at least minVolume, most recent
+// first.
+func (d *DB) GetLargeTradesFromDB(pair string, from, to time.Time, minVolume float64, limit int) ([]models.Trade, error) {
+	return d.getTrades(pair, from, to, minVolume, limit)
+}
+
+func (d *DB) getTrades(pair string, from, to time.Time, minVolume float64, limit int) ([]models.Trade, error) {
+	query := `SELECT id, pair, trade_id, price, volume, side, order_type, misc, timestamp
+		FROM trades
+		WHERE pair = ? AND timestamp >= ? AND timestamp <= ? AND volume >= ?
+		ORDER BY timestamp DESC`
+	args := []any{pair, from.UTC(), to.UTC(), minVolume}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []models.Trade
+	for rows.Next() {
+		var t models.Trade
+		var tradeID sql.NullInt64
+		var misc sql.NullString
+		err := rows.Scan(&t.ID, &t.Pair, &tradeID, &t.Price, &t.Volume, &t.Side, &t.OrderType, &misc, &t.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		t.TradeID = tradeID.Int64
+		t.Misc = misc.String
+		trades = append(trades, t)
+	}
+	return trades, nil
+}
+
+// GetTradeAggregates sums the trades of the range per side and, when bucket
+// is positive, per bucket of that width aligned on the Unix epoch. The sums
+// are computed by SQLite, so a long range is never loaded in memory.
+func (d *DB) GetTradeAggregates(pair string, from, to time.Time, bucket time.Duration) ([]models.TradeAggregate, error) {
+	seconds := int64(bucket / time.Second)
+	start := `0`
+	if seconds > 0 {
+		start = `CAST(strftime('%s', timestamp) AS INTEGER) / ? * ?`
+	}
+	query := `SELECT ` + start + ` AS start, side, COUNT(*), SUM(volume), SUM(price * volume)
+		FROM trades
+		WHERE pair = ? AND timestamp >= ? AND timestamp <= ?
+		GROUP BY start, side
+		ORDER BY start, side`
+	var args []any
+	if seconds > 0 {
+		args = append(args, seconds, seconds)
+	}
+	args = append(args, pair, from.UTC(), to.UTC())
+
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aggregates []models.TradeAggregate
+	for rows.Next() {
+		var a models.TradeAggregate
+		var unix int64
+		if err := rows.Scan(&unix, &a.Side, &a.Count, &a.Volume, &a.Notional); err != nil {
+			return nil, err
+		}
+		if seconds > 0 {
+			a.Start = time.Unix(unix, 0).UTC()
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, nil
+}
+
+// migrateTradeIDs makes trades.trade_id nullable in databases created when
+// it was NOT NULL: trades without a Kraken id were stored with the id 0 and
+// the UNIQUE(pair, trade_id) constraint dropped all of them but the first.
+func (d *DB) migrateTradeIDs() error {
+	var notNull bool
+	err := d.queryRow(`SELECT "notnull" FROM pragma_table_info('trades') WHERE name = 'trade_id'`).Scan(&notNull)
+	if err != nil || !notNull {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`CREATE TABLE trades_migrated (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pair TEXT NOT NULL,
+			trade_id INTEGER,
+			price REAL NOT NULL,
+			volume REAL NOT NULL,
+			side TEXT NOT NULL,
+			order_type TEXT NOT NULL,
+			misc TEXT,
+			timestamp DATETIME NOT NULL,
+			source TEXT NOT NULL DEFAULT 'kraken',
+			UNIQUE(pair, trade_id)
+		)`,
+		`INSERT INTO trades_migrated (id, pair, trade_id, price, volume, side, order_type, misc, timestamp, source)
+			SELECT id, pair, NULLIF(trade_id, 0), price, volume, side, order_type, misc, timestamp, source FROM trades`,
+		`DROP TABLE trades`,
+		`ALTER TABLE trades_migrated RENAME TO trades`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_pair_timestamp ON trades(pair, timestamp)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("colonne trades.trade_id rendue facultative")
+	return nil
+}
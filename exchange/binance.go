@@ -0,0 +1,271 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+const binanceBaseURL = "https://api.binance.com/api/v3"
+
+var binanceIntervals = map[time.Duration]string{
+	time.Minute:        "1m",
+	5 * time.Minute:    "5m",
+	15 * time.Minute:   "15m",
+	30 * time.Minute:   "30m",
+	time.Hour:          "1h",
+	4 * time.Hour:      "4h",
+	24 * time.Hour:     "1d",
+	7 * 24 * time.Hour: "1w",
+}
+
+// Binance reads the public market data of Binance's spot REST API.
+type Binance struct {
+	httpClient *http.Client
+	baseURL    string
+	markets    *marketCache
+	ctx        context.Context
+}
+
+func NewBinance() *Binance {
+	b := &Binance{httpClient: newHTTPClient(), baseURL: binanceBaseURL, ctx: context.Background()}
+	b.markets = &marketCache{exchange: b.Name()}
+	return b
+}
+
+// SetBaseURL points the adapter to another server, e.g. a local fixture
+// server.
+func (b *Binance) SetBaseURL(u string) {
+	b.baseURL = strings.TrimSuffix(u, "/")
+}
+
+func (b *Binance) Name() string {
+	return "binance"
+}
+
+func (b *Binance) WithContext(ctx context.Context) Exchange {
+	clone := *b
+	clone.ctx = ctx
+	return &clone
+}
+
+func (b *Binance) get(endpoint string, params url.Values, v any) error {
+	u := fmt.Sprintf("%s/%s", b.baseURL, endpoint)
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return getJSON(b.ctx, b.httpClient, b.Name(), u, v)
+}
+
+func (b *Binance) loadMarkets() ([]Market, error) {
+	var info struct {
+		Symbols []struct {
+			Symbol     string `json:"symbol"`
+			Status     string `json:"status"`
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+		} `json:"symbols"`
+	}
+	if err := b.get("exchangeInfo", nil, &info); err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(info.Symbols))
+	for _, s := range info.Symbols {
+		base, quote := assets.Normalize(s.BaseAsset), assets.Normalize(s.QuoteAsset)
+		markets = append(markets, Market{
+			Symbol: base + "/" + quote,
+			Native: s.Symbol,
+			Base:   base,
+			Quote:  quote,
+			Active: s.Status == "TRADING",
+		})
+	}
+	return markets, nil
+}
+
+func (b *Binance) ListMarkets() ([]Market, error) {
+	return b.markets.list(b.loadMarkets)
+}
+
+func (b *Binance) Ticker(symbol string) (*Ticker, error) {
+	market, err := b.markets.resolve(symbol, b.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	var t struct {
+		LastPrice        string `json:"lastPrice"`
+		BidPrice         string `json:"bidPrice"`
+		AskPrice         string `json:"askPrice"`
+		OpenPrice        string `json:"openPrice"`
+		HighPrice        string `json:"highPrice"`
+		LowPrice         string `json:"lowPrice"`
+		Volume           string `json:"volume"`
+		WeightedAvgPrice string `json:"weightedAvgPrice"`
+		CloseTime        int64  `json:"closeTime"`
+	}
+	params := url.Values{}
+	params.Set("symbol", market.Native)
+	if err := b.get("ticker/24hr", params, &t); err != nil {
+		return nil, err
+	}
+
+	ticker := &Ticker{
+		Symbol:    market.Symbol,
+		Native:    market.Native,
+		Timestamp: time.UnixMilli(t.CloseTime).UTC(),
+	}
+	for _, f := range []struct {
+		dst *float64
+		raw string
+	}{
+		{&ticker.Last, t.LastPrice},
+		{&ticker.Bid, t.BidPrice},
+		{&ticker.Ask, t.AskPrice},
+		{&ticker.Open, t.OpenPrice},
+		{&ticker.High24h, t.HighPrice},
+		{&ticker.Low24h, t.LowPrice},
+		{&ticker.Volume24h, t.Volume},
+		{&ticker.VWAP24h, t.WeightedAvgPrice},
+	} {
+		if *f.dst, err = strconv.ParseFloat(f.raw, 64); err != nil {
+			return nil, fmt.Errorf("binance: ticker invalide: %v", err)
+		}
+	}
+	return ticker, nil
+}
+
+func (b *Binance) OHLC(symbol string, interval time.Duration, since time.Time) ([]candles.Candle, error) {
+	code, ok := binanceIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("binance: intervalle %s non supporté", interval)
+	}
+	market, err := b.markets.resolve(symbol, b.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("symbol", market.Native)
+	params.Set("interval", code)
+	params.Set("limit", "1000")
+	if !since.IsZero() {
+		params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+
+	var rows [][]json.RawMessage
+	if err := b.get("klines", params, &rows); err != nil {
+		return nil, err
+	}
+
+	result := make([]candles.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("binance: bougie invalide")
+		}
+		values := make([]float64, 6)
+		for i := range values {
+			if values[i], err = parseFloat(row[i]); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, candles.Candle{
+			Time:   time.UnixMilli(int64(values[0])).UTC(),
+			Open:   values[1],
+			High:   values[2],
+			Low:    values[3],
+			Close:  values[4],
+			Volume: values[5],
+		})
+	}
+	return result, nil
+}
+
+func (b *Binance) Depth(symbol string, count int) (*OrderBook, error) {
+	market, err := b.markets.resolve(symbol, b.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("symbol", market.Native)
+	if count > 0 {
+		params.Set("limit", strconv.Itoa(count))
+	}
+
+	var book struct {
+		Bids [][]json.RawMessage `json:"bids"`
+		Asks [][]json.RawMessage `json:"asks"`
+	}
+	if err := b.get("depth", params, &book); err != nil {
+		return nil, err
+	}
+
+	bids, err := parseLevels(book.Bids, count)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(book.Asks, count)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderBook{Symbol: market.Symbol, Bids: bids, Asks: asks, Timestamp: time.Now().UTC()}, nil
+}
+
+func (b *Binance) Trades(symbol string, limit int) ([]Trade, error) {
+	market, err := b.markets.resolve(symbol, b.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("symbol", market.Native)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var raw []struct {
+		ID           int64  `json:"id"`
+		Price        string `json:"price"`
+		Qty          string `json:"qty"`
+		Time         int64  `json:"time"`
+		IsBuyerMaker bool   `json:"isBuyerMaker"`
+	}
+	if err := b.get("trades", params, &raw); err != nil {
+		return nil, err
+	}
+
+	trades := make([]Trade, 0, len(raw))
+	for _, t := range raw {
+		price, err := strconv.ParseFloat(t.Price, 64)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := strconv.ParseFloat(t.Qty, 64)
+		if err != nil {
+			return nil, err
+		}
+		// The buyer being the maker means the taker sold.
+		side := "buy"
+		if t.IsBuyerMaker {
+			side = "sell"
+		}
+		trades = append(trades, Trade{
+			ID:     strconv.FormatInt(t.ID, 10),
+			Price:  price,
+			Volume: volume,
+			Side:   side,
+			Time:   time.UnixMilli(t.Time).UTC(),
+		})
+	}
+	return trades, nil
+}
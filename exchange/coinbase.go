@@ -0,0 +1,280 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+const coinbaseBaseURL = "https://api.exchange.coinbase.com"
+
+// coinbaseGranularities are the candle sizes accepted by Coinbase, which
+// returns at most 300 candles per request.
+var coinbaseGranularities = map[time.Duration]bool{
+	time.Minute:      true,
+	5 * time.Minute:  true,
+	15 * time.Minute: true,
+	time.Hour:        true,
+	6 * time.Hour:    true,
+	24 * time.Hour:   true,
+}
+
+const coinbaseMaxCandles = 300
+
+// Coinbase reads the public market data of the Coinbase Exchange REST API.
+type Coinbase struct {
+	httpClient *http.Client
+	baseURL    string
+	markets    *marketCache
+	ctx        context.Context
+}
+
+func NewCoinbase() *Coinbase {
+	c := &Coinbase{httpClient: newHTTPClient(), baseURL: coinbaseBaseURL, ctx: context.Background()}
+	c.markets = &marketCache{exchange: c.Name()}
+	return c
+}
+
+// SetBaseURL points the adapter to another server, e.g. a local fixture
+// server.
+func (c *Coinbase) SetBaseURL(u string) {
+	c.baseURL = strings.TrimSuffix(u, "/")
+}
+
+func (c *Coinbase) Name() string {
+	return "coinbase"
+}
+
+func (c *Coinbase) WithContext(ctx context.Context) Exchange {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *Coinbase) get(path string, params url.Values, v any) error {
+	u := c.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return getJSON(c.ctx, c.httpClient, c.Name(), u, v)
+}
+
+func (c *Coinbase) loadMarkets() ([]Market, error) {
+	var products []struct {
+		ID              string `json:"id"`
+		BaseCurrency    string `json:"base_currency"`
+		QuoteCurrency   string `json:"quote_currency"`
+		Status          string `json:"status"`
+		TradingDisabled bool   `json:"trading_disabled"`
+	}
+	if err := c.get("/products", nil, &products); err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(products))
+	for _, p := range products {
+		base, quote := assets.Normalize(p.BaseCurrency), assets.Normalize(p.QuoteCurrency)
+		markets = append(markets, Market{
+			Symbol: base + "/" + quote,
+			Native: p.ID,
+			Base:   base,
+			Quote:  quote,
+			Active: p.Status == "online" && !p.TradingDisabled,
+		})
+	}
+	return markets, nil
+}
+
+func (c *Coinbase) ListMarkets() ([]Market, error) {
+	return c.markets.list(c.loadMarkets)
+}
+
+// Ticker combines the product ticker (last trade, best bid and ask) with the
+// 24h stats (open, high, low, volume).
+func (c *Coinbase) Ticker(symbol string) (*Ticker, error) {
+	market, err := c.markets.resolve(symbol, c.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	var t struct {
+		Price string    `json:"price"`
+		Bid   string    `json:"bid"`
+		Ask   string    `json:"ask"`
+		Time  time.Time `json:"time"`
+	}
+	if err := c.get("/products/"+url.PathEscape(market.Native)+"/ticker", nil, &t); err != nil {
+		return nil, err
+	}
+	var stats struct {
+		Open   string `json:"open"`
+		High   string `json:"high"`
+		Low    string `json:"low"`
+		Volume string `json:"volume"`
+	}
+	if err := c.get("/products/"+url.PathEscape(market.Native)+"/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+
+	ticker := &Ticker{Symbol: market.Symbol, Native: market.Native, Timestamp: t.Time.UTC()}
+	for _, f := range []struct {
+		dst *float64
+		raw string
+	}{
+		{&ticker.Last, t.Price},
+		{&ticker.Bid, t.Bid},
+		{&ticker.Ask, t.Ask},
+		{&ticker.Open, stats.Open},
+		{&ticker.High24h, stats.High},
+		{&ticker.Low24h, stats.Low},
+		{&ticker.Volume24h, stats.Volume},
+	} {
+		if *f.dst, err = strconv.ParseFloat(f.raw, 64); err != nil {
+			return nil, fmt.Errorf("coinbase: ticker invalide: %v", err)
+		}
+	}
+	return ticker, nil
+}
+
+func (c *Coinbase) OHLC(symbol string, interval time.Duration, since time.Time) ([]candles.Candle, error) {
+	if !coinbaseGranularities[interval] {
+		return nil, fmt.Errorf("coinbase: intervalle %s non supporté", interval)
+	}
+	market, err := c.markets.resolve(symbol, c.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("granularity", strconv.Itoa(int(interval.Seconds())))
+	if !since.IsZero() {
+		end := since.Add(interval * coinbaseMaxCandles)
+		if now := time.Now(); end.After(now) {
+			end = now
+		}
+		params.Set("start", since.UTC().Format(time.RFC3339))
+		params.Set("end", end.UTC().Format(time.RFC3339))
+	}
+
+	// Rows are [time, low, high, open, close, volume], newest first.
+	var rows [][]json.RawMessage
+	if err := c.get("/products/"+url.PathEscape(market.Native)+"/candles", params, &rows); err != nil {
+		return nil, err
+	}
+
+	result := make([]candles.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("coinbase: bougie invalide")
+		}
+		values := make([]float64, 6)
+		for i := range values {
+			if values[i], err = parseFloat(row[i]); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, candles.Candle{
+			Time:   time.Unix(int64(values[0]), 0).UTC(),
+			Low:    values[1],
+			High:   values[2],
+			Open:   values[3],
+			Close:  values[4],
+			Volume: values[5],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
+func (c *Coinbase) Depth(symbol string, count int) (*OrderBook, error) {
+	market, err := c.markets.resolve(symbol, c.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("level", "2")
+
+	var book struct {
+		Bids [][]json.RawMessage `json:"bids"`
+		Asks [][]json.RawMessage `json:"asks"`
+		Time time.Time           `json:"time"`
+	}
+	if err := c.get("/products/"+url.PathEscape(market.Native)+"/book", params, &book); err != nil {
+		return nil, err
+	}
+
+	bids, err := parseLevels(book.Bids, count)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(book.Asks, count)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := book.Time.UTC()
+	if book.Time.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	return &OrderBook{Symbol: market.Symbol, Bids: bids, Asks: asks, Timestamp: timestamp}, nil
+}
+
+func (c *Coinbase) Trades(symbol string, limit int) ([]Trade, error) {
+	market, err := c.markets.resolve(symbol, c.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var raw []struct {
+		TradeID int64     `json:"trade_id"`
+		Price   string    `json:"price"`
+		Size    string    `json:"size"`
+		Side    string    `json:"side"`
+		Time    time.Time `json:"time"`
+	}
+	if err := c.get("/products/"+url.PathEscape(market.Native)+"/trades", params, &raw); err != nil {
+		return nil, err
+	}
+
+	trades := make([]Trade, 0, len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		t := raw[i]
+		price, err := strconv.ParseFloat(t.Price, 64)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := strconv.ParseFloat(t.Size, 64)
+		if err != nil {
+			return nil, err
+		}
+		// Coinbase reports the maker side; the taker is on the other side.
+		side := "buy"
+		if t.Side == "buy" {
+			side = "sell"
+		}
+		trades = append(trades, Trade{
+			ID:     strconv.FormatInt(t.TradeID, 10),
+			Price:  price,
+			Volume: volume,
+			Side:   side,
+			Time:   t.Time.UTC(),
+		})
+	}
+	return trades, nil
+}
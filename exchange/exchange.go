@@ -0,0 +1,259 @@
+// Package exchange provides a common interface over the public market data
+// of several venues, so that handlers and collectors do not depend on one
+// exchange's response shapes.
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// marketsTTL bounds how long the list of markets of a venue is reused.
+const marketsTTL = time.Hour
+
+// Market is a tradable pair. Symbol is the canonical "BASE/QUOTE" form built
+// from normalized asset codes, Native the venue's own identifier.
+type Market struct {
+	Symbol   string   `json:"symbol"`
+	Native   string   `json:"native"`
+	Base     string   `json:"base"`
+	Quote    string   `json:"quote"`
+	Active   bool     `json:"active"`
+	AltNames []string `json:"alt_names,omitempty"`
+}
+
+// Ticker is the 24h summary of a market. Timestamp is the time of the
+// venue's data when it reports one, the time of the request otherwise.
+type Ticker struct {
+	Symbol    string    `json:"symbol"`
+	Native    string    `json:"native"`
+	Last      float64   `json:"last"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	Open      float64   `json:"open"`
+	High24h   float64   `json:"high_24h"`
+	Low24h    float64   `json:"low_24h"`
+	Volume24h float64   `json:"volume_24h"`
+	VWAP24h   float64   `json:"vwap_24h,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type BookLevel struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+}
+
+type OrderBook struct {
+	Symbol    string      `json:"symbol"`
+	Bids      []BookLevel `json:"bids"`
+	Asks      []BookLevel `json:"asks"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// Trade is a public trade; Side is the side of the taker.
+type Trade struct {
+	ID     string    `json:"id"`
+	Price  float64   `json:"price"`
+	Volume float64   `json:"volume"`
+	Side   string    `json:"side"`
+	Time   time.Time `json:"time"`
+}
+
+// Exchange is implemented by every venue adapter. Symbols may be given in
+// canonical form ("BTC/USD") or in the venue's native form.
+type Exchange interface {
+	Name() string
+	// WithContext returns a copy of the adapter whose requests carry ctx.
+	// The copy shares the markets of the original.
+	WithContext(ctx context.Context) Exchange
+	ListMarkets() ([]Market, error)
+	Ticker(symbol string) (*Ticker, error)
+	OHLC(symbol string, interval time.Duration, since time.Time) ([]candles.Candle, error)
+	Depth(symbol string, count int) (*OrderBook, error)
+	Trades(symbol string, limit int) ([]Trade, error)
+}
+
+// ErrUnknownMarket is returned when a venue does not list a symbol.
+type ErrUnknownMarket struct {
+	Exchange string
+	Symbol   string
+}
+
+func (e ErrUnknownMarket) Error() string {
+	return fmt.Sprintf("%s: marché inconnu %s", e.Exchange, e.Symbol)
+}
+
+// Symbol returns the canonical symbol of a pair of asset codes.
+func Symbol(base, quote string) string {
+	return assets.Normalize(base) + "/" + assets.Normalize(quote)
+}
+
+// marketCache keeps the markets of a venue and resolves symbols against
+// them. The loader is given by the caller, so that the markets are fetched
+// with the context of the adapter copy that needs them.
+type marketCache struct {
+	exchange string
+
+	mu        sync.Mutex
+	markets   []Market
+	bySymbol  map[string]Market
+	fetchedAt time.Time
+}
+
+func (m *marketCache) list(load func() ([]Market, error)) ([]Market, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.markets == nil || time.Since(m.fetchedAt) > marketsTTL {
+		markets, err := load()
+		if err != nil {
+			if m.markets == nil {
+				return nil, err
+			}
+			return m.markets, nil
+		}
+		sort.Slice(markets, func(i, j int) bool {
+			return markets[i].Native < markets[j].Native
+		})
+
+		bySymbol := make(map[string]Market, len(markets)*2)
+		for _, market := range markets {
+			for _, key := range append([]string{market.Native}, market.AltNames...) {
+				bySymbol[strings.ToUpper(key)] = market
+			}
+		}
+		// Canonical symbols go last so that they never shadow a native name,
+		// and an active market wins when several share a symbol.
+		for _, market := range markets {
+			if existing, ok := bySymbol[market.Symbol]; ok && (existing.Native == market.Symbol || existing.Active) {
+				continue
+			}
+			bySymbol[market.Symbol] = market
+		}
+		m.markets, m.bySymbol, m.fetchedAt = markets, bySymbol, time.Now()
+	}
+	return m.markets, nil
+}
+
+func (m *marketCache) resolve(symbol string, load func() ([]Market, error)) (Market, error) {
+	if _, err := m.list(load); err != nil {
+		return Market{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := strings.ToUpper(strings.TrimSpace(symbol))
+	if market, ok := m.bySymbol[key]; ok {
+		return market, nil
+	}
+	// Pair names of other venues ("XXBTZUSD" on Coinbase) resolve through
+	// their canonical symbol.
+	if canonical, ok := assets.NormalizePair(key); ok {
+		if market, ok := m.bySymbol[canonical]; ok {
+			return market, nil
+		}
+	}
+	return Market{}, ErrUnknownMarket{Exchange: m.exchange, Symbol: symbol}
+}
+
+// getJSON decodes the JSON body of a GET request, turning non-2xx answers
+// into errors that include the venue's message.
+func getJSON(ctx context.Context, client *http.Client, exchange, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Go-CryptoPrice")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: HTTP %d: %s", exchange, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// newHTTPClient returns a client whose requests are traced as children of
+// the span of their context.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+}
+
+// parseLevels converts [price, volume, ...] string arrays.
+func parseLevels(raw [][]json.RawMessage, count int) ([]BookLevel, error) {
+	if count > 0 && len(raw) > count {
+		raw = raw[:count]
+	}
+	levels := make([]BookLevel, 0, len(raw))
+	for _, level := range raw {
+		if len(level) < 2 {
+			return nil, fmt.Errorf("niveau de carnet invalide: %s", level)
+		}
+		price, err := parseFloat(level[0])
+		if err != nil {
+			return nil, err
+		}
+		volume, err := parseFloat(level[1])
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, BookLevel{Price: price, Volume: volume})
+	}
+	return levels, nil
+}
+
+// parseFloat accepts numbers sent either as JSON numbers or as strings.
+func parseFloat(raw json.RawMessage) (float64, error) {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return f, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// New returns the adapter of a venue by name. The Kraken adapter wraps the
+// given client so that it shares its HTTP settings.
+func New(name string, krakenClient *kraken.Client) (Exchange, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "kraken":
+		return NewKraken(krakenClient), nil
+	case "binance":
+		return NewBinance(), nil
+	case "coinbase":
+		return NewCoinbase(), nil
+	default:
+		return nil, fmt.Errorf("exchange inconnu %q (kraken, binance ou coinbase)", name)
+	}
+}
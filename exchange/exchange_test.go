@@ -0,0 +1,426 @@
+package exchange
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+)
+
+// fixtureServer serves the responses recorded in testdata, keyed by request
+// path, and keeps the query of the last request to each path.
+type fixtureServer struct {
+	t     *testing.T
+	files map[string]string
+
+	mu      sync.Mutex
+	queries map[string]url.Values
+}
+
+func (f *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	file, ok := f.files[r.URL.Path]
+	if !ok {
+		f.t.Errorf("unexpected request %s", r.URL)
+		http.NotFound(w, r)
+		return
+	}
+	body, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		f.t.Errorf("reading fixture: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	f.mu.Lock()
+	f.queries[r.URL.Path] = r.URL.Query()
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (f *fixtureServer) query(path string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[path]
+}
+
+func serveFixtures(t *testing.T, files map[string]string) (*httptest.Server, *fixtureServer) {
+	t.Helper()
+	f := &fixtureServer{t: t, files: files, queries: make(map[string]url.Values)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return srv, f
+}
+
+// venue is an adapter served by the recorded responses of its exchange.
+type venue struct {
+	exchange Exchange
+	fixtures *fixtureServer
+	// paths of the endpoints called by the adapter, by method.
+	paths map[string]string
+}
+
+func newVenue(t *testing.T, name string) venue {
+	t.Helper()
+	switch name {
+	case "binance":
+		srv, f := serveFixtures(t, map[string]string{
+			"/api/v3/exchangeInfo": "binance/exchange_info.json",
+			"/api/v3/ticker/24hr":  "binance/ticker_24hr.json",
+			"/api/v3/klines":       "binance/klines.json",
+			"/api/v3/depth":        "binance/depth.json",
+			"/api/v3/trades":       "binance/trades.json",
+		})
+		b := NewBinance()
+		b.SetBaseURL(srv.URL + "/api/v3/")
+		return venue{exchange: b, fixtures: f, paths: map[string]string{
+			"ticker": "/api/v3/ticker/24hr",
+			"ohlc":   "/api/v3/klines",
+			"depth":  "/api/v3/depth",
+			"trades": "/api/v3/trades",
+		}}
+	case "coinbase":
+		srv, f := serveFixtures(t, map[string]string{
+			"/products":                 "coinbase/products.json",
+			"/products/BTC-USD/ticker":  "coinbase/ticker.json",
+			"/products/BTC-USD/stats":   "coinbase/stats.json",
+			"/products/BTC-USD/candles": "coinbase/candles.json",
+			"/products/BTC-USD/book":    "coinbase/book.json",
+			"/products/BTC-USD/trades":  "coinbase/trades.json",
+		})
+		c := NewCoinbase()
+		c.SetBaseURL(srv.URL)
+		return venue{exchange: c, fixtures: f, paths: map[string]string{
+			"ticker": "/products/BTC-USD/ticker",
+			"ohlc":   "/products/BTC-USD/candles",
+			"depth":  "/products/BTC-USD/book",
+			"trades": "/products/BTC-USD/trades",
+		}}
+	case "kraken":
+		srv, f := serveFixtures(t, map[string]string{
+			"/0/public/AssetPairs": "kraken/asset_pairs.json",
+			"/0/public/Ticker":     "kraken/ticker.json",
+			"/0/public/OHLC":       "kraken/ohlc.json",
+			"/0/public/Depth":      "kraken/depth.json",
+			"/0/public/Trades":     "kraken/trades.json",
+		})
+		client := kraken.NewClient()
+		client.SetBaseURL(srv.URL + "/0")
+		return venue{exchange: NewKraken(client), fixtures: f, paths: map[string]string{
+			"ticker": "/0/public/Ticker",
+			"ohlc":   "/0/public/OHLC",
+			"depth":  "/0/public/Depth",
+			"trades": "/0/public/Trades",
+		}}
+	}
+	t.Fatalf("unknown venue %s", name)
+	return venue{}
+}
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestListMarkets(t *testing.T) {
+	tests := []struct {
+		venue string
+		want  []Market
+	}{
+		{"binance", []Market{
+			{Symbol: "BTC/USD", Native: "BTCUSD", Base: "BTC", Quote: "USD", Active: false},
+			{Symbol: "BTC/USDC", Native: "BTCUSDC", Base: "BTC", Quote: "USDC", Active: true},
+			{Symbol: "BTC/USDT", Native: "BTCUSDT", Base: "BTC", Quote: "USDT", Active: true},
+			{Symbol: "ETH/BTC", Native: "ETHBTC", Base: "ETH", Quote: "BTC", Active: true},
+		}},
+		{"coinbase", []Market{
+			{Symbol: "BTC/USD", Native: "BTC-USD", Base: "BTC", Quote: "USD", Active: true},
+			{Symbol: "ETH/BTC", Native: "ETH-BTC", Base: "ETH", Quote: "BTC", Active: true},
+			{Symbol: "USDT/USD", Native: "USDT-USD", Base: "USDT", Quote: "USD", Active: false},
+			{Symbol: "XTZ/USD", Native: "XTZ-USD", Base: "XTZ", Quote: "USD", Active: true},
+		}},
+		{"kraken", []Market{
+			{Symbol: "PYUSD/USD", Native: "PYUSDUSD", Base: "PYUSD", Quote: "USD", Active: true, AltNames: []string{"PYUSDUSD", "PYUSD/USD"}},
+			{Symbol: "STETH/ETH", Native: "STETHETH", Base: "STETH", Quote: "ETH", Active: true, AltNames: []string{"STETHETH", "STETH/ETH"}},
+			{Symbol: "ETH/BTC", Native: "XETHXXBT", Base: "ETH", Quote: "BTC", Active: true, AltNames: []string{"ETHXBT", "ETH/XBT"}},
+			{Symbol: "XTZ/USD", Native: "XTZUSD", Base: "XTZ", Quote: "USD", Active: true, AltNames: []string{"XTZUSD", "XTZ/USD"}},
+			{Symbol: "BTC/USD", Native: "XXBTZUSD", Base: "BTC", Quote: "USD", Active: true, AltNames: []string{"XBTUSD", "XBT/USD"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.venue, func(t *testing.T) {
+			markets, err := newVenue(t, tt.venue).exchange.ListMarkets()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(markets, tt.want) {
+				t.Errorf("markets:\ngot  %+v\nwant %+v", markets, tt.want)
+			}
+		})
+	}
+}
+
+func TestTicker(t *testing.T) {
+	tests := []struct {
+		venue  string
+		symbol string
+		query  url.Values
+		want   Ticker
+	}{
+		{"binance", "BTC/USDT", url.Values{"symbol": {"BTCUSDT"}}, Ticker{
+			Symbol: "BTC/USDT", Native: "BTCUSDT", Last: 67001.01, Bid: 67001, Ask: 67001.01, Open: 65977.56,
+			High24h: 67400, Low24h: 65800.1, Volume24h: 21457.83219, VWAP24h: 66812.53471522,
+			Timestamp: at("2024-06-10T12:00:05.123Z"),
+		}},
+		{"coinbase", "BTC/USD", url.Values{}, Ticker{
+			Symbol: "BTC/USD", Native: "BTC-USD", Last: 67003.12, Bid: 67003.11, Ask: 67003.12, Open: 65981.02,
+			High24h: 67399.99, Low24h: 65801.25, Volume24h: 9342.20551201,
+			Timestamp: at("2024-06-10T12:00:04.981763Z"),
+		}},
+		{"kraken", "XBTUSD", url.Values{"pair": {"XXBTZUSD"}}, Ticker{
+			Symbol: "BTC/USD", Native: "XXBTZUSD", Last: 67005.1, Bid: 67005, Ask: 67005.1, Open: 65983.4,
+			High24h: 67420, Low24h: 65805.1, Volume24h: 3185.33472651, VWAP24h: 66819.02311,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.venue, func(t *testing.T) {
+			v := newVenue(t, tt.venue)
+			ticker, err := v.exchange.Ticker(tt.symbol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := *ticker
+			if tt.want.Timestamp.IsZero() {
+				// Kraken's ticker carries no time.
+				got.Timestamp = time.Time{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ticker:\ngot  %+v\nwant %+v", got, tt.want)
+			}
+			if q := v.fixtures.query(v.paths["ticker"]); !reflect.DeepEqual(q, tt.query) {
+				t.Errorf("query = %v, want %v", q, tt.query)
+			}
+		})
+	}
+}
+
+func TestOHLC(t *testing.T) {
+	since := at("2024-06-10T10:00:00Z")
+	tests := []struct {
+		venue string
+		query url.Values
+		want  [][6]float64
+	}{
+		{"binance", url.Values{"symbol": {"BTCUSDT"}, "interval": {"1h"}, "limit": {"1000"}, "startTime": {"1718013600000"}}, [][6]float64{
+			{1718013600, 66850, 66990, 66790.12, 66920.5, 512.3341},
+			{1718017200, 66920.51, 67050, 66901, 67001.01, 430.10025},
+		}},
+		{"coinbase", url.Values{"granularity": {"3600"}, "start": {"2024-06-10T10:00:00Z"}, "end": {"2024-06-22T22:00:00Z"}}, [][6]float64{
+			{1718013600, 66853.2, 66992.45, 66795.01, 66921.3, 215.80024518},
+			{1718017200, 66921.3, 67052.12, 66903.5, 67003.12, 182.40311925},
+		}},
+		{"kraken", url.Values{"pair": {"XXBTZUSD"}, "interval": {"60"}, "since": {"1718013600"}}, [][6]float64{
+			{1718013600, 66852.1, 66995, 66790, 66925.3, 98.17264051},
+			{1718017200, 66925.3, 67055, 66910, 67005.1, 81.40337195},
+		}},
+	}
+
+	symbols := map[string]string{"binance": "BTCUSDT", "coinbase": "BTC-USD", "kraken": "BTC/USD"}
+	for _, tt := range tests {
+		t.Run(tt.venue, func(t *testing.T) {
+			v := newVenue(t, tt.venue)
+			rows, err := v.exchange.OHLC(symbols[tt.venue], time.Hour, since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d candles, want %d", len(rows), len(tt.want))
+			}
+			for i, c := range rows {
+				got := [6]float64{float64(c.Time.Unix()), c.Open, c.High, c.Low, c.Close, c.Volume}
+				if got != tt.want[i] || c.Time.Location() != time.UTC {
+					t.Errorf("candle %d = %v (%s), want %v in UTC", i, got, c.Time.Location(), tt.want[i])
+				}
+			}
+			if q := v.fixtures.query(v.paths["ohlc"]); !reflect.DeepEqual(q, tt.query) {
+				t.Errorf("query = %v, want %v", q, tt.query)
+			}
+		})
+	}
+
+	t.Run("unsupported interval", func(t *testing.T) {
+		for _, name := range []string{"binance", "coinbase", "kraken"} {
+			if _, err := newVenue(t, name).exchange.OHLC("BTC/USD", 2*time.Hour, time.Time{}); err == nil {
+				t.Errorf("%s: no error for a 2h interval", name)
+			}
+		}
+	})
+}
+
+func TestDepth(t *testing.T) {
+	tests := []struct {
+		venue string
+		query url.Values
+		bids  []BookLevel
+		asks  []BookLevel
+	}{
+		{"binance", url.Values{"symbol": {"BTCUSD"}, "limit": {"2"}},
+			[]BookLevel{{67001, 2.31}, {67000.5, 0.012}},
+			[]BookLevel{{67001.01, 0.405}, {67001.5, 0.1}}},
+		{"coinbase", url.Values{"level": {"2"}},
+			[]BookLevel{{67003.11, 0.41211}, {67003.1, 0.0015}},
+			[]BookLevel{{67003.12, 0.10523}, {67003.5, 0.25}}},
+		// Kraken applies the count itself, so the whole recorded book is
+		// returned.
+		{"kraken", url.Values{"pair": {"XXBTZUSD"}, "count": {"2"}},
+			[]BookLevel{{67005, 3.104}, {67004.9, 0.15}, {67004, 1}},
+			[]BookLevel{{67005.1, 1.502}, {67005.5, 0.02}, {67006, 0.75}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.venue, func(t *testing.T) {
+			v := newVenue(t, tt.venue)
+			book, err := v.exchange.Depth("BTC/USD", 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(book.Bids, tt.bids) || !reflect.DeepEqual(book.Asks, tt.asks) {
+				t.Errorf("book:\ngot  bids %v asks %v\nwant bids %v asks %v", book.Bids, book.Asks, tt.bids, tt.asks)
+			}
+			if book.Symbol != "BTC/USD" || book.Timestamp.IsZero() {
+				t.Errorf("book %s at %s", book.Symbol, book.Timestamp)
+			}
+			if q := v.fixtures.query(v.paths["depth"]); !reflect.DeepEqual(q, tt.query) {
+				t.Errorf("query = %v, want %v", q, tt.query)
+			}
+		})
+	}
+}
+
+func TestTrades(t *testing.T) {
+	tests := []struct {
+		venue  string
+		symbol string
+		limit  int
+		want   []Trade
+	}{
+		// Binance reports whether the buyer was the maker.
+		{"binance", "BTC/USDT", 2, []Trade{
+			{ID: "3634217465", Price: 67001, Volume: 0.015, Side: "sell", Time: at("2024-06-10T12:00:04.912Z")},
+			{ID: "3634217466", Price: 67001.01, Volume: 0.0015, Side: "buy", Time: at("2024-06-10T12:00:05.101Z")},
+		}},
+		// Coinbase returns the newest trade first and reports the maker side.
+		{"coinbase", "BTC/USD", 2, []Trade{
+			{ID: "667854320", Price: 67003.11, Volume: 0.05, Side: "sell", Time: at("2024-06-10T12:00:04.512003Z")},
+			{ID: "667854321", Price: 67003.12, Volume: 0.00071236, Side: "buy", Time: at("2024-06-10T12:00:04.981763Z")},
+		}},
+		// Kraken returns the oldest trade first; the limit keeps the newest.
+		{"kraken", "XXBTZUSD", 1, []Trade{
+			{ID: "70218812", Price: 67005.1, Volume: 0.0015, Side: "buy", Time: at("2024-06-10T12:00:04.9876Z")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.venue, func(t *testing.T) {
+			trades, err := newVenue(t, tt.venue).exchange.Trades(tt.symbol, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(trades) != len(tt.want) {
+				t.Fatalf("got %d trades, want %d", len(trades), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := trades[i]
+				// Kraken's fractional seconds lose some precision in float64.
+				if d := got.Time.Sub(want.Time); d < -time.Millisecond || d > time.Millisecond {
+					t.Errorf("trade %d time = %s, want %s", i, got.Time, want.Time)
+				}
+				got.Time = want.Time
+				if got != want {
+					t.Errorf("trade %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	m := &marketCache{exchange: "test"}
+	load := func() ([]Market, error) {
+		return []Market{
+			{Symbol: "BTC/USD", Native: "XXBTZUSD", Base: "BTC", Quote: "USD", Active: true, AltNames: []string{"XBTUSD", "XBT/USD"}},
+			{Symbol: "BTC/USD", Native: "XBTUSD.M", Base: "BTC", Quote: "USD", Active: false},
+			{Symbol: "ETH/BTC", Native: "XETHXXBT", Base: "ETH", Quote: "BTC", Active: true, AltNames: []string{"ETHXBT", "ETH/XBT"}},
+			{Symbol: "BTC/EUR", Native: "BTC/EUR", Base: "BTC", Quote: "EUR", Active: true},
+			{Symbol: "BTC/EUR", Native: "XXBTZEUR", Base: "BTC", Quote: "EUR", Active: true},
+		}, nil
+	}
+
+	tests := []struct {
+		symbol string
+		native string
+	}{
+		{"XXBTZUSD", "XXBTZUSD"},
+		{"xbtusd", "XXBTZUSD"},
+		{"XBT/USD", "XXBTZUSD"},
+		{" BTC/USD ", "XXBTZUSD"}, // the active market wins
+		{"BTC-USD", "XXBTZUSD"},   // another venue's name, through the canonical symbol
+		{"ETH/BTC", "XETHXXBT"},
+		{"ETHXBT", "XETHXXBT"},
+		{"BTC/EUR", "BTC/EUR"}, // a native name is never shadowed by a symbol
+		{"XXBTZEUR", "XXBTZEUR"},
+		{"DOGE/USD", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			market, err := m.resolve(tt.symbol, load)
+			if tt.native == "" {
+				var unknown ErrUnknownMarket
+				if !errors.As(err, &unknown) {
+					t.Errorf("resolve(%q) = %+v, %v, want ErrUnknownMarket", tt.symbol, market, err)
+				}
+				return
+			}
+			if err != nil || market.Native != tt.native {
+				t.Errorf("resolve(%q) = %q, %v, want %q", tt.symbol, market.Native, err, tt.native)
+			}
+		})
+	}
+}
+
+func TestMarketsReusedOnLoadFailure(t *testing.T) {
+	calls := 0
+	m := &marketCache{exchange: "test"}
+	load := func() ([]Market, error) {
+		calls++
+		if calls > 1 {
+			return nil, errors.New("unavailable")
+		}
+		return []Market{{Symbol: "BTC/USD", Native: "BTCUSD", Base: "BTC", Quote: "USD", Active: true}}, nil
+	}
+
+	if _, err := m.list(load); err != nil {
+		t.Fatal(err)
+	}
+	m.fetchedAt = time.Now().Add(-2 * marketsTTL)
+	markets, err := m.list(load)
+	if err != nil || len(markets) != 1 || calls != 2 {
+		t.Errorf("list after a failed reload = %v, %v after %d loads, want the previous markets", markets, err, calls)
+	}
+}
@@ -0,0 +1,187 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+)
+
+// krakenIntervals are the OHLC intervals supported by Kraken, in minutes.
+var krakenIntervals = map[time.Duration]int{
+	time.Minute:         1,
+	5 * time.Minute:     5,
+	15 * time.Minute:    15,
+	30 * time.Minute:    30,
+	time.Hour:           60,
+	4 * time.Hour:       240,
+	24 * time.Hour:      1440,
+	7 * 24 * time.Hour:  10080,
+	15 * 24 * time.Hour: 21600,
+}
+
+type Kraken struct {
+	client  *kraken.Client
+	markets *marketCache
+}
+
+func NewKraken(client *kraken.Client) *Kraken {
+	k := &Kraken{client: client}
+	k.markets = &marketCache{exchange: k.Name()}
+	return k
+}
+
+func (k *Kraken) Name() string {
+	return "kraken"
+}
+
+func (k *Kraken) WithContext(ctx context.Context) Exchange {
+	clone := *k
+	clone.client = k.client.WithContext(ctx)
+	return &clone
+}
+
+func (k *Kraken) loadMarkets() ([]Market, error) {
+	pairs, err := k.client.GetAssetPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(pairs))
+	for name, p := range pairs {
+		base, quote := assets.Normalize(p.Base), assets.Normalize(p.Quote)
+		markets = append(markets, Market{
+			Symbol:   base + "/" + quote,
+			Native:   name,
+			Base:     base,
+			Quote:    quote,
+			Active:   p.Status == "" || p.Status == "online",
+			AltNames: []string{p.Altname, p.WSName},
+		})
+	}
+	return markets, nil
+}
+
+func (k *Kraken) ListMarkets() ([]Market, error) {
+	return k.markets.list(k.loadMarkets)
+}
+
+// Ticker returns the ticker of the market. Kraken's ticker carries no time,
+// so it is stamped with the time it was fetched: its freshness is unknown,
+// and a market that stopped trading is never seen as stale.
+func (k *Kraken) Ticker(symbol string) (*Ticker, error) {
+	market, err := k.markets.resolve(symbol, k.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+	t, err := k.client.GetTicker(market.Native)
+	if err != nil {
+		return nil, err
+	}
+	return &Ticker{
+		Symbol:    market.Symbol,
+		Native:    market.Native,
+		Last:      t.Last,
+		Bid:       t.Bid,
+		Ask:       t.Ask,
+		Open:      t.Open,
+		High24h:   t.High24h,
+		Low24h:    t.Low24h,
+		Volume24h: t.Volume24h,
+		VWAP24h:   t.VWAP24h,
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
+
+func (k *Kraken) OHLC(symbol string, interval time.Duration, since time.Time) ([]candles.Candle, error) {
+	minutes, ok := krakenIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("kraken: intervalle %s non supporté", interval)
+	}
+	market, err := k.markets.resolve(symbol, k.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinceUnix int64
+	if !since.IsZero() {
+		sinceUnix = since.Unix()
+	}
+	rows, err := k.client.GetOHLC(market.Native, minutes, sinceUnix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]candles.Candle, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, candles.Candle{
+			Time:   r.Time.UTC(),
+			Open:   r.Open,
+			High:   r.High,
+			Low:    r.Low,
+			Close:  r.Close,
+			Volume: r.Volume,
+		})
+	}
+	return result, nil
+}
+
+func (k *Kraken) Depth(symbol string, count int) (*OrderBook, error) {
+	market, err := k.markets.resolve(symbol, k.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+	book, err := k.client.GetOrderBook(market.Native, count)
+	if err != nil {
+		return nil, err
+	}
+
+	convert := func(entries []kraken.OrderBookEntry) []BookLevel {
+		levels := make([]BookLevel, 0, len(entries))
+		for _, e := range entries {
+			levels = append(levels, BookLevel{Price: e.Price, Volume: e.Volume})
+		}
+		return levels
+	}
+	return &OrderBook{
+		Symbol:    market.Symbol,
+		Bids:      convert(book.Bids),
+		Asks:      convert(book.Asks),
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
+
+func (k *Kraken) Trades(symbol string, limit int) ([]Trade, error) {
+	market, err := k.markets.resolve(symbol, k.loadMarkets)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := k.client.GetRecentTrades(market.Native, "")
+	if err != nil {
+		return nil, err
+	}
+
+	raw := recent.Trades
+	if limit > 0 && len(raw) > limit {
+		raw = raw[len(raw)-limit:]
+	}
+	trades := make([]Trade, 0, len(raw))
+	for _, t := range raw {
+		side := "buy"
+		if t.Side == "s" {
+			side = "sell"
+		}
+		trades = append(trades, Trade{
+			ID:     strconv.FormatInt(t.TradeID, 10),
+			Price:  t.Price,
+			Volume: t.Volume,
+			Side:   side,
+			Time:   t.Time.UTC(),
+		})
+	}
+	return trades, nil
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/account"
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/portfolio"
+	"github.com/gin-gonic/gin"
+)
+
+type accountBalance struct {
+	Asset  string  `json:"asset"`
+	Code   string  `json:"code"`
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+	Value  float64 `json:"value"`
+	Priced bool    `json:"priced"`
+}
+
+type accountHistoryPoint struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Value     float64            `json:"value"`
+	Complete  bool               `json:"complete"`
+	Balances  map[string]float64 `json:"balances"`
+}
+
+// SetAccountSyncer enables the Kraken account endpoints that call the
+// private API.
+func (h *Handler) SetAccountSyncer(s *account.Syncer) {
+	h.account = s
+}
+
+func (h *Handler) SyncAccount(c *gin.Context) {
+	if h.account == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Clés API Kraken non configurées"})
+		return
+	}
+	result, err := h.account.Sync()
+	if errors.Is(err, account.ErrSyncRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAccountBalances returns the latest balance snapshot valued with the
+// latest stored prices.
+func (h *Handler) GetAccountBalances(c *gin.Context) {
+	quote := assets.Normalize(c.DefaultQuery("quote", "USD"))
+
+	balances, err := h.db.GetLatestAccountBalances()
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des soldes")
+		return
+	}
+	pricer, err := portfolio.NewPricer(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prix"})
+		return
+	}
+
+	result := make([]accountBalance, 0, len(balances))
+	total := 0.0
+	complete := true
+	for _, b := range balances {
+		item := accountBalance{Asset: b.Asset, Code: assets.Normalize(b.Asset), Amount: b.Amount}
+		if price, _, err := pricer.Price(item.Code, quote); err == nil {
+			item.Price = price
+			item.Value = b.Amount * price
+			item.Priced = true
+			total += item.Value
+		} else if b.Amount != 0 {
+			complete = false
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Value > result[j].Value
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"as_of":    balances[0].Timestamp,
+		"quote":    quote,
+		"balances": result,
+		"total":    total,
+		"complete": complete,
+	})
+}
+
+// GetAccountHistory returns the value of every balance snapshot of the
+// window, priced with the stored candle closes at the snapshot time.
+func (h *Handler) GetAccountHistory(c *gin.Context) {
+	quote := assets.Normalize(c.DefaultQuery("quote", "USD"))
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balances, err := h.db.GetAccountBalancesFromDB(from, to)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des soldes")
+		return
+	}
+	pricer, err := portfolio.NewPricer(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prix"})
+		return
+	}
+
+	points := make([]accountHistoryPoint, 0)
+	for _, b := range balances {
+		if len(points) == 0 || !points[len(points)-1].Timestamp.Equal(b.Timestamp) {
+			points = append(points, accountHistoryPoint{
+				Timestamp: b.Timestamp,
+				Complete:  true,
+				Balances:  make(map[string]float64),
+			})
+		}
+		point := &points[len(points)-1]
+		point.Balances[b.Asset] = b.Amount
+		if b.Amount == 0 {
+			continue
+		}
+		price, err := pricer.PriceAt(assets.Normalize(b.Asset), quote, b.Timestamp)
+		if err != nil {
+			point.Complete = false
+			continue
+		}
+		point.Value += b.Amount * price
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quote":  quote,
+		"from":   from,
+		"to":     to,
+		"points": points,
+		"count":  len(points),
+	})
+}
+
+func (h *Handler) GetAccountLedger(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := parsePositiveInt(c, "limit", 500)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.db.GetLedgerEntriesFromDB(c.Query("asset"), from, to, limit)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération du grand livre")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+func (h *Handler) GetAccountReconciliation(c *gin.Context) {
+	report, err := account.Reconcile(h.db)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors du rapprochement du compte")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/backtest"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/gin-gonic/gin"
+)
+
+type backtestRequest struct {
+	Pair        string             `json:"pair" binding:"required"`
+	Strategy    string             `json:"strategy" binding:"required"`
+	Params      map[string]float64 `json:"params"`
+	Interval    string             `json:"interval"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	InitialCash float64            `json:"initial_cash" binding:"gte=0"`
+	FeeBps      *float64           `json:"fee_bps" binding:"omitempty,gte=0"`
+	SlippageBps *float64           `json:"slippage_bps" binding:"omitempty,gte=0"`
+}
+
+const (
+	defaultBacktestCash     = 10000
+	defaultBacktestFeeBps   = 26
+	defaultBacktestSlipBps  = 5
+	defaultBacktestLookback = 90 * 24 * time.Hour
+)
+
+func (h *Handler) RunBacktest(c *gin.Context) {
+	var req backtestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run := backtest.Request{
+		Pair:     req.Pair,
+		Strategy: req.Strategy,
+		Params:   req.Params,
+		From:     req.From,
+		To:       req.To,
+		Config: backtest.Config{
+			InitialCash: req.InitialCash,
+			FeeBps:      defaultBacktestFeeBps,
+			SlippageBps: defaultBacktestSlipBps,
+		},
+	}
+
+	intervalStr := req.Interval
+	if intervalStr == "" {
+		intervalStr = "1h"
+	}
+	interval, err := candles.ParseDuration(intervalStr)
+	if err != nil || interval < candles.BaseInterval {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval invalide, minimum 5m"})
+		return
+	}
+	run.Interval = interval
+
+	if run.To.IsZero() {
+		run.To = time.Now()
+	}
+	if run.From.IsZero() {
+		run.From = run.To.Add(-defaultBacktestLookback)
+	}
+	if run.InitialCash == 0 {
+		run.InitialCash = defaultBacktestCash
+	}
+	if req.FeeBps != nil {
+		run.FeeBps = *req.FeeBps
+	}
+	if req.SlippageBps != nil {
+		run.SlippageBps = *req.SlippageBps
+	}
+
+	report, err := backtest.RunFromDB(h.db, run)
+	if errors.Is(err, backtest.ErrInvalidRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des bougies")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":         run.Pair,
+		"interval":     intervalStr,
+		"params":       run.Params,
+		"fee_bps":      run.FeeBps,
+		"slippage_bps": run.SlippageBps,
+		"report":       report,
+	})
+}
+
+func (h *Handler) ListBacktestStrategies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"strategies": backtest.Strategies()})
+}
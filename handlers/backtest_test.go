@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunBacktestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		closeDB  bool
+		status   int
+		contains string
+	}{
+		{"period too large", `{"pair":"XXBTZUSD","strategy":"sma_cross","params":{"slow":1e12}}`, false, http.StatusBadRequest, "slow doit être un entier"},
+		{"no candles", `{"pair":"XXBTZUSD","strategy":"sma_cross"}`, false, http.StatusBadRequest, "au moins deux bougies"},
+		{"database failure", `{"pair":"XXBTZUSD","strategy":"sma_cross"}`, true, http.StatusInternalServerError, "Erreur lors de la récupération des bougies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if tt.closeDB {
+				db.Close()
+			}
+			r := newTestEngine(NewHandler(db, newFakeKraken(t, nil)))
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/backtests", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("status %d: %s, want %d with %q", w.Code, w.Body, tt.status, tt.contains)
+			}
+		})
+	}
+}
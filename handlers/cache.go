@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+	"github.com/antonyloussararian/Go-CryptoPrice/priceindex"
+	"github.com/gin-gonic/gin"
+)
+
+// Endpoints whose Kraken responses are cached.
+const (
+	cacheStatus = "status"
+	cachePairs  = "pairs"
+	cachePair   = "pair"
+	// cacheIndex is the live price index, which calls every venue.
+	cacheIndex = "index"
+)
+
+const (
+	// maxStaleOnError is how long an expired response is still served when
+	// Kraken fails.
+	maxStaleOnError = 15 * time.Minute
+	// maxCacheEntries bounds the cache, whose keys include the pair sent by
+	// the client.
+	maxCacheEntries = 1024
+	// revalidateTimeout bounds the Kraken calls made in the background.
+	revalidateTimeout = 15 * time.Second
+)
+
+// X-Cache values.
+const (
+	cacheHit          = "HIT"
+	cacheMiss         = "MISS"
+	cacheStale        = "STALE"
+	cacheStaleOnError = "STALE-IF-ERROR"
+	cacheFallback     = "FALLBACK"
+)
+
+// defaultCacheTTLs are the TTLs of the cached endpoints: the status changes
+// rarely, pairs are ranked on 24 hours volumes and tickers move quickly.
+var defaultCacheTTLs = map[string]time.Duration{
+	cacheStatus: 10 * time.Second,
+	cachePairs:  time.Minute,
+	cachePair:   5 * time.Second,
+	cacheIndex:  10 * time.Second,
+}
+
+// cacheEntry is an encoded JSON response.
+type cacheEntry struct {
+	body      []byte
+	etag      string
+	fetchedAt time.Time
+}
+
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return now.Sub(e.fetchedAt)
+}
+
+// flight is a Kraken call shared by the concurrent requests of one key.
+type flight struct {
+	done  chan struct{}
+	entry *cacheEntry
+	err   error
+}
+
+// responseCache holds the responses of the endpoints that proxy Kraken. An
+// entry younger than its TTL is served as is; until twice its TTL it is
+// served while refreshed in the background, and up to maxStaleOnError
+// when the refresh fails. Concurrent misses of a key share one call.
+type responseCache struct {
+	mu      sync.Mutex
+	ttls    map[string]time.Duration
+	entries map[string]*cacheEntry
+	flights map[string]*flight
+}
+
+func newResponseCache() *responseCache {
+	ttls := make(map[string]time.Duration, len(defaultCacheTTLs))
+	for endpoint, ttl := range defaultCacheTTLs {
+		ttls[endpoint] = ttl
+	}
+	return &responseCache{
+		ttls:    ttls,
+		entries: make(map[string]*cacheEntry),
+		flights: make(map[string]*flight),
+	}
+}
+
+func (rc *responseCache) ttl(endpoint string) time.Duration {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.ttls[endpoint]
+}
+
+func (rc *responseCache) get(key string) *cacheEntry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.entries[key]
+}
+
+// fetch calls load once for all the concurrent callers of key and stores
+// its result.
+func (rc *responseCache) fetch(key string, load func() ([]byte, error)) (*cacheEntry, error) {
+	rc.mu.Lock()
+	if f, ok := rc.flights[key]; ok {
+		rc.mu.Unlock()
+		<-f.done
+		return f.entry, f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	rc.flights[key] = f
+	rc.mu.Unlock()
+
+	body, err := load()
+	if err == nil {
+		sum := sha256.Sum256(body)
+		f.entry = &cacheEntry{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`, fetchedAt: time.Now()}
+	}
+	f.err = err
+
+	rc.mu.Lock()
+	delete(rc.flights, key)
+	if err == nil {
+		if len(rc.entries) >= maxCacheEntries {
+			rc.evict(f.entry.fetchedAt)
+		}
+		rc.entries[key] = f.entry
+	}
+	rc.mu.Unlock()
+	close(f.done)
+	return f.entry, f.err
+}
+
+// evict drops the entries too old to be served, and the oldest one if the
+// cache is still full.
+func (rc *responseCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest *cacheEntry
+	for key, e := range rc.entries {
+		if e.age(now) > maxStaleOnError {
+			delete(rc.entries, key)
+			continue
+		}
+		if oldest == nil || e.fetchedAt.Before(oldest.fetchedAt) {
+			oldestKey, oldest = key, e
+		}
+	}
+	if len(rc.entries) >= maxCacheEntries {
+		delete(rc.entries, oldestKey)
+	}
+}
+
+// SetCacheTTL sets the TTL of a cached endpoint: status, pairs, pair or
+// index. A zero TTL disables its cache.
+func (h *Handler) SetCacheTTL(endpoint string, ttl time.Duration) error {
+	if _, ok := defaultCacheTTLs[endpoint]; !ok {
+		return fmt.Errorf("endpoint de cache inconnu %q (status, pairs, pair ou index)", endpoint)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("TTL négatif pour %s", endpoint)
+	}
+	h.responses.mu.Lock()
+	defer h.responses.mu.Unlock()
+	h.responses.ttls[endpoint] = ttl
+	return nil
+}
+
+// respondCached serves the JSON response built by load for key, from the
+// cache when possible. load is given a handler scoped to the request, or to
+// a detached context when it refreshes the entry in the background. Errors
+// are never cached: they are answered with the stored data returned by
+// fallback, if not nil, or with a 500.
+func (h *Handler) respondCached(c *gin.Context, endpoint, key string, load, fallback func(*Handler) (any, error)) {
+	key = endpoint + "|" + key
+	ttl := h.responses.ttl(endpoint)
+	loadJSON := func(scoped *Handler) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			value, err := load(scoped)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(value)
+		}
+	}
+
+	if ttl == 0 {
+		body, err := loadJSON(h.withContext(c.Request.Context()))()
+		if err != nil {
+			h.respondFallback(c, endpoint, err, fallback)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
+	now := time.Now()
+	entry := h.responses.get(key)
+	switch {
+	case entry != nil && entry.age(now) < ttl:
+		h.writeCached(c, endpoint, entry, cacheHit, ttl)
+		return
+	case entry != nil && entry.age(now) < 2*ttl:
+		// The caller's cancellation must not abort the shared refresh.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), revalidateTimeout)
+		go func() {
+			defer cancel()
+			if _, err := h.responses.fetch(key, loadJSON(h.withContext(ctx))); err != nil {
+				slog.WarnContext(ctx, "échec du rafraîchissement du cache", "key", key, logging.Err(err))
+			}
+		}()
+		h.writeCached(c, endpoint, entry, cacheStale, ttl)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), revalidateTimeout)
+	defer cancel()
+	fresh, err := h.responses.fetch(key, loadJSON(h.withContext(ctx)))
+	if err != nil {
+		if entry != nil && entry.age(now) < maxStaleOnError {
+			slog.WarnContext(c.Request.Context(), "Kraken en échec, réponse en cache servie", "key", key, logging.Err(err))
+			h.writeCached(c, endpoint, entry, cacheStaleOnError, ttl)
+			return
+		}
+		h.respondFallback(c, endpoint, err, fallback)
+		return
+	}
+	h.writeCached(c, endpoint, fresh, cacheMiss, ttl)
+}
+
+// respondFallback answers a failed Kraken call with the stored data, marked
+// as stale, or with an error when there is none: a 404 when no venue lists
+// the asset of a live index, a 500 otherwise.
+func (h *Handler) respondFallback(c *gin.Context, endpoint string, upstreamErr error, fallback func(*Handler) (any, error)) {
+	if fallback != nil {
+		value, err := fallback(h.withContext(c.Request.Context()))
+		if err == nil {
+			slog.WarnContext(c.Request.Context(), "Kraken en échec, données enregistrées servies", "endpoint", endpoint, logging.Err(upstreamErr))
+			metrics.CacheRequests.WithLabelValues(endpoint, strings.ToLower(cacheFallback)).Inc()
+			c.Header("X-Cache", cacheFallback)
+			c.JSON(http.StatusOK, value)
+			return
+		}
+		if !errors.Is(err, database.ErrNotFound) {
+			slog.ErrorContext(c.Request.Context(), "échec de la lecture des données de repli", "endpoint", endpoint, logging.Err(err))
+		}
+	}
+	status := http.StatusInternalServerError
+	if errors.Is(upstreamErr, priceindex.ErrNoComponents) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{"error": upstreamErr.Error()})
+}
+
+// writeCached answers with the entry, or with a 304 when the client already
+// has it.
+func (h *Handler) writeCached(c *gin.Context, endpoint string, entry *cacheEntry, status string, ttl time.Duration) {
+	metrics.CacheRequests.WithLabelValues(endpoint, strings.ToLower(status)).Inc()
+
+	age := entry.age(time.Now())
+	maxAge := max(0, int((ttl - age).Seconds()))
+	c.Header("X-Cache", status)
+	c.Header("Age", strconv.Itoa(int(age.Seconds())))
+	c.Header("ETag", entry.etag)
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+
+	if etagMatches(c.GetHeader("If-None-Match"), entry.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", entry.body)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
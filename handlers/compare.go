@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSpreadAlertBps is the cross-venue price gap, in basis points of the
+// lowest price, above which an alert is recorded.
+const defaultSpreadAlertBps = 100
+
+type venueQuote struct {
+	Source    string    `json:"source"`
+	Native    string    `json:"native"`
+	Price     float64   `json:"price"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	Mid       float64   `json:"mid"`
+	SpreadBps float64   `json:"spread_bps"`
+	Volume24h float64   `json:"volume_24h"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func newVenueQuote(source, native string, info models.PairInfo) venueQuote {
+	q := venueQuote{
+		Source:    source,
+		Native:    native,
+		Price:     info.Price,
+		Bid:       info.Bid,
+		Ask:       info.Ask,
+		Volume24h: info.Volume24h,
+		Timestamp: info.Timestamp,
+	}
+	if q.Bid > 0 && q.Ask > 0 {
+		q.Mid = (q.Bid + q.Ask) / 2
+		q.SpreadBps = (q.Ask - q.Bid) / q.Mid * 10000
+	}
+	return q
+}
+
+// crossVenue summarizes the gap between venues: the spread between the
+// highest and lowest last prices, and the arbitrage between the best bid of
+// one venue and the best ask of another.
+type crossVenue struct {
+	HighSource   string  `json:"high_source"`
+	HighPrice    float64 `json:"high_price"`
+	LowSource    string  `json:"low_source"`
+	LowPrice     float64 `json:"low_price"`
+	SpreadBps    float64 `json:"spread_bps"`
+	BuyOn        string  `json:"buy_on,omitempty"`
+	SellOn       string  `json:"sell_on,omitempty"`
+	ArbitrageBps float64 `json:"arbitrage_bps"`
+}
+
+// compareQuotes returns nil when fewer than two venues have a price.
+func compareQuotes(quotes []venueQuote) *crossVenue {
+	var result crossVenue
+	priced := 0
+	bestBid, bestAsk := 0.0, math.Inf(1)
+	for _, q := range quotes {
+		if q.Price <= 0 {
+			continue
+		}
+		priced++
+		if result.HighSource == "" || q.Price > result.HighPrice {
+			result.HighSource, result.HighPrice = q.Source, q.Price
+		}
+		if result.LowSource == "" || q.Price < result.LowPrice {
+			result.LowSource, result.LowPrice = q.Source, q.Price
+		}
+		if q.Bid > bestBid {
+			bestBid, result.SellOn = q.Bid, q.Source
+		}
+		if q.Ask > 0 && q.Ask < bestAsk {
+			bestAsk, result.BuyOn = q.Ask, q.Source
+		}
+	}
+	if priced < 2 {
+		return nil
+	}
+
+	result.SpreadBps = (result.HighPrice - result.LowPrice) / result.LowPrice * 10000
+	if result.BuyOn != "" && result.SellOn != "" && result.BuyOn != result.SellOn && bestBid > bestAsk {
+		result.ArbitrageBps = (bestBid - bestAsk) / bestAsk * 10000
+	} else {
+		result.BuyOn, result.SellOn = "", ""
+	}
+	return &result
+}
+
+// SetSpreadAlertThreshold sets the cross-venue spread, in basis points, that
+// triggers an alert during collection.
+func (h *Handler) SetSpreadAlertThreshold(bps float64) {
+	h.spreadAlertBps = bps
+}
+
+func (h *Handler) checkSpreadAlert(symbol string, quotes []venueQuote, timestamp time.Time) {
+	cross := compareQuotes(quotes)
+	if cross == nil || cross.SpreadBps < h.spreadAlertBps {
+		return
+	}
+
+	alert := &models.SpreadAlert{
+		Symbol:     symbol,
+		Timestamp:  timestamp,
+		HighSource: cross.HighSource,
+		HighPrice:  cross.HighPrice,
+		LowSource:  cross.LowSource,
+		LowPrice:   cross.LowPrice,
+		SpreadBps:  cross.SpreadBps,
+	}
+	if err := h.db.SaveSpreadAlert(alert); err != nil {
+		slog.ErrorContext(h.ctx, "échec de l'enregistrement de l'alerte d'écart", "symbol", symbol, logging.Err(err))
+		return
+	}
+	slog.WarnContext(h.ctx, "alerte d'écart entre exchanges", "symbol", symbol, "spread_bps", cross.SpreadBps,
+		"high_source", cross.HighSource, "high_price", cross.HighPrice,
+		"low_source", cross.LowSource, "low_price", cross.LowPrice)
+}
+
+// compareSymbol reads the canonical symbol from the :asset parameter and
+// the optional quote (default USD). When no quote is given, a pair name is
+// accepted if it has a separator ("BTC-USD") or is the name of a market of
+// an enabled venue ("XXBTZUSD"), so that assets such as STETH or PYUSD are
+// not mistaken for pairs.
+func (h *Handler) compareSymbol(c *gin.Context) string {
+	asset := strings.TrimSpace(c.Param("asset"))
+	quote := c.Query("quote")
+	if quote == "" {
+		if assets.HasSeparator(asset) {
+			if symbol, ok := assets.NormalizePair(asset); ok {
+				return symbol
+			}
+		} else if symbol, ok := h.marketSymbol(asset); ok {
+			return symbol
+		}
+		quote = "USD"
+	}
+	return assets.Normalize(asset) + "/" + assets.Normalize(quote)
+}
+
+// marketSymbol returns the canonical symbol of the market whose native or
+// alternative name is name on one of the enabled venues.
+func (h *Handler) marketSymbol(name string) (string, bool) {
+	for _, venue := range h.exchangeNames() {
+		markets, err := h.exchanges[venue].ListMarkets()
+		if err != nil {
+			continue
+		}
+		for _, market := range markets {
+			if strings.EqualFold(market.Native, name) {
+				return market.Symbol, true
+			}
+			for _, alt := range market.AltNames {
+				if strings.EqualFold(alt, name) {
+					return market.Symbol, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func (h *Handler) spreadThreshold(c *gin.Context) (float64, error) {
+	s := c.Query("threshold_bps")
+	if s == "" {
+		return h.spreadAlertBps, nil
+	}
+	bps, err := strconv.ParseFloat(s, 64)
+	if err != nil || bps < 0 {
+		return 0, fmt.Errorf("threshold_bps invalide")
+	}
+	return bps, nil
+}
+
+// ComparePrices fetches the live ticker of the asset on every enabled venue
+// and compares them.
+func (h *Handler) ComparePrices(c *gin.Context) {
+	symbol := h.compareSymbol(c)
+	threshold, err := h.spreadThreshold(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	names := h.exchangeNames()
+	quotes := make([]*venueQuote, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			ticker, err := h.exchanges[name].Ticker(symbol)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			q := newVenueQuote(name, ticker.Native, models.PairInfo{
+				Price:     ticker.Last,
+				Bid:       ticker.Bid,
+				Ask:       ticker.Ask,
+				Volume24h: ticker.Volume24h,
+				Timestamp: ticker.Timestamp,
+			})
+			quotes[i] = &q
+		}(i, name)
+	}
+	wg.Wait()
+
+	venues := make([]venueQuote, 0, len(names))
+	unavailable := make(map[string]string)
+	for i, q := range quotes {
+		if q == nil {
+			unavailable[names[i]] = errs[i].Error()
+			continue
+		}
+		venues = append(venues, *q)
+	}
+	if len(venues) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("aucun marché %s disponible", symbol), "unavailable": unavailable})
+		return
+	}
+
+	cross := compareQuotes(venues)
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":        symbol,
+		"venues":        venues,
+		"unavailable":   unavailable,
+		"cross_venue":   cross,
+		"threshold_bps": threshold,
+		"alert":         cross != nil && cross.SpreadBps >= threshold,
+	})
+}
+
+type comparePoint struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Prices    map[string]float64 `json:"prices"`
+	quotes    []venueQuote
+	*crossVenue
+}
+
+type spreadEpisode struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Points       int       `json:"points"`
+	MaxSpreadBps float64   `json:"max_spread_bps"`
+	HighSource   string    `json:"high_source"`
+	LowSource    string    `json:"low_source"`
+}
+
+// GetCompareHistory rebuilds the cross-venue spread series of the asset
+// from the tickers stored on each collection cycle, and groups consecutive
+// points above the threshold into alert episodes.
+func (h *Handler) GetCompareHistory(c *gin.Context) {
+	symbol := h.compareSymbol(c)
+	threshold, err := h.spreadThreshold(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pairs, err := h.db.GetDistinctPairsFromDB()
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des paires")
+		return
+	}
+
+	byTime := make(map[time.Time]*comparePoint)
+	sources := make(map[string]string)
+	for _, pair := range pairs {
+		if pairSymbol, ok := assets.NormalizePair(pair.Base + "/" + pair.Quote); !ok || pairSymbol != symbol {
+			continue
+		}
+		infos, err := h.db.GetSourcePairInfoFromDB(pair.Source, pair.Name, from, to)
+		if err != nil {
+			respondDBError(c, err, "Erreur lors de la récupération des prix")
+			return
+		}
+		sources[pair.Source] = pair.Name
+		for _, info := range infos {
+			key := info.Timestamp.UTC()
+			point, ok := byTime[key]
+			if !ok {
+				point = &comparePoint{Timestamp: key, Prices: make(map[string]float64)}
+				byTime[key] = point
+			}
+			point.Prices[pair.Source] = info.Price
+			point.quotes = append(point.quotes, newVenueQuote(pair.Source, pair.Name, info))
+		}
+	}
+
+	points := make([]*comparePoint, 0, len(byTime))
+	for _, point := range byTime {
+		point.crossVenue = compareQuotes(point.quotes)
+		if point.crossVenue != nil {
+			points = append(points, point)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+
+	var episodes []spreadEpisode
+	var current *spreadEpisode
+	sum, maxSpread := 0.0, 0.0
+	for _, p := range points {
+		sum += p.SpreadBps
+		maxSpread = math.Max(maxSpread, p.SpreadBps)
+		if p.SpreadBps < threshold {
+			current = nil
+			continue
+		}
+		if current == nil {
+			episodes = append(episodes, spreadEpisode{Start: p.Timestamp})
+			current = &episodes[len(episodes)-1]
+		}
+		current.End = p.Timestamp
+		current.Points++
+		if p.SpreadBps > current.MaxSpreadBps {
+			current.MaxSpreadBps = p.SpreadBps
+			current.HighSource, current.LowSource = p.HighSource, p.LowSource
+		}
+	}
+
+	avg := 0.0
+	if len(points) > 0 {
+		avg = sum / float64(len(points))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":         symbol,
+		"sources":        sources,
+		"from":           from,
+		"to":             to,
+		"points":         points,
+		"count":          len(points),
+		"avg_spread_bps": avg,
+		"max_spread_bps": maxSpread,
+		"threshold_bps":  threshold,
+		"alerts":         episodes,
+	})
+}
+
+// GetSpreadAlerts returns the alerts recorded during collection.
+func (h *Handler) GetSpreadAlerts(c *gin.Context) {
+	symbol := h.compareSymbol(c)
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alerts, err := h.db.GetSpreadAlertsFromDB(symbol, from, to)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des alertes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"symbol": symbol,
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
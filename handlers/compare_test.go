@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
+	"github.com/gin-gonic/gin"
+)
+
+// marketsOnly is a venue that only lists markets.
+type marketsOnly struct {
+	name    string
+	markets []exchange.Market
+}
+
+func (m marketsOnly) Name() string                                   { return m.name }
+func (m marketsOnly) WithContext(context.Context) exchange.Exchange  { return m }
+func (m marketsOnly) ListMarkets() ([]exchange.Market, error)        { return m.markets, nil }
+func (m marketsOnly) Ticker(string) (*exchange.Ticker, error)        { return nil, nil }
+func (m marketsOnly) Depth(string, int) (*exchange.OrderBook, error) { return nil, nil }
+func (m marketsOnly) Trades(string, int) ([]exchange.Trade, error)   { return nil, nil }
+func (m marketsOnly) OHLC(string, time.Duration, time.Time) ([]candles.Candle, error) {
+	return nil, nil
+}
+
+func TestCompareSymbol(t *testing.T) {
+	h := &Handler{exchanges: map[string]exchange.Exchange{
+		"kraken": marketsOnly{name: "kraken", markets: []exchange.Market{
+			{Symbol: "BTC/USD", Native: "XXBTZUSD", Base: "BTC", Quote: "USD", AltNames: []string{"XBTUSD", "XBT/USD"}},
+			{Symbol: "XTZ/USD", Native: "XTZUSD", Base: "XTZ", Quote: "USD", AltNames: []string{"XTZUSD", "XTZ/USD"}},
+			{Symbol: "STETH/ETH", Native: "STETHETH", Base: "STETH", Quote: "ETH", AltNames: []string{"STETHETH", "STETH/ETH"}},
+		}},
+		"binance": marketsOnly{name: "binance", markets: []exchange.Market{
+			{Symbol: "FDUSD/USDT", Native: "FDUSDUSDT", Base: "FDUSD", Quote: "USDT"},
+		}},
+	}}
+
+	tests := []struct {
+		asset string
+		query string
+		want  string
+	}{
+		{"XXBTZUSD", "", "BTC/USD"},
+		{"xbtusd", "", "BTC/USD"},
+		{"XTZUSD", "", "XTZ/USD"},
+		{"STETHETH", "", "STETH/ETH"},
+		{"FDUSDUSDT", "", "FDUSD/USDT"},
+		{"BTC-EUR", "", "BTC/EUR"},
+		{"XBT_USDT", "", "BTC/USDT"},
+		// Assets that look like pairs but are no venue's market.
+		{"STETH", "", "STETH/USD"},
+		{"PYUSD", "", "PYUSD/USD"},
+		{"FDUSD", "", "FDUSD/USD"},
+		{"XTZ", "", "XTZ/USD"},
+		{"XBT", "", "BTC/USD"},
+		{"BTC", "?quote=ZEUR", "BTC/EUR"},
+		{"ETH", "?quote=xbt", "ETH/BTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.asset+tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/compare/"+tt.asset+tt.query, nil)
+			c.Params = gin.Params{{Key: "asset", Value: tt.asset}}
+			if got := h.compareSymbol(c); got != tt.want {
+				t.Errorf("compareSymbol(%s%s) = %s, want %s", tt.asset, tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/account"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/antonyloussararian/Go-CryptoPrice/openapi"
+)
+
+// contractRequest is a call of a documented route and the status it must
+// get.
+type contractRequest struct {
+	route  string // method and Gin route, as in the OpenAPI catalog
+	path   string
+	body   string
+	status int
+	cache  string // expected X-Cache, if any
+}
+
+// TestContract calls every documented route against seeded data and a fake
+// Kraken, and checks each response against the schema of the OpenAPI
+// document, so that the document and the client types cannot drift from
+// what the handlers serve.
+func TestContract(t *testing.T) {
+	now := time.Now().UTC().Truncate(5 * time.Minute)
+	db := newTestDB(t)
+	seedContractData(t, db, now)
+	withCSVDir(t, now)
+
+	responses := contractKrakenResponses(now)
+	baseURL := newFakeKrakenServer(t, responses)
+	krakenClient := kraken.NewClient()
+	krakenClient.SetBaseURL(baseURL)
+	private, err := kraken.NewPrivateClient(kraken.Credentials{Key: "key", Secret: "c2VjcmV0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	private.SetBaseURL(baseURL)
+
+	h := NewHandler(db, krakenClient)
+	h.SetAccountSyncer(account.NewSyncer(db, private))
+	h.health.recordSuccess(time.Now())
+	r := newTestEngine(h)
+	if err := openapi.Register(r, nil); err != nil {
+		t.Fatal(err)
+	}
+	doc := openapi.Build(r.Routes(), nil)
+
+	day := now.Format("2006-01-02")
+	requests := []contractRequest{
+		{route: "GET /healthz", path: "/healthz", status: http.StatusOK},
+		{route: "GET /readyz", path: "/readyz", status: http.StatusOK},
+		{route: "GET /openapi.json", path: "/openapi.json", status: http.StatusOK},
+		{route: "GET /docs", path: "/docs", status: http.StatusOK},
+
+		{route: "GET /api/status", path: "/api/status", status: http.StatusOK},
+		{route: "GET /api/status/history", path: "/api/status/history", status: http.StatusOK},
+		{route: "GET /api/pairs", path: "/api/pairs", status: http.StatusOK, cache: cacheMiss},
+		{route: "GET /api/pairs/:pair", path: "/api/pairs/XXBTZUSD", status: http.StatusOK, cache: cacheMiss},
+		{route: "GET /api/pairs/:pair/depth", path: "/api/pairs/XXBTZUSD/depth", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/depth/history", path: "/api/pairs/XXBTZUSD/depth/history", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/trades", path: "/api/pairs/XXBTZUSD/trades", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/trades/volume", path: "/api/pairs/XXBTZUSD/trades/volume", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/trades/large", path: "/api/pairs/XXBTZUSD/trades/large", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/vwap", path: "/api/pairs/XXBTZUSD/vwap", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/spread", path: "/api/pairs/XXBTZUSD/spread", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/indicators", path: "/api/pairs/XXBTZUSD/indicators?names=sma:20,rsi:14,macd,bbands,atr", status: http.StatusOK},
+		{route: "GET /api/pairs/:pair/stats", path: "/api/pairs/XXBTZUSD/stats", status: http.StatusOK},
+		{route: "GET /api/stats", path: "/api/stats?pairs=XXBTZUSD,XETHZUSD", status: http.StatusOK},
+		{route: "GET /api/correlation", path: "/api/correlation?pairs=XXBTZUSD,XETHZUSD", status: http.StatusOK},
+
+		{route: "POST /api/portfolios", path: "/api/portfolios", body: `{"name":"Long terme","base_currency":"USD"}`, status: http.StatusCreated},
+		{route: "GET /api/portfolios", path: "/api/portfolios", status: http.StatusOK},
+		{route: "PUT /api/portfolios/:id", path: "/api/portfolios/1", body: `{"name":"Retraite","base_currency":"USD"}`, status: http.StatusOK},
+		{route: "POST /api/portfolios/:id/holdings", path: "/api/portfolios/1/holdings", body: `{"asset":"BTC","quantity":0.5,"cost_basis":30000}`, status: http.StatusCreated},
+		{route: "PUT /api/portfolios/:id/holdings/:holding_id", path: "/api/portfolios/1/holdings/1", body: `{"quantity":0.75,"cost_basis":45000}`, status: http.StatusOK},
+		{route: "GET /api/portfolios/:id", path: "/api/portfolios/1", status: http.StatusOK},
+		{route: "GET /api/portfolios/:id/valuation", path: "/api/portfolios/1/valuation", status: http.StatusOK},
+		{route: "POST /api/portfolios/:id/transactions", path: "/api/portfolios/1/transactions",
+			body: `{"time":"` + now.Add(-48*time.Hour).Format(time.RFC3339) + `","type":"buy","asset":"BTC","quantity":0.5,"price":60000}`, status: http.StatusCreated},
+		{route: "POST /api/portfolios/:id/transactions/import", path: "/api/portfolios/1/transactions/import",
+			body: "time,type,asset,quantity,price\n" + now.Add(-24*time.Hour).Format(time.RFC3339) + ",sell,BTC,0.25,65000\n", status: http.StatusOK},
+		{route: "GET /api/portfolios/:id/transactions", path: "/api/portfolios/1/transactions", status: http.StatusOK},
+		{route: "GET /api/portfolios/:id/lots", path: "/api/portfolios/1/lots", status: http.StatusOK},
+		{route: "DELETE /api/portfolios/:id/transactions/:tx_id", path: "/api/portfolios/1/transactions/1", status: http.StatusNoContent},
+		{route: "DELETE /api/portfolios/:id/holdings/:holding_id", path: "/api/portfolios/1/holdings/1", status: http.StatusNoContent},
+		{route: "DELETE /api/portfolios/:id", path: "/api/portfolios/1", status: http.StatusNoContent},
+
+		{route: "GET /api/backtests/strategies", path: "/api/backtests/strategies", status: http.StatusOK},
+		{route: "POST /api/backtests", path: "/api/backtests", body: `{"pair":"XXBTZUSD","strategy":"sma_cross","params":{"fast":5,"slow":20}}`, status: http.StatusOK},
+
+		{route: "POST /api/paper/accounts", path: "/api/paper/accounts", body: `{"name":"Essai","initial_balance":10000}`, status: http.StatusCreated},
+		{route: "GET /api/paper/accounts", path: "/api/paper/accounts", status: http.StatusOK},
+		{route: "POST /api/paper/orders", path: "/api/paper/orders",
+			body: `{"account_id":1,"pair":"XXBTZUSD","side":"buy","type":"limit","quantity":0.01,"limit_price":50000}`, status: http.StatusCreated},
+		{route: "GET /api/paper/orders", path: "/api/paper/orders?account_id=1", status: http.StatusOK},
+		{route: "DELETE /api/paper/orders/:id", path: "/api/paper/orders/1", status: http.StatusOK},
+		{route: "GET /api/paper/accounts/:id", path: "/api/paper/accounts/1", status: http.StatusOK},
+		{route: "GET /api/paper/accounts/:id/fills", path: "/api/paper/accounts/1/fills", status: http.StatusOK},
+
+		{route: "POST /api/account/sync", path: "/api/account/sync", status: http.StatusOK},
+		{route: "GET /api/account/balances", path: "/api/account/balances", status: http.StatusOK},
+		{route: "GET /api/account/history", path: "/api/account/history", status: http.StatusOK},
+		{route: "GET /api/account/ledger", path: "/api/account/ledger", status: http.StatusOK},
+		{route: "GET /api/account/reconciliation", path: "/api/account/reconciliation", status: http.StatusOK},
+
+		{route: "GET /api/exchanges", path: "/api/exchanges", status: http.StatusOK},
+		{route: "GET /api/exchanges/:exchange/markets", path: "/api/exchanges/kraken/markets", status: http.StatusOK},
+		{route: "GET /api/exchanges/:exchange/ticker", path: "/api/exchanges/kraken/ticker?symbol=BTC/USD", status: http.StatusOK},
+		{route: "GET /api/exchanges/:exchange/ohlc", path: "/api/exchanges/kraken/ohlc?symbol=BTC/USD", status: http.StatusOK},
+		{route: "GET /api/exchanges/:exchange/depth", path: "/api/exchanges/kraken/depth?symbol=BTC/USD", status: http.StatusOK},
+		{route: "GET /api/exchanges/:exchange/trades", path: "/api/exchanges/kraken/trades?symbol=BTC/USD", status: http.StatusOK},
+		{route: "GET /api/compare/:asset", path: "/api/compare/BTC", status: http.StatusOK},
+		{route: "GET /api/compare/:asset/history", path: "/api/compare/BTC/history", status: http.StatusOK},
+		{route: "GET /api/compare/:asset/alerts", path: "/api/compare/BTC/alerts", status: http.StatusOK},
+		{route: "GET /api/index/:asset", path: "/api/index/BTC", status: http.StatusOK},
+		{route: "GET /api/index/:asset", path: "/api/index/BTC?live=true", status: http.StatusOK, cache: cacheMiss},
+
+		{route: "GET /api/historical", path: "/api/historical?date=" + day, status: http.StatusOK},
+		{route: "GET /api/db", path: "/api/db", status: http.StatusOK},
+	}
+
+	called := make(map[string]bool)
+	for _, req := range requests {
+		checkContract(t, r, doc, req)
+		called[req.route] = true
+	}
+
+	// Kraken fails: the responses built from the stored data must have the
+	// documented shape as well.
+	h.client = newFakeKraken(t, map[string]string{
+		"AssetPairs": outageBody,
+		"Ticker":     outageBody,
+		"Depth":      outageBody,
+	})
+	h.exchanges = NewHandler(db, h.client).exchanges
+	h.responses = newResponseCache()
+	for _, req := range []contractRequest{
+		{route: "GET /api/pairs", path: "/api/pairs", status: http.StatusOK, cache: cacheFallback},
+		{route: "GET /api/pairs/:pair", path: "/api/pairs/XXBTZUSD", status: http.StatusOK, cache: cacheFallback},
+		{route: "GET /api/index/:asset", path: "/api/index/BTC?live=true", status: http.StatusOK, cache: cacheFallback},
+	} {
+		checkContract(t, r, doc, req)
+	}
+
+	var missing []string
+	for path, methods := range doc.Paths {
+		for method := range methods {
+			route := strings.ToUpper(method) + " " + ginRoute(path)
+			if !called[route] {
+				missing = append(missing, route)
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("documented routes without a contract test: %v", missing)
+	}
+}
+
+// checkContract makes the request and checks its response against the
+// document.
+func checkContract(t *testing.T, r http.Handler, doc *openapi.Document, req contractRequest) {
+	t.Helper()
+	method, route, _ := strings.Cut(req.route, " ")
+	name := method + " " + req.path
+
+	httpReq := httptest.NewRequest(method, req.path, strings.NewReader(req.body))
+	switch {
+	case strings.HasSuffix(req.path, "/import"):
+		httpReq.Header.Set("Content-Type", "text/csv")
+	case req.body != "":
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httpReq)
+	if w.Code != req.status {
+		t.Errorf("%s: status %d, want %d: %s", name, w.Code, req.status, w.Body)
+		return
+	}
+	if req.cache != "" {
+		if cache := w.Header().Get("X-Cache"); cache != req.cache {
+			t.Errorf("%s: X-Cache %s, want %s", name, cache, req.cache)
+		}
+	}
+
+	op := doc.Paths[openAPIPath(route)][strings.ToLower(method)]
+	if op == nil {
+		t.Errorf("%s: %s is not documented", name, req.route)
+		return
+	}
+	response := op.Responses[strconv.Itoa(w.Code)]
+	if response == nil {
+		t.Errorf("%s: status %d is not documented", name, w.Code)
+		return
+	}
+	if len(response.Content) == 0 {
+		if w.Body.Len() > 0 {
+			t.Errorf("%s: undocumented body %s", name, w.Body)
+		}
+		return
+	}
+
+	contentType, _, _ := strings.Cut(w.Header().Get("Content-Type"), ";")
+	media := response.Content[contentType]
+	if media == nil {
+		t.Errorf("%s: content type %s, documented %v", name, contentType, mapKeys(response.Content))
+		return
+	}
+	if contentType != "application/json" {
+		return
+	}
+	var body any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	for _, problem := range conform(doc, media.Schema, body, "body") {
+		t.Errorf("%s: %s", name, problem)
+	}
+}
+
+// conform lists where value, decoded from JSON, does not match schema.
+// It covers the subset of JSON Schema built by the openapi package.
+func conform(doc *openapi.Document, schema *openapi.Schema, value any, at string) []string {
+	if schema.Ref != "" {
+		component := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if component == nil {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, schema.Ref)}
+		}
+		return conform(doc, component, value, at)
+	}
+	if len(schema.AnyOf) > 0 {
+		for _, s := range schema.AnyOf {
+			if len(conform(doc, s, value, at)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %s matches none of the schemas", at, excerpt(value))}
+	}
+
+	var types []string
+	switch typ := schema.Type.(type) {
+	case string:
+		types = []string{typ}
+	case []string:
+		types = typ
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	if !slices.Contains(types, jsonType(value)) &&
+		!(jsonType(value) == "integer" && slices.Contains(types, "number")) {
+		return []string{fmt.Sprintf("%s: %s is not of type %v", at, excerpt(value), types)}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", at, name))
+			}
+		}
+		for name, field := range v {
+			if s, ok := schema.Properties[name]; ok {
+				problems = append(problems, conform(doc, s, field, at+"."+name)...)
+			} else if schema.AdditionalProperties != nil {
+				problems = append(problems, conform(doc, schema.AdditionalProperties, field, at+"."+name)...)
+			}
+		}
+	case []any:
+		if schema.Items != nil {
+			for i, item := range v {
+				problems = append(problems, conform(doc, schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case string:
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a date-time", at, v))
+			}
+		}
+	}
+	return problems
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func excerpt(value any) string {
+	b, _ := json.Marshal(value)
+	if len(b) > 80 {
+		return string(b[:80]) + "..."
+	}
+	return string(b)
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// openAPIPath turns "/api/pairs/:pair" into "/api/pairs/{pair}", and
+// ginRoute does the reverse.
+func openAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func ginRoute(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") {
+			segments[i] = ":" + strings.Trim(s, "{}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// seedContractData stores two Kraken pairs, BTC/USD on Coinbase, and what
+// the collector would have stored for them over the last three days.
+func seedContractData(t *testing.T, db *database.DB, now time.Time) {
+	t.Helper()
+	pairs := []struct {
+		name, base, quote, source string
+		price                     float64
+	}{
+		{"XXBTZUSD", "XXBT", "ZUSD", database.SourceKraken, 67000},
+		{"XETHZUSD", "XETH", "ZUSD", database.SourceKraken, 3500},
+		{"BTC-USD", "BTC", "USD", "coinbase", 67040},
+	}
+	for i, p := range pairs {
+		pair := &models.TradingPair{Name: p.name, Base: p.base, Quote: p.quote, Source: p.source, LastUpdated: now}
+		if err := db.SaveTradingPair(pair); err != nil {
+			t.Fatal(err)
+		}
+
+		var candles []models.HistoricalData
+		for ts := now.Add(-72 * time.Hour); !ts.After(now); ts = ts.Add(5 * time.Minute) {
+			// A slow wave, shifted per pair, so that returns vary.
+			price := p.price * (1 + 0.02*math.Sin(float64(ts.Unix())/7200+float64(i)))
+			candles = append(candles, models.HistoricalData{
+				PairID: pair.ID, Timestamp: ts,
+				Open: price, High: price * 1.001, Low: price * 0.999, Close: price * 1.0002, Volume: 12.5,
+			})
+			if ts.Minute()%30 == 0 {
+				info := &models.PairInfo{
+					PairID: pair.ID, Price: price, Volume24h: 1500 - float64(i), High24h: price * 1.01, Low24h: price * 0.99,
+					Bid: price - 0.5, Ask: price + 0.5, LastTradeVolume: 0.1, VWAP24h: price, TradeCount24h: 20000, Open: price,
+					Timestamp: ts,
+				}
+				if err := db.SavePairInfo(info); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := db.SaveHistoricalDataBatch(candles); err != nil {
+			t.Fatal(err)
+		}
+
+		if p.source != database.SourceKraken {
+			continue
+		}
+		snapshot := &models.OrderBookSnapshot{
+			PairID: pair.ID, Timestamp: now,
+			DepthMetrics: models.DepthMetrics{BestBid: p.price - 0.5, BestAsk: p.price + 0.5},
+			Bids:         []models.OrderBookLevel{{Price: p.price - 0.5, Volume: 2}},
+			Asks:         []models.OrderBookLevel{{Price: p.price + 0.5, Volume: 1.5}},
+		}
+		if err := db.SaveOrderBookSnapshot(snapshot); err != nil {
+			t.Fatal(err)
+		}
+		trades := []models.Trade{
+			{Pair: p.name, TradeID: 1, Price: p.price, Volume: 0.5, Side: "b", OrderType: "m", Timestamp: now.Add(-time.Hour)},
+			{Pair: p.name, TradeID: 2, Price: p.price + 1, Volume: 25, Side: "s", OrderType: "l", Timestamp: now.Add(-30 * time.Minute)},
+		}
+		if err := db.SaveTradesBatch(p.name, trades, strconv.FormatInt(now.UnixNano(), 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	skew := 12.0
+	for _, status := range []*models.ServerStatus{
+		{Timestamp: now.Add(-2 * time.Hour), Status: "online", Error: "[]", LatencyMs: 80, SystemTime: now.Add(-2 * time.Hour)},
+		{Timestamp: now.Add(-time.Hour), Status: "maintenance", Error: "[]", LatencyMs: 95, SystemTime: now.Add(-time.Hour), ClockSkewMs: &skew},
+	} {
+		if err := db.SaveServerStatus(status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alert := &models.SpreadAlert{
+		Symbol: "BTC/USD", Timestamp: now.Add(-time.Hour), HighSource: "coinbase", HighPrice: 67040,
+		LowSource: database.SourceKraken, LowPrice: 67000, SpreadBps: 5.97,
+	}
+	if err := db.SaveSpreadAlert(alert); err != nil {
+		t.Fatal(err)
+	}
+	point := &models.IndexPoint{
+		Asset: "BTC", Quote: "USD", Timestamp: now, VWAP: 67020, Median: 67020, Used: 2,
+		Components: []models.IndexComponent{{Source: database.SourceKraken, Pair: "XXBTZUSD", Quote: "USD"}},
+	}
+	if err := db.SaveIndexPoint(point); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// contractKrakenResponses are the bodies of the fake Kraken, in the shapes
+// of Kraken's documentation.
+func contractKrakenResponses(now time.Time) map[string]string {
+	unix := time.Now().Unix()
+	return map[string]string{
+		"Time":         fmt.Sprintf(`{"error":[],"result":{"unixtime":%d,"rfc1123":%q}}`, unix, time.Unix(unix, 0).UTC().Format(time.RFC1123)),
+		"SystemStatus": fmt.Sprintf(`{"error":[],"result":{"status":"online","timestamp":%q}}`, time.Now().UTC().Format(time.RFC3339)),
+		"AssetPairs": `{"error":[],"result":{` +
+			`"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","base":"XXBT","quote":"ZUSD","pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001","costmin":"0.5","tick_size":"0.1","status":"online"},` +
+			`"XETHZUSD":{"altname":"ETHUSD","wsname":"ETH/USD","base":"XETH","quote":"ZUSD","pair_decimals":2,"lot_decimals":8,"ordermin":"0.002","costmin":"0.5","tick_size":"0.01","status":"online"}}}`,
+		"Ticker": `{"error":[],"result":{` +
+			`"XXBTZUSD":{"a":["67005.10000","1","1.000"],"b":["67005.00000","3","3.000"],"c":["67005.10000","0.00150000"],"v":["812.5","1520.25"],"p":["66950.1","66900.5"],"t":[10250,21040],"l":["66500.0","66100.0"],"h":["67200.0","67350.0"],"o":"66800.0"},` +
+			`"XETHZUSD":{"a":["3501.10","4","4.000"],"b":["3501.00","9","9.000"],"c":["3501.05","0.20000000"],"v":["9100.5","18250.0"],"p":["3490.2","3485.7"],"t":[8120,16044],"l":["3450.00","3440.00"],"h":["3520.00","3530.00"],"o":"3480.00"}}}`,
+		"Depth":   fmt.Sprintf(`{"error":[],"result":{"XXBTZUSD":{"asks":[["67005.1","1.5",%[1]d],["67006.0","2.0",%[1]d]],"bids":[["67005.0","3.1",%[1]d],["67004.0","0.8",%[1]d]]}}}`, now.Unix()),
+		"Trades":  fmt.Sprintf(`{"error":[],"result":{"XXBTZUSD":[["67005.1","0.0015",%d.1234,"b","m","",81234567]],"last":"%d"}}`, now.Unix(), now.UnixNano()),
+		"OHLC":    fmt.Sprintf(`{"error":[],"result":{"XXBTZUSD":[[%d,"67000.0","67010.0","66990.0","67005.1","67001.2","1.5",12]],"last":%d}}`, now.Add(-time.Hour).Unix(), now.Add(-time.Hour).Unix()),
+		"Balance": `{"error":[],"result":{"XXBT":"0.5000000000","ZUSD":"1000.0000"}}`,
+		"Ledgers": fmt.Sprintf(`{"error":[],"result":{"ledger":{"L4UESK-KG3EQ-UFO4T5":{"refid":"TJKLXX-PGMUI-4NTLXU","time":%d.6879,"type":"deposit","subtype":"","aclass":"currency","asset":"XXBT","amount":"0.5000000000","fee":"0.0000000000","balance":"0.5000000000"}},"count":1}}`,
+			now.Add(-24*time.Hour).Unix()),
+	}
+}
+
+// withCSVDir runs the test in a directory holding the CSV export of now.
+func withCSVDir(t *testing.T, now time.Time) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "csv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("top10_5min_highlow_%s_%s.csv", now.Format("20060102"), now.Format("150405"))
+	content := "Pair,Timestamp,Open,High,Low,Close,Volume\nXXBTZUSD," + now.Format(time.RFC3339) + ",67000,67010,66990,67005.1,1.5\n"
+	if err := os.WriteFile(filepath.Join(dir, "csv", name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/stats"
+	"github.com/gin-gonic/gin"
+)
+
+type rollingCorrelation struct {
+	Time     time.Time `json:"time"`
+	Pearson  float64   `json:"pearson"`
+	Spearman float64   `json:"spearman"`
+}
+
+func (h *Handler) GetCorrelation(c *gin.Context) {
+	var pairs []string
+	for _, p := range strings.Split(c.Query("pairs"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pairs = append(pairs, p)
+		}
+	}
+	if len(pairs) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "au moins deux paires sont nécessaires dans pairs"})
+		return
+	}
+
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, intervalStr, err := parseCandleInterval(c, "1h")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fill, err := candles.ParseFillMode(c.DefaultQuery("fill", string(candles.FillForward)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rolling, err := parsePositiveInt(c, "rolling", 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if rolling > 0 && (len(pairs) != 2 || rolling < 3) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "le mode rolling nécessite exactement deux paires et une fenêtre d'au moins 3 intervalles"})
+		return
+	}
+
+	series := make(map[string][]candles.Candle, len(pairs))
+	for _, pair := range pairs {
+		data, err := h.db.GetCandlesFromDB(pair, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des bougies"})
+			return
+		}
+		if len(data) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("aucune bougie pour %s sur la période demandée", pair)})
+			return
+		}
+		series[pair] = candles.FromHistorical(data)
+	}
+
+	aligned := candles.Align(series, interval, fill)
+	times, returns := consecutiveReturns(aligned, interval)
+
+	response := gin.H{
+		"pairs":        pairs,
+		"interval":     intervalStr,
+		"from":         from,
+		"to":           to,
+		"fill":         fill,
+		"observations": len(times),
+		"missing":      aligned.Missing,
+	}
+
+	if rolling > 0 {
+		x, y := returns[pairs[0]], returns[pairs[1]]
+		points := make([]rollingCorrelation, 0)
+		for end := rolling; end <= len(x); end++ {
+			points = append(points, rollingCorrelation{
+				Time:     times[end-1],
+				Pearson:  stats.Pearson(x[end-rolling:end], y[end-rolling:end]),
+				Spearman: stats.Spearman(x[end-rolling:end], y[end-rolling:end]),
+			})
+		}
+		response["rolling"] = rolling
+		response["series"] = points
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	pearson := make([][]float64, len(pairs))
+	spearman := make([][]float64, len(pairs))
+	for i, a := range pairs {
+		pearson[i] = make([]float64, len(pairs))
+		spearman[i] = make([]float64, len(pairs))
+		for j, b := range pairs {
+			if i == j {
+				pearson[i][j], spearman[i][j] = 1, 1
+				continue
+			}
+			pearson[i][j] = stats.Pearson(returns[a], returns[b])
+			spearman[i][j] = stats.Spearman(returns[a], returns[b])
+		}
+	}
+	response["pearson"] = pearson
+	response["spearman"] = spearman
+
+	c.JSON(http.StatusOK, response)
+}
+
+// consecutiveReturns computes the log returns between adjacent buckets of the
+// aligned grid, with the time of the bucket closing each return. In drop
+// mode, a return spanning a dropped bucket would cover several intervals and
+// is skipped. A bucket where a pair has no positive close is skipped for
+// every pair, so that the series stay paired.
+func consecutiveReturns(aligned candles.Aligned, interval time.Duration) ([]time.Time, map[string][]float64) {
+	var times []time.Time
+	returns := make(map[string][]float64, len(aligned.Closes))
+	for i := 1; i < len(aligned.Times); i++ {
+		if aligned.Times[i].Sub(aligned.Times[i-1]) != interval || !positiveCloses(aligned, i-1, i) {
+			continue
+		}
+		times = append(times, aligned.Times[i])
+		for pair, closes := range aligned.Closes {
+			returns[pair] = append(returns[pair], stats.LogReturns(closes[i-1:i+1])...)
+		}
+	}
+	return times, returns
+}
+
+func positiveCloses(aligned candles.Aligned, buckets ...int) bool {
+	for _, closes := range aligned.Closes {
+		for _, i := range buckets {
+			if closes[i] <= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+func TestConsecutiveReturns(t *testing.T) {
+	start := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return start.Add(time.Duration(h) * time.Hour) }
+	aligned := candles.Aligned{
+		// 02:00 was dropped.
+		Times: []time.Time{hour(0), hour(1), hour(3), hour(4), hour(5)},
+		Closes: map[string][]float64{
+			"a": {100, 110, 121, 133.1, 146.41},
+			// No positive close at 04:00.
+			"b": {10, 20, 40, 0, 80},
+		},
+	}
+
+	times, returns := consecutiveReturns(aligned, time.Hour)
+	if want := []time.Time{hour(1)}; !reflect.DeepEqual(times, want) {
+		t.Fatalf("times %v, want %v", times, want)
+	}
+	if len(returns["a"]) != 1 || len(returns["b"]) != 1 ||
+		math.Abs(returns["a"][0]-math.Log(1.1)) > 1e-12 || math.Abs(returns["b"][0]-math.Ln2) > 1e-12 {
+		t.Errorf("returns %v", returns)
+	}
+
+	aligned.Closes["b"][3] = 60
+	times, returns = consecutiveReturns(aligned, time.Hour)
+	if want := []time.Time{hour(1), hour(4), hour(5)}; !reflect.DeepEqual(times, want) {
+		t.Errorf("times %v, want %v", times, want)
+	}
+	for pair, r := range returns {
+		if len(r) != len(times) {
+			t.Errorf("%s: %d returns for %d times", pair, len(r), len(times))
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AddExchange registers another venue. Its tickers are collected for the
+// tracked pairs on every save cycle.
+func (h *Handler) AddExchange(ex exchange.Exchange) {
+	h.exchanges[ex.Name()] = ex
+}
+
+func (h *Handler) exchangeNames() []string {
+	names := make([]string, 0, len(h.exchanges))
+	for name := range h.exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (h *Handler) loadExchange(c *gin.Context) (exchange.Exchange, bool) {
+	ex, ok := h.exchanges[strings.ToLower(c.Param("exchange"))]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("exchange inconnu, valeurs possibles: %s", strings.Join(h.exchangeNames(), ", "))})
+		return nil, false
+	}
+	return ex, true
+}
+
+func requireSymbol(c *gin.Context) (string, bool) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol requis (ex: BTC/USD)"})
+		return "", false
+	}
+	return symbol, true
+}
+
+// respondExchangeError reports unknown markets as 404 and upstream failures
+// as 502.
+func respondExchangeError(c *gin.Context, err error) {
+	var unknown exchange.ErrUnknownMarket
+	if errors.As(err, &unknown) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+}
+
+func (h *Handler) ListExchanges(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"exchanges": h.exchangeNames()})
+}
+
+func (h *Handler) GetExchangeMarkets(c *gin.Context) {
+	ex, ok := h.loadExchange(c)
+	if !ok {
+		return
+	}
+	markets, err := ex.ListMarkets()
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+
+	base, quote := strings.ToUpper(c.Query("base")), strings.ToUpper(c.Query("quote"))
+	filtered := make([]exchange.Market, 0, len(markets))
+	for _, m := range markets {
+		if (base == "" || m.Base == base) && (quote == "" || m.Quote == quote) {
+			filtered = append(filtered, m)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"exchange": ex.Name(),
+		"markets":  filtered,
+		"count":    len(filtered),
+	})
+}
+
+func (h *Handler) GetExchangeTicker(c *gin.Context) {
+	ex, ok := h.loadExchange(c)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(c)
+	if !ok {
+		return
+	}
+	ticker, err := ex.Ticker(symbol)
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ticker)
+}
+
+func (h *Handler) GetExchangeOHLC(c *gin.Context) {
+	ex, ok := h.loadExchange(c)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(c)
+	if !ok {
+		return
+	}
+	interval, err := candles.ParseDuration(c.DefaultQuery("interval", "1h"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval invalide"})
+		return
+	}
+	var since time.Time
+	if s := c.Query("since"); s != "" {
+		if since, err = parseTime(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	data, err := ex.OHLC(symbol, interval, since)
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"exchange": ex.Name(),
+		"symbol":   symbol,
+		"interval": interval.String(),
+		"candles":  data,
+		"count":    len(data),
+	})
+}
+
+func (h *Handler) GetExchangeDepth(c *gin.Context) {
+	ex, ok := h.loadExchange(c)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(c)
+	if !ok {
+		return
+	}
+	count, err := parsePositiveInt(c, "count", 100)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	book, err := ex.Depth(symbol, count)
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, book)
+}
+
+func (h *Handler) GetExchangeTrades(c *gin.Context) {
+	ex, ok := h.loadExchange(c)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(c)
+	if !ok {
+		return
+	}
+	limit, err := parsePositiveInt(c, "limit", 100)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trades, err := ex.Trades(symbol, limit)
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"exchange": ex.Name(),
+		"symbol":   symbol,
+		"trades":   trades,
+		"count":    len(trades),
+	})
+}
+
+// trackedPair is a Kraken pair collected in the current cycle with its
+// ticker.
+type trackedPair struct {
+	pair models.TradingPair
+	info models.PairInfo
+}
+
+// collectVenueTickers stores, for every other venue, the ticker of the
+// markets matching the tracked Kraken pairs, tagged with the venue as
+// source, then checks the cross-venue spread of every pair.
+func (h *Handler) collectVenueTickers(pairs []trackedPair, timestamp time.Time) {
+	for _, tracked := range pairs {
+		symbol := exchange.Symbol(tracked.pair.Base, tracked.pair.Quote)
+		quotes := []venueQuote{newVenueQuote(database.SourceKraken, tracked.pair.Name, tracked.info)}
+
+		for _, name := range h.exchangeNames() {
+			if name == database.SourceKraken {
+				continue
+			}
+			ticker, err := h.exchanges[name].Ticker(symbol)
+			if err != nil {
+				var unknown exchange.ErrUnknownMarket
+				if !errors.As(err, &unknown) {
+					slog.WarnContext(h.ctx, "échec de la récupération du ticker", "symbol", symbol, "exchange", name, logging.Err(err))
+				}
+				continue
+			}
+
+			base, quote, _ := strings.Cut(ticker.Symbol, "/")
+			venuePair := &models.TradingPair{
+				Name:        ticker.Native,
+				Base:        base,
+				Quote:       quote,
+				Source:      name,
+				LastUpdated: timestamp,
+			}
+			if err := h.db.SaveTradingPair(venuePair); err != nil {
+				slog.ErrorContext(h.ctx, "échec de l'enregistrement de la paire", "pair", ticker.Native, "exchange", name, logging.Err(err))
+				continue
+			}
+			info := models.PairInfo{
+				PairID:    venuePair.ID,
+				Price:     ticker.Last,
+				Volume24h: ticker.Volume24h,
+				High24h:   ticker.High24h,
+				Low24h:    ticker.Low24h,
+				Bid:       ticker.Bid,
+				Ask:       ticker.Ask,
+				VWAP24h:   ticker.VWAP24h,
+				Open:      ticker.Open,
+				Timestamp: timestamp,
+			}
+			if err := h.db.SavePairInfo(&info); err != nil {
+				slog.ErrorContext(h.ctx, "échec de l'enregistrement du ticker", "pair", ticker.Native, "exchange", name, logging.Err(err))
+				continue
+			}
+			quotes = append(quotes, newVenueQuote(name, ticker.Native, info))
+		}
+
+		h.checkSpreadAlert(symbol, quotes, timestamp)
+	}
+}
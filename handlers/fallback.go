@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/gin-gonic/gin"
+)
+
+// storedTopPairs answers /api/pairs when Kraken fails, with the top 10
+// pairs of the latest collection in the shape of topTradingPairs: each pair
+// has its base, quote and 24 hours volume, but none of the other fields of
+// Kraken's AssetPairs.
+func (h *Handler) storedTopPairs() (any, error) {
+	snapshots, err := h.db.GetLatestTopPairsFromDB(database.SourceKraken, 10)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make(map[string]any, len(snapshots))
+	for _, s := range snapshots {
+		pairs[s.Name] = map[string]any{
+			"base":  s.Base,
+			"quote": s.Quote,
+			"v":     formatTicker(s.PairInfo)["v"],
+		}
+	}
+	return gin.H{
+		"pairs": pairs,
+		"count": len(pairs),
+		"stale": true,
+		"as_of": snapshots[0].Timestamp,
+	}, nil
+}
+
+// storedPairInfo answers /api/pairs/:pair when Kraken fails, with the
+// latest stored ticker of the pair in the shape of Kraken's response.
+func (h *Handler) storedPairInfo(pair string) (any, error) {
+	snapshot, err := h.db.GetLatestPairSnapshotFromDB(database.SourceKraken, pair)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"error":  []string{},
+		"result": map[string]any{snapshot.Name: formatTicker(snapshot.PairInfo)},
+		"stale":  true,
+		"as_of":  snapshot.Timestamp,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/client"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const (
+	tickerBody = `{"error":[],"result":{"XXBTZUSD":{"a":["67005.10000","1","1.000"],"b":["67005.00000","3","3.000"],` +
+		`"c":["67005.10000","0.00150000"],"v":["812.5","1520.25"],"p":["66950.1","66900.5"],"t":[10250,21040],` +
+		`"l":["66500.0","66100.0"],"h":["67200.0","67350.0"],"o":"66800.0"}}}`
+	assetPairsBody = `{"error":[],"result":{"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","base":"XXBT","quote":"ZUSD","pair_decimals":1}}}`
+	outageBody     = `{"error":["EService:Unavailable"]}`
+)
+
+// seedPair stores a pair of the collection with its ticker.
+func seedPair(t *testing.T, db *database.DB, name, base, quote string, info models.PairInfo) {
+	t.Helper()
+	pair := &models.TradingPair{Name: name, Base: base, Quote: quote, LastUpdated: info.Timestamp}
+	if err := db.SaveTradingPair(pair); err != nil {
+		t.Fatal(err)
+	}
+	info.PairID = pair.ID
+	if err := db.SavePairInfo(&info); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func get(t *testing.T, h *Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	newTestEngine(h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body)
+	}
+	return w
+}
+
+// Kraken reports an outage in the error list of a 200: the latest cached
+// response is served while it is recent enough, the stored data otherwise.
+func TestPairTickerOnKrakenError(t *testing.T) {
+	db := newTestDB(t)
+	stored := models.PairInfo{
+		Price: 66000.5, LastTradeVolume: 0.25, Bid: 66000, Ask: 66001, Volume24h: 1400.75,
+		VWAP24h: 65900, TradeCount24h: 20000, High24h: 66500, Low24h: 65000, Open: 65500,
+		Timestamp: time.Date(2024, 6, 10, 11, 55, 0, 0, time.UTC),
+	}
+	seedPair(t, db, "XXBTZUSD", "XXBT", "ZUSD", stored)
+
+	h := NewHandler(db, newFakeKraken(t, map[string]string{"Ticker": tickerBody}))
+	if err := h.SetCacheTTL(cachePair, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	live := get(t, h, "/api/pairs/XXBTZUSD")
+	if cache := live.Header().Get("X-Cache"); cache != cacheMiss {
+		t.Fatalf("X-Cache %s, want %s", cache, cacheMiss)
+	}
+
+	h.client = newFakeKraken(t, map[string]string{"Ticker": outageBody})
+	time.Sleep(3 * time.Millisecond)
+	stale := get(t, h, "/api/pairs/XXBTZUSD")
+	if cache := stale.Header().Get("X-Cache"); cache != cacheStaleOnError {
+		t.Errorf("X-Cache %s, want %s", cache, cacheStaleOnError)
+	}
+	if stale.Body.String() != live.Body.String() {
+		t.Errorf("body %s, want the cached %s", stale.Body, live.Body)
+	}
+
+	h.responses = newResponseCache()
+	fallback := get(t, h, "/api/pairs/XXBTZUSD")
+	if cache := fallback.Header().Get("X-Cache"); cache != cacheFallback {
+		t.Fatalf("X-Cache %s, want %s", cache, cacheFallback)
+	}
+
+	var liveTicker, storedTicker client.PairTicker
+	if err := json.Unmarshal(live.Body.Bytes(), &liveTicker); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(fallback.Body.Bytes(), &storedTicker); err != nil {
+		t.Fatal(err)
+	}
+	if liveTicker.Stale || !storedTicker.Stale || storedTicker.AsOf == nil || !storedTicker.AsOf.Equal(stored.Timestamp) {
+		t.Errorf("stale %v, %v, as_of %v", liveTicker.Stale, storedTicker.Stale, storedTicker.AsOf)
+	}
+	if storedTicker.Error == nil || len(storedTicker.Error) != 0 {
+		t.Errorf("error %#v, want an empty list", storedTicker.Error)
+	}
+
+	// The stored ticker reads back as it was collected.
+	var raw struct {
+		Result map[string]map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal(fallback.Body.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	got := parseTicker(raw.Result["XXBTZUSD"])
+	want := stored
+	want.Timestamp = time.Time{}
+	if got != want {
+		t.Errorf("stored ticker %+v, want %+v", got, want)
+	}
+}
+
+func TestTradingPairsOnKrakenError(t *testing.T) {
+	db := newTestDB(t)
+	seedPair(t, db, "XXBTZUSD", "XXBT", "ZUSD", models.PairInfo{
+		Price: 66000.5, Volume24h: 1400.75, Timestamp: time.Date(2024, 6, 10, 11, 55, 0, 0, time.UTC),
+	})
+
+	h := NewHandler(db, newFakeKraken(t, map[string]string{"AssetPairs": assetPairsBody, "Ticker": tickerBody}))
+	var live client.TradingPairs
+	if err := json.Unmarshal(get(t, h, "/api/pairs").Body.Bytes(), &live); err != nil {
+		t.Fatal(err)
+	}
+
+	h.client = newFakeKraken(t, map[string]string{"AssetPairs": outageBody})
+	h.responses = newResponseCache()
+	w := get(t, h, "/api/pairs")
+	if cache := w.Header().Get("X-Cache"); cache != cacheFallback {
+		t.Fatalf("X-Cache %s, want %s", cache, cacheFallback)
+	}
+	var stored client.TradingPairs
+	if err := json.Unmarshal(w.Body.Bytes(), &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	wantLive := map[string]client.AssetPair{"XXBTZUSD": {Base: "XXBT", Quote: "ZUSD", V: []string{"812.5", "1520.25"}}}
+	wantStored := map[string]client.AssetPair{"XXBTZUSD": {Base: "XXBT", Quote: "ZUSD", V: []string{"1400.75", "1400.75"}}}
+	if !reflect.DeepEqual(live.Pairs, wantLive) || live.Stale {
+		t.Errorf("live pairs %+v, stale %v", live.Pairs, live.Stale)
+	}
+	if !reflect.DeepEqual(stored.Pairs, wantStored) || !stored.Stale || stored.Count != 1 {
+		t.Errorf("stored pairs %+v, count %d, stale %v", stored.Pairs, stored.Count, stored.Stale)
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,38 +14,93 @@ import (
 	"strings"
 	"time"
 
+	"github.com/antonyloussararian/Go-CryptoPrice/account"
 	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
 	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
 	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/antonyloussararian/Go-CryptoPrice/paper"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Handler struct {
-	db     *database.DB
-	client *kraken.Client
+	db             *database.DB
+	client         *kraken.Client
+	indicatorCache *indicatorCache
+	responses      *responseCache
+	paper          *paper.Engine
+	account        *account.Syncer
+	exchanges      map[string]exchange.Exchange
+	spreadAlertBps float64
+	indexAssets    []string
+	health         *healthState
+	// ctx is the context of the collection cycle run by a copy returned by
+	// withContext, used for its logs.
+	ctx context.Context
 }
 
 func NewHandler(db *database.DB, client *kraken.Client) *Handler {
+	krakenExchange := exchange.NewKraken(client)
 	return &Handler{
-		db:     db,
-		client: client,
+		db:             db,
+		client:         client,
+		indicatorCache: newIndicatorCache(),
+		responses:      newResponseCache(),
+		paper:          paper.NewEngine(db, client),
+		exchanges:      map[string]exchange.Exchange{krakenExchange.Name(): krakenExchange},
+		spreadAlertBps: defaultSpreadAlertBps,
+		indexAssets:    defaultIndexAssets,
+		health:         &healthState{},
+		ctx:            context.Background(),
 	}
 }
 
+// withContext returns a copy of the handler whose Kraken and venue calls,
+// database queries and logs carry ctx. Request handlers get one from scoped.
+func (h *Handler) withContext(ctx context.Context) *Handler {
+	scoped := *h
+	scoped.db = h.db.WithContext(ctx)
+	scoped.client = h.client.WithContext(ctx)
+	if h.account != nil {
+		scoped.account = h.account.WithContext(ctx)
+	}
+	scoped.exchanges = make(map[string]exchange.Exchange, len(h.exchanges))
+	for name, ex := range h.exchanges {
+		scoped.exchanges[name] = ex.WithContext(ctx)
+	}
+	scoped.ctx = ctx
+	return &scoped
+}
+
+var tracer = tracing.Tracer("handlers")
+
+// startSpan starts a child span of the handler context and returns a copy
+// of the handler scoped to it.
+func (h *Handler) startSpan(name string, attrs ...attribute.KeyValue) (*Handler, trace.Span) {
+	ctx, span := tracer.Start(h.ctx, name, trace.WithAttributes(attrs...))
+	return h.withContext(ctx), span
+}
+
 func (h *Handler) GetServerStatus(c *gin.Context) {
-	status, err := h.client.GetServerStatus()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, status)
+	h.respondCached(c, cacheStatus, "", func(h *Handler) (any, error) {
+		return h.client.GetServerStatus()
+	}, nil)
 }
 
 func (h *Handler) GetTradingPairs(c *gin.Context) {
+	h.respondCached(c, cachePairs, "", (*Handler).topTradingPairs, (*Handler).storedTopPairs)
+}
+
+// topTradingPairs returns the 10 pairs with the largest 24 hours volume.
+func (h *Handler) topTradingPairs() (any, error) {
 	pairs, err := h.client.GetTradingPairs()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
 	type pairVolume struct {
@@ -76,10 +134,10 @@ func (h *Handler) GetTradingPairs(c *gin.Context) {
 		topPairs[pairVolumes[i].name] = pairs[pairVolumes[i].name]
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	return gin.H{
 		"pairs": topPairs,
 		"count": len(topPairs),
-	})
+	}, nil
 }
 
 func (h *Handler) GetPairInfo(c *gin.Context) {
@@ -89,12 +147,11 @@ func (h *Handler) GetPairInfo(c *gin.Context) {
 		return
 	}
 
-	info, err := h.client.GetPairInfo(pair)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, info)
+	h.respondCached(c, cachePair, pair, func(h *Handler) (any, error) {
+		return h.client.GetPairInfo(pair)
+	}, func(h *Handler) (any, error) {
+		return h.storedPairInfo(pair)
+	})
 }
 
 func (h *Handler) createCSV(targetTime time.Time) error {
@@ -329,37 +386,63 @@ func (h *Handler) GetDBData(c *gin.Context) {
 	})
 }
 
+// SaveDataToDB runs one collection cycle and records its duration and
+// outcome in the metrics. Every log of the cycle carries its cycle_id, and
+// its Kraken calls and queries are traced under one root span.
 func (h *Handler) SaveDataToDB() error {
-	_, err := h.client.GetServerStatus()
+	cycleID := logging.NewID()
+	ctx, span := tracer.Start(logging.WithCycleID(context.Background(), cycleID), "SaveDataToDB",
+		trace.WithNewRoot(), trace.WithAttributes(attribute.String("cycle.id", cycleID)))
+	defer span.End()
+	slog.InfoContext(ctx, "début du cycle d'enregistrement")
+
+	start := time.Now()
+	err := h.withContext(ctx).saveData()
+	if errors.Is(err, errCollectionPaused) {
+		metrics.ObservePausedSaveCycle(start)
+		span.SetAttributes(attribute.Bool("cycle.paused", true))
+		slog.InfoContext(ctx, "cycle d'enregistrement suspendu : Kraken en maintenance", logging.Duration(time.Since(start)))
+		return nil
+	}
+	metrics.ObserveSaveCycle(start, err)
+	tracing.RecordError(span, err)
+
 	if err != nil {
+		slog.ErrorContext(ctx, "échec du cycle d'enregistrement", logging.Duration(time.Since(start)), logging.Err(err))
 		return err
 	}
+	h.health.recordSuccess(time.Now())
+	slog.InfoContext(ctx, "fin du cycle d'enregistrement", logging.Duration(time.Since(start)))
+	return nil
+}
 
-	errorJSON := "[]"
-	if err != nil {
-		errorJSON = fmt.Sprintf(`["%s"]`, err.Error())
-	}
+// errCollectionPaused is returned by saveData when Kraken is in
+// maintenance: the cycle is skipped instead of failing on every call.
+var errCollectionPaused = errors.New("collecte suspendue pendant la maintenance de Kraken")
 
-	serverStatus := &models.ServerStatus{
-		Timestamp: time.Now(),
-		Status:    "online",
-		Error:     errorJSON,
-	}
-	if err := h.db.SaveServerStatus(serverStatus); err != nil {
+func (h *Handler) saveData() error {
+	// The outcome of the Kraken check is stored before a failure stops the
+	// cycle, so that server_status records outages too.
+	check := checkKraken(h.client)
+	h.health.recordKraken(check)
+	if err := h.saveServerStatus(check); err != nil {
 		return err
 	}
+	if !check.reachable() {
+		return fmt.Errorf("kraken injoignable: %s", check.Error)
+	}
+	if check.paused() {
+		return errCollectionPaused
+	}
+	if check.degraded() {
+		slog.InfoContext(h.ctx, "Kraken en mode dégradé, collecte des données de marché maintenue", "status", check.Status)
+	}
 
 	pairs, err := h.client.GetTradingPairs()
 	if err != nil {
 		return err
 	}
 
-	type pairVolume struct {
-		name   string
-		volume float64
-		data   map[string]any
-	}
-
 	var pairVolumes []pairVolume
 
 	for pairName, pairData := range pairs {
@@ -384,103 +467,147 @@ func (h *Handler) SaveDataToDB() error {
 
 	now := time.Now()
 	lastCandleTime := now.Truncate(5 * time.Minute)
-	lastCandleTimestamp := lastCandleTime.Unix()
 
+	csvHandler, csvSpan := h.startSpan("csv")
+	csvHandler.saveCSV(now, lastCandleTime)
+	csvSpan.End()
+
+	var tracked []trackedPair
+	for i := 0; i < len(pairVolumes) && i < 10; i++ {
+		if t := h.collectPair(pairVolumes[i], lastCandleTime); t != nil {
+			tracked = append(tracked, *t)
+		}
+	}
+
+	venuesHandler, venuesSpan := h.startSpan("collect venues")
+	venuesHandler.collectVenueTickers(tracked, lastCandleTime)
+	venuesSpan.End()
+
+	indexHandler, indexSpan := h.startSpan("update indexes")
+	indexHandler.updateIndexes(lastCandleTime)
+	indexSpan.End()
+
+	return nil
+}
+
+// saveCSV writes the CSV of the top pairs once per 5 minutes candle.
+func (h *Handler) saveCSV(now, lastCandleTime time.Time) {
 	lastCSV, err := h.getLatestCSV()
 	if err == nil {
 		lastCSVDate := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(lastCSV), "top10_5min_highlow_"), ".csv")
 		lastCSVTime, err := time.Parse("20060102150405", lastCSVDate)
 		if err == nil && lastCSVTime.Equal(lastCandleTime) {
-			fmt.Printf("Pas de nouveau CSV à créer, nous sommes dans la même bougie de 5 minutes\n")
+			slog.DebugContext(h.ctx, "pas de nouveau CSV à créer, même bougie de 5 minutes", "candle", lastCandleTime)
 		} else {
 			if err := h.createCSV(now); err != nil {
-				fmt.Printf("Erreur lors de la création du CSV: %v\n", err)
+				slog.ErrorContext(h.ctx, "échec de la création du CSV", logging.Err(err))
 			} else {
-				fmt.Printf("Nouveau CSV créé pour la bougie de %s\n", lastCandleTime.Format("2006-01-02 15:04:05"))
+				slog.InfoContext(h.ctx, "nouveau CSV créé", "candle", lastCandleTime)
 			}
 		}
 	} else {
 		if err := h.createCSV(now); err != nil {
-			fmt.Printf("Erreur lors de la création du CSV: %v\n", err)
+			slog.ErrorContext(h.ctx, "échec de la création du CSV", logging.Err(err))
 		} else {
-			fmt.Printf("Premier CSV créé pour la bougie de %s\n", lastCandleTime.Format("2006-01-02 15:04:05"))
+			slog.InfoContext(h.ctx, "premier CSV créé", "candle", lastCandleTime)
 		}
 	}
+}
 
-	for i := 0; i < len(pairVolumes) && i < 10; i++ {
-		pair := &models.TradingPair{
-			Name:        pairVolumes[i].name,
-			Base:        pairVolumes[i].data["base"].(string),
-			Quote:       pairVolumes[i].data["quote"].(string),
-			LastUpdated: lastCandleTime,
-		}
+type pairVolume struct {
+	name   string
+	volume float64
+	data   map[string]any
+}
 
-		if err := h.db.SaveTradingPair(pair); err != nil {
-			continue
-		}
+// collectPair stores the ticker, order book, trades and last candle of one
+// of the top pairs, in its own span of the cycle trace. It returns the pair
+// and its ticker when the ticker was stored.
+func (h *Handler) collectPair(pv pairVolume, lastCandleTime time.Time) *trackedPair {
+	h, span := h.startSpan("collect "+pv.name, attribute.String("pair", pv.name))
+	defer span.End()
 
-		info, err := h.client.GetPairInfo(pair.Name)
-		if err != nil {
-			continue
-		}
+	var tracked *trackedPair
+	pair := &models.TradingPair{
+		Name:        pv.name,
+		Base:        pv.data["base"].(string),
+		Quote:       pv.data["quote"].(string),
+		LastUpdated: lastCandleTime,
+	}
 
-		if pairInfo, ok := info[pair.Name].(map[string]any); ok {
-			price, _ := strconv.ParseFloat(pairInfo["c"].([]any)[0].(string), 64)
-			volume24h, _ := strconv.ParseFloat(pairInfo["v"].([]any)[1].(string), 64)
-			high24h, _ := strconv.ParseFloat(pairInfo["h"].([]any)[1].(string), 64)
-			low24h, _ := strconv.ParseFloat(pairInfo["l"].([]any)[1].(string), 64)
-
-			info := &models.PairInfo{
-				PairID:    pair.ID,
-				Price:     price,
-				Volume24h: volume24h,
-				High24h:   high24h,
-				Low24h:    low24h,
-				Timestamp: lastCandleTime,
-			}
-			if err := h.db.SavePairInfo(info); err != nil {
-				continue
-			}
-		}
+	if err := h.db.SaveTradingPair(pair); err != nil {
+		tracing.RecordError(span, err)
+		return nil
+	}
 
-		historical, err := h.client.GetHistoricalData(pair.Name, 5, lastCandleTimestamp)
-		if err != nil {
-			continue
+	info, err := h.client.GetPairInfo(pair.Name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil
+	}
+
+	result, _ := info["result"].(map[string]any)
+	if pairInfo, ok := result[pair.Name].(map[string]any); ok {
+		info := parseTicker(pairInfo)
+		info.PairID = pair.ID
+		info.Timestamp = lastCandleTime
+		if err := h.db.SavePairInfo(&info); err != nil {
+			tracing.RecordError(span, err)
+			return nil
+		}
+		tracked = &trackedPair{pair: *pair, info: info}
+		if err := h.paper.OnTicker(pair.Name, info.Bid, info.Ask, info.Price, time.Now()); err != nil {
+			slog.ErrorContext(h.ctx, "échec de l'exécution des ordres fictifs", "pair", pair.Name, logging.Err(err))
 		}
+	}
 
-		if result, ok := historical["result"].(map[string]any); ok {
-			if ohlc, ok := result[pair.Name].([]any); ok && len(ohlc) > 0 {
-				for _, candle := range ohlc {
-					if candleData, ok := candle.([]any); ok {
-						candleTimestamp := time.Unix(int64(candleData[0].(float64)), 0)
-						if candleTimestamp.Equal(lastCandleTime) {
-							open, _ := strconv.ParseFloat(candleData[1].(string), 64)
-							high, _ := strconv.ParseFloat(candleData[2].(string), 64)
-							low, _ := strconv.ParseFloat(candleData[3].(string), 64)
-							close, _ := strconv.ParseFloat(candleData[4].(string), 64)
-							volume, _ := strconv.ParseFloat(candleData[6].(string), 64)
-
-							data := &models.HistoricalData{
-								PairID:    pair.ID,
-								Timestamp: candleTimestamp,
-								Open:      open,
-								High:      high,
-								Low:       low,
-								Close:     close,
-								Volume:    volume,
-							}
-							if err := h.db.SaveHistoricalData(data); err != nil {
-								continue
-							}
-							break
+	if err := h.saveOrderBookSnapshot(pair); err != nil {
+		slog.ErrorContext(h.ctx, "échec de l'enregistrement du carnet d'ordres", "pair", pair.Name, logging.Err(err))
+	}
+
+	if _, err := h.collectTrades(pair.Name); err != nil {
+		slog.ErrorContext(h.ctx, "échec de la collecte des transactions", "pair", pair.Name, logging.Err(err))
+	}
+
+	historical, err := h.client.GetHistoricalData(pair.Name, 5, lastCandleTime.Unix())
+	if err != nil {
+		tracing.RecordError(span, err)
+		return tracked
+	}
+
+	if result, ok := historical["result"].(map[string]any); ok {
+		if ohlc, ok := result[pair.Name].([]any); ok && len(ohlc) > 0 {
+			for _, candle := range ohlc {
+				if candleData, ok := candle.([]any); ok {
+					candleTimestamp := time.Unix(int64(candleData[0].(float64)), 0)
+					if candleTimestamp.Equal(lastCandleTime) {
+						open, _ := strconv.ParseFloat(candleData[1].(string), 64)
+						high, _ := strconv.ParseFloat(candleData[2].(string), 64)
+						low, _ := strconv.ParseFloat(candleData[3].(string), 64)
+						close, _ := strconv.ParseFloat(candleData[4].(string), 64)
+						volume, _ := strconv.ParseFloat(candleData[6].(string), 64)
+
+						data := &models.HistoricalData{
+							PairID:    pair.ID,
+							Timestamp: candleTimestamp,
+							Open:      open,
+							High:      high,
+							Low:       low,
+							Close:     close,
+							Volume:    volume,
+						}
+						if err := h.db.SaveHistoricalData(data); err != nil {
+							continue
 						}
+						h.indicatorCache.invalidate(pair.Name)
+						break
 					}
 				}
 			}
 		}
 	}
 
-	return nil
+	return tracked
 }
 
 func (h *Handler) SaveDataNow(c *gin.Context) {
@@ -502,11 +629,8 @@ func (h *Handler) StartAutoSave() {
 		for {
 			select {
 			case <-ticker.C:
-				if err := h.SaveDataToDB(); err != nil {
-					fmt.Printf("Erreur lors de la sauvegarde automatique: %v\n", err)
-				} else {
-					fmt.Printf("Données sauvegardées automatiquement à %v\n", time.Now().Format("2006-01-02 15:04:05"))
-				}
+				// SaveDataToDB logs the outcome of the cycle.
+				h.SaveDataToDB()
 			}
 		}
 	}()
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a private in-memory database with the schema.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newFakeKrakenServer serves the public and private endpoints of Kraken
+// from the bodies of responses, keyed by endpoint ("Depth", "Balance"), and
+// returns the base URL of the API.
+func newFakeKrakenServer(t *testing.T, responses map[string]string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/0/public/"), "/0/private/")
+		body, ok := responses[endpoint]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/0"
+}
+
+// newFakeKraken returns a client of a fake Kraken serving responses.
+func newFakeKraken(t *testing.T, responses map[string]string) *kraken.Client {
+	t.Helper()
+	client := kraken.NewClient()
+	client.SetBaseURL(newFakeKrakenServer(t, responses))
+	return client
+}
+
+func newTestEngine(h *Handler) *gin.Engine {
+	r := gin.New()
+	r.Use(tracing.Middleware())
+	h.RegisterRoutes(r)
+	return r
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxCollectionAge is the age of the last successful collection above
+	// which the service is not ready: three missed 5 minutes cycles.
+	maxCollectionAge = 15 * time.Minute
+	// krakenProbeTTL is how long a Kraken check is reused by /readyz, so
+	// that probes do not consume the API call counter.
+	krakenProbeTTL     = 30 * time.Second
+	krakenProbeTimeout = 5 * time.Second
+	// maxClockSkew is the clock difference with Kraken above which a warning
+	// is logged: candles are aligned on the local clock.
+	maxClockSkew = 2 * time.Second
+
+	statusUnreachable = "unreachable"
+)
+
+// krakenCheck is the outcome of one call to Kraken's SystemStatus, with the
+// clock skew measured from its Time endpoint.
+type krakenCheck struct {
+	Status      string
+	SystemTime  time.Time
+	LatencyMs   float64
+	ClockSkewMs *float64
+	CheckedAt   time.Time
+	Error       string
+}
+
+func (k krakenCheck) reachable() bool {
+	return k.Error == ""
+}
+
+// paused tells whether collecting would fail: Kraken serves no data during
+// maintenance.
+func (k krakenCheck) paused() bool {
+	return k.Status == kraken.StatusMaintenance
+}
+
+// degraded tells whether Kraken restricts trading. Market data is still
+// served, so collection goes on.
+func (k krakenCheck) degraded() bool {
+	return k.Status == kraken.StatusCancelOnly || k.Status == kraken.StatusPostOnly
+}
+
+// serverStatus is the row stored for the check.
+func (k krakenCheck) serverStatus() *models.ServerStatus {
+	errs := []string{}
+	if k.Error != "" {
+		errs = append(errs, k.Error)
+	}
+	errorJSON, _ := json.Marshal(errs)
+	return &models.ServerStatus{
+		Timestamp:   k.CheckedAt,
+		Status:      k.Status,
+		Error:       string(errorJSON),
+		LatencyMs:   k.LatencyMs,
+		SystemTime:  k.SystemTime,
+		ClockSkewMs: k.ClockSkewMs,
+	}
+}
+
+func checkKraken(client *kraken.Client) krakenCheck {
+	start := time.Now()
+	status, err := client.GetSystemStatus()
+	check := krakenCheck{
+		CheckedAt: start,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = statusUnreachable
+		check.Error = err.Error()
+		return check
+	}
+	check.Status = status.Status
+	check.SystemTime = status.Timestamp
+	check.ClockSkewMs = measureClockSkew(client)
+	return check
+}
+
+// measureClockSkew returns Kraken's clock minus the local one, in
+// milliseconds, or nil when the Time endpoint fails. The server time is
+// compared with the middle of the request; since it is truncated to the
+// second, half a second is added and the result is accurate to about ±0.5s
+// plus half the round trip.
+func measureClockSkew(client *kraken.Client) *float64 {
+	start := time.Now()
+	serverTime, err := client.GetServerTime()
+	if err != nil {
+		return nil
+	}
+	middle := start.Add(time.Since(start) / 2)
+	skew := serverTime.Time().Add(500 * time.Millisecond).Sub(middle)
+	ms := float64(skew.Milliseconds())
+	return &ms
+}
+
+// healthState is shared by the copies of the handler: the time of the last
+// successful collection and the last Kraken check.
+type healthState struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	kraken      *krakenCheck
+}
+
+func (s *healthState) recordSuccess(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSuccess = t
+}
+
+func (s *healthState) recordKraken(check krakenCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kraken = &check
+}
+
+func (s *healthState) snapshot() (time.Time, *krakenCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSuccess, s.kraken
+}
+
+// Healthz reports that the process is alive and serving requests.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz checks the database, the age of the last successful collection and
+// Kraken's reachability, reusing a Kraken check younger than 30 seconds.
+// Kraken being in maintenance is reported as degraded without failing the
+// check, since stored data can still be served, and the collection as
+// paused.
+func (h *Handler) Readyz(c *gin.Context) {
+	ready := true
+	now := time.Now()
+
+	dbCheck := gin.H{"status": "ok"}
+	start := time.Now()
+	if err := h.db.Ping(); err != nil {
+		ready = false
+		dbCheck = gin.H{"status": "error", "error": err.Error()}
+	}
+	dbCheck["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+
+	lastSuccess, check := h.health.snapshot()
+	cached := check != nil && now.Sub(check.CheckedAt) < krakenProbeTTL
+	if !cached {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), krakenProbeTimeout)
+		fresh := checkKraken(h.client.WithContext(ctx))
+		cancel()
+		h.health.recordKraken(fresh)
+		check = &fresh
+	}
+
+	// A collection paused by a Kraken maintenance is not an error.
+	collection := gin.H{"status": "ok", "max_age_seconds": maxCollectionAge.Seconds()}
+	if !lastSuccess.IsZero() {
+		collection["last_success"] = lastSuccess
+		collection["age_seconds"] = now.Sub(lastSuccess).Seconds()
+	}
+	switch {
+	case check.paused():
+		collection["status"] = "paused"
+	case lastSuccess.IsZero():
+		ready = false
+		collection["status"] = "error"
+		collection["error"] = "aucune collecte réussie depuis le démarrage"
+	case now.Sub(lastSuccess) > maxCollectionAge:
+		ready = false
+		collection["status"] = "error"
+		collection["error"] = "dernière collecte réussie trop ancienne"
+	}
+
+	krakenStatus := "ok"
+	switch {
+	case !check.reachable():
+		ready = false
+		krakenStatus = "error"
+	case check.Status != kraken.StatusOnline:
+		krakenStatus = "degraded"
+	}
+
+	krakenResult := gin.H{
+		"status":        krakenStatus,
+		"cached":        cached,
+		"system_status": check.Status,
+		"latency_ms":    check.LatencyMs,
+		"checked_at":    check.CheckedAt,
+		"circuit":       h.client.CircuitState(),
+	}
+	if !check.SystemTime.IsZero() {
+		krakenResult["system_time"] = check.SystemTime
+	}
+	if check.ClockSkewMs != nil {
+		krakenResult["clock_skew_ms"] = *check.ClockSkewMs
+	}
+	if check.Error != "" {
+		krakenResult["error"] = check.Error
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": gin.H{
+			"database":   dbCheck,
+			"collection": collection,
+			"kraken":     krakenResult,
+		},
+	})
+}
+
+// saveServerStatus stores the check, marking it as a transition when the
+// status differs from the previous stored one, and warns about a large
+// clock skew.
+func (h *Handler) saveServerStatus(check krakenCheck) error {
+	status := check.serverStatus()
+	previous, err := h.db.GetLatestServerStatusFromDB(check.CheckedAt)
+	switch {
+	case err == nil:
+		if previous.Status != status.Status {
+			status.PreviousStatus = previous.Status
+			slog.WarnContext(h.ctx, "changement du statut de Kraken", "from", previous.Status, "to", status.Status)
+		}
+	case !errors.Is(err, database.ErrNotFound):
+		return err
+	}
+
+	if skew := status.ClockSkewMs; skew != nil && math.Abs(*skew) > float64(maxClockSkew.Milliseconds()) {
+		slog.WarnContext(h.ctx, "horloge locale décalée par rapport à Kraken", "clock_skew_ms", *skew)
+	}
+	return h.db.SaveServerStatus(status)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/indicators"
+	"github.com/gin-gonic/gin"
+)
+
+// indicatorCache holds full indicator series per pair, keyed by interval and
+// canonical spec. A pair's entries are dropped as soon as a new candle is
+// stored for it. Like the response cache, it holds at most maxCacheEntries
+// series, since the pair and the specs are sent by the client.
+//
+// generations counts the invalidations of each pair: a series computed from
+// candles loaded before an invalidation is not stored, or it would outlive
+// the candle that should have dropped it.
+type indicatorCache struct {
+	mu          sync.RWMutex
+	entries     map[string]map[string]*indicatorEntry
+	size        int
+	generations map[string]uint64
+}
+
+type indicatorEntry struct {
+	points   []indicators.Point
+	storedAt time.Time
+}
+
+func newIndicatorCache() *indicatorCache {
+	return &indicatorCache{
+		entries:     make(map[string]map[string]*indicatorEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// generation is read before loading the candles a series is computed from,
+// then passed to set.
+func (c *indicatorCache) generation(pair string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generations[pair]
+}
+
+func (c *indicatorCache) get(pair, key string) ([]indicators.Point, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[pair][key]
+	if !ok {
+		return nil, false
+	}
+	return e.points, true
+}
+
+func (c *indicatorCache) set(pair, key string, generation uint64, points []indicators.Point) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[pair] != generation {
+		return
+	}
+	if _, ok := c.entries[pair][key]; !ok {
+		if c.size >= maxCacheEntries {
+			c.evictOldest()
+		}
+		c.size++
+	}
+	if c.entries[pair] == nil {
+		c.entries[pair] = make(map[string]*indicatorEntry)
+	}
+	c.entries[pair][key] = &indicatorEntry{points: points, storedAt: time.Now()}
+}
+
+func (c *indicatorCache) evictOldest() {
+	var oldestPair, oldestKey string
+	var oldest *indicatorEntry
+	for pair, series := range c.entries {
+		for key, e := range series {
+			if oldest == nil || e.storedAt.Before(oldest.storedAt) {
+				oldestPair, oldestKey, oldest = pair, key, e
+			}
+		}
+	}
+	if oldest == nil {
+		return
+	}
+	delete(c.entries[oldestPair], oldestKey)
+	if len(c.entries[oldestPair]) == 0 {
+		delete(c.entries, oldestPair)
+	}
+	c.size--
+}
+
+func (c *indicatorCache) invalidate(pair string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size -= len(c.entries[pair])
+	delete(c.entries, pair)
+	c.generations[pair]++
+}
+
+func (h *Handler) GetPairIndicators(c *gin.Context) {
+	pair := c.Param("pair")
+
+	specs, err := indicators.ParseSpecs(c.Query("names"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval, intervalStr, err := parseCandleInterval(c, "1h")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := parsePositiveInt(c, "limit", 100)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	generation := h.indicatorCache.generation(pair)
+	var series []candles.Candle
+	result := make(map[string]any, len(specs))
+	for _, spec := range specs {
+		key := interval.String() + "|" + spec.String()
+		points, ok := h.indicatorCache.get(pair, key)
+		if !ok {
+			ind, err := indicators.New(spec)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if series == nil {
+				series, err = candles.Load(h.db, pair, time.Time{}, time.Now(), interval)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des bougies"})
+					return
+				}
+			}
+			points = indicators.Compute(ind, series)
+			h.indicatorCache.set(pair, key, generation, points)
+		}
+
+		if len(points) > limit {
+			points = points[len(points)-limit:]
+		}
+		result[spec.String()] = points
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":       pair,
+		"interval":   intervalStr,
+		"indicators": result,
+	})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/indicators"
+)
+
+// A series computed from candles loaded before an invalidation must not be
+// stored.
+func TestIndicatorCacheGeneration(t *testing.T) {
+	c := newIndicatorCache()
+	points := []indicators.Point{{Values: map[string]float64{"sma": 1}}}
+
+	stale := c.generation("XXBTZUSD")
+	c.invalidate("XXBTZUSD")
+	c.set("XXBTZUSD", "1h|sma:20", stale, points)
+	if _, ok := c.get("XXBTZUSD", "1h|sma:20"); ok || c.size != 0 {
+		t.Fatalf("stale series stored, size %d", c.size)
+	}
+
+	c.set("XXBTZUSD", "1h|sma:20", c.generation("XXBTZUSD"), points)
+	c.set("XETHZUSD", "1h|sma:20", stale, points)
+	if _, ok := c.get("XXBTZUSD", "1h|sma:20"); !ok {
+		t.Error("current series not stored")
+	}
+	if _, ok := c.get("XETHZUSD", "1h|sma:20"); !ok {
+		t.Error("another pair's series dropped")
+	}
+
+	c.invalidate("XXBTZUSD")
+	if _, ok := c.get("XXBTZUSD", "1h|sma:20"); ok || c.size != 1 {
+		t.Errorf("after invalidate: size %d", c.size)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDepthCount  = 100
+	maxDepthCount      = 500
+	maxDepthHistory    = 2016 // a week of 5-minute snapshots
+	snapshotDepthCount = 100
+	snapshotLevels     = 20
+)
+
+func toOrderBookLevels(entries []kraken.OrderBookEntry) []models.OrderBookLevel {
+	levels := make([]models.OrderBookLevel, 0, len(entries))
+	for _, e := range entries {
+		levels = append(levels, models.OrderBookLevel{Price: e.Price, Volume: e.Volume})
+	}
+	return levels
+}
+
+// computeDepthMetrics expects bids sorted by descending price and asks by
+// ascending price, as returned by Kraken. Depth is expressed in base volume
+// and the imbalance is measured within the ±2% band around the mid price.
+func computeDepthMetrics(bids, asks []models.OrderBookLevel) models.DepthMetrics {
+	var m models.DepthMetrics
+	if len(bids) == 0 || len(asks) == 0 {
+		return m
+	}
+
+	m.BestBid = bids[0].Price
+	m.BestAsk = asks[0].Price
+	m.Spread = m.BestAsk - m.BestBid
+	m.MidPrice = (m.BestAsk + m.BestBid) / 2
+
+	for _, b := range bids {
+		if b.Price >= m.MidPrice*0.99 {
+			m.BidDepth1Pct += b.Volume
+		}
+		if b.Price >= m.MidPrice*0.98 {
+			m.BidDepth2Pct += b.Volume
+		}
+	}
+	for _, a := range asks {
+		if a.Price <= m.MidPrice*1.01 {
+			m.AskDepth1Pct += a.Volume
+		}
+		if a.Price <= m.MidPrice*1.02 {
+			m.AskDepth2Pct += a.Volume
+		}
+	}
+
+	if total := m.BidDepth2Pct + m.AskDepth2Pct; total > 0 {
+		m.Imbalance = (m.BidDepth2Pct - m.AskDepth2Pct) / total
+	}
+
+	return m
+}
+
+func (h *Handler) GetPairDepth(c *gin.Context) {
+	pair := c.Param("pair")
+	if pair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "le paramètre pair est obligatoire"})
+		return
+	}
+
+	count, err := parseBoundedInt(c, "count", defaultDepthCount, maxDepthCount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	book, err := h.client.GetOrderBook(pair, count)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "échec de la récupération du carnet d'ordres", "pair", pair, logging.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération du carnet d'ordres"})
+		return
+	}
+
+	bids := toOrderBookLevels(book.Bids)
+	asks := toOrderBookLevels(book.Asks)
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":      book.Pair,
+		"timestamp": time.Now(),
+		"bids":      bids,
+		"asks":      asks,
+		"metrics":   computeDepthMetrics(bids, asks),
+	})
+}
+
+func (h *Handler) GetPairDepthHistory(c *gin.Context) {
+	pair := c.Param("pair")
+	if pair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "le paramètre pair est obligatoire"})
+		return
+	}
+
+	limit, err := parseBoundedInt(c, "limit", 288, maxDepthHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshots, err := h.db.GetOrderBookSnapshotsFromDB(pair, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des carnets d'ordres"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":      pair,
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+	})
+}
+
+func (h *Handler) saveOrderBookSnapshot(pair *models.TradingPair) error {
+	book, err := h.client.GetOrderBook(pair.Name, snapshotDepthCount)
+	if err != nil {
+		return err
+	}
+
+	bids := toOrderBookLevels(book.Bids)
+	asks := toOrderBookLevels(book.Asks)
+
+	snapshot := &models.OrderBookSnapshot{
+		PairID:       pair.ID,
+		Timestamp:    pair.LastUpdated,
+		DepthMetrics: computeDepthMetrics(bids, asks),
+		Bids:         bids[:min(len(bids), snapshotLevels)],
+		Asks:         asks[:min(len(asks), snapshotLevels)],
+	}
+	return h.db.SaveOrderBookSnapshot(snapshot)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func TestComputeDepthMetrics(t *testing.T) {
+	levels := func(pv ...float64) []models.OrderBookLevel {
+		var l []models.OrderBookLevel
+		for i := 0; i < len(pv); i += 2 {
+			l = append(l, models.OrderBookLevel{Price: pv[i], Volume: pv[i+1]})
+		}
+		return l
+	}
+	bids := levels(99, 1, 98, 2, 97.5, 4)
+	asks := levels(101, 1, 101.5, 1, 102, 2, 102.5, 5)
+
+	tests := []struct {
+		name       string
+		bids, asks []models.OrderBookLevel
+		want       models.DepthMetrics
+	}{
+		{"no bids", nil, asks, models.DepthMetrics{}},
+		{"no asks", bids, nil, models.DepthMetrics{}},
+		// The mid price is 100; the levels at exactly 1% and 2% are within
+		// the bands.
+		{"both sides", bids, asks, models.DepthMetrics{
+			BestBid: 99, BestAsk: 101, Spread: 2, MidPrice: 100,
+			BidDepth1Pct: 1, AskDepth1Pct: 1, BidDepth2Pct: 3, AskDepth2Pct: 4,
+			Imbalance: -1.0 / 7,
+		}},
+		// Nothing within 2%: the imbalance stays 0.
+		{"wide spread", levels(90, 1), levels(110, 1), models.DepthMetrics{
+			BestBid: 90, BestAsk: 110, Spread: 20, MidPrice: 100,
+		}},
+		// Both best levels lie between 1% and 2% of the mid price.
+		{"outside the 1% band", levels(99.5, 3), levels(103, 1), models.DepthMetrics{
+			BestBid: 99.5, BestAsk: 103, Spread: 3.5, MidPrice: 101.25,
+			BidDepth2Pct: 3, AskDepth2Pct: 1,
+			Imbalance: 0.5,
+		}},
+	}
+	for _, tt := range tests {
+		got := computeDepthMetrics(tt.bids, tt.asks)
+		if math.Abs(got.Imbalance-tt.want.Imbalance) > 1e-12 {
+			t.Errorf("%s: imbalance %v, want %v", tt.name, got.Imbalance, tt.want.Imbalance)
+		}
+		got.Imbalance = tt.want.Imbalance
+		if got != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDepthParameters(t *testing.T) {
+	r := newTestEngine(NewHandler(newTestDB(t), newFakeKraken(t, nil)))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/pairs/XXBTZUSD/depth?count=0", http.StatusBadRequest},
+		{"/api/pairs/XXBTZUSD/depth?count=501", http.StatusBadRequest},
+		// The fake Kraken serves no order book.
+		{"/api/pairs/XXBTZUSD/depth?count=500", http.StatusInternalServerError},
+		{"/api/pairs/XXBTZUSD/depth/history", http.StatusOK},
+		{"/api/pairs/XXBTZUSD/depth/history?limit=2016", http.StatusOK},
+		{"/api/pairs/XXBTZUSD/depth/history?limit=2017", http.StatusBadRequest},
+		{"/api/pairs/XXBTZUSD/depth/history?limit=-1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("GET %s: status %d, want %d: %s", tt.path, w.Code, tt.status, w.Body)
+		}
+	}
+}
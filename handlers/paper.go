@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/antonyloussararian/Go-CryptoPrice/paper"
+	"github.com/antonyloussararian/Go-CryptoPrice/portfolio"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPaperBalance = 10000
+
+type paperAccountRequest struct {
+	Name           string  `json:"name" binding:"required"`
+	QuoteCurrency  string  `json:"quote_currency"`
+	InitialBalance float64 `json:"initial_balance" binding:"gte=0"`
+}
+
+type paperOrderRequest struct {
+	AccountID  int64   `json:"account_id" binding:"required"`
+	Pair       string  `json:"pair" binding:"required"`
+	Side       string  `json:"side" binding:"required"`
+	Type       string  `json:"type"`
+	Quantity   float64 `json:"quantity"`
+	LimitPrice float64 `json:"limit_price"`
+	StopPrice  float64 `json:"stop_price"`
+}
+
+type paperBalance struct {
+	Asset    string  `json:"asset"`
+	Amount   float64 `json:"amount"`
+	Price    float64 `json:"price"`
+	Value    float64 `json:"value"`
+	Priced   bool    `json:"priced"`
+	Reserved float64 `json:"reserved"`
+}
+
+// respondPaperError reports Kraken-like order errors as bad requests.
+func respondPaperError(c *gin.Context, err error, message string) {
+	var orderErr paper.OrderError
+	if errors.As(err, &orderErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": []string{orderErr.Error()}})
+		return
+	}
+	respondDBError(c, err, message)
+}
+
+func (h *Handler) CreatePaperAccount(c *gin.Context) {
+	var req paperAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := &models.PaperAccount{
+		Name:          req.Name,
+		QuoteCurrency: assets.Normalize(req.QuoteCurrency),
+		InitialValue:  req.InitialBalance,
+	}
+	if account.QuoteCurrency == "" {
+		account.QuoteCurrency = "USD"
+	}
+	if account.InitialValue == 0 {
+		account.InitialValue = defaultPaperBalance
+	}
+	if err := h.db.CreatePaperAccount(account); err != nil {
+		respondDBError(c, err, "Erreur lors de la création du compte")
+		return
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *Handler) ListPaperAccounts(c *gin.Context) {
+	accounts, err := h.db.GetPaperAccounts()
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des comptes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"accounts": accounts,
+		"count":    len(accounts),
+	})
+}
+
+// GetPaperAccount returns the balances of an account valued in its quote
+// currency with the latest stored prices, and its P&L since creation.
+func (h *Handler) GetPaperAccount(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	account, err := h.db.GetPaperAccount(id)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération du compte")
+		return
+	}
+	amounts, err := h.db.GetPaperBalances(id)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des soldes")
+		return
+	}
+	open, err := h.db.GetPaperOrders(id, paper.StatusOpen)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des ordres")
+		return
+	}
+	// The engine values reservations like its balance checks: with the fee,
+	// and open market orders at the last stored price.
+	reserved, err := h.paper.Reserved(id)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des ordres")
+		return
+	}
+	pricer, err := portfolio.NewPricer(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prix"})
+		return
+	}
+
+	balances := make([]paperBalance, 0, len(amounts))
+	equity := 0.0
+	complete := true
+	for asset, amount := range amounts {
+		b := paperBalance{Asset: asset, Amount: amount, Reserved: reserved[asset]}
+		if price, _, err := pricer.Price(asset, account.QuoteCurrency); err == nil {
+			b.Price = price
+			b.Value = amount * price
+			b.Priced = true
+			equity += b.Value
+		} else if amount != 0 {
+			complete = false
+		}
+		balances = append(balances, b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Value > balances[j].Value
+	})
+
+	pnl := equity - account.InitialValue
+	pnlPercent := 0.0
+	if account.InitialValue > 0 {
+		pnlPercent = pnl / account.InitialValue * 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account":     account,
+		"balances":    balances,
+		"equity":      equity,
+		"pnl":         pnl,
+		"pnl_percent": pnlPercent,
+		"complete":    complete,
+		"open_orders": len(open),
+	})
+}
+
+func (h *Handler) GetPaperFills(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.db.GetPaperAccount(id); err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération du compte")
+		return
+	}
+	fills, err := h.db.GetPaperFills(id)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des exécutions")
+		return
+	}
+
+	fees := 0.0
+	for _, f := range fills {
+		fees += f.Fee
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"fills":      fills,
+		"count":      len(fills),
+		"total_fees": fees,
+	})
+}
+
+func (h *Handler) CreatePaperOrder(c *gin.Context) {
+	var req paperOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Type == "" {
+		req.Type = paper.TypeMarket
+	}
+
+	order, err := h.paper.PlaceOrder(paper.OrderRequest{
+		AccountID:  req.AccountID,
+		Pair:       req.Pair,
+		Side:       req.Side,
+		Type:       req.Type,
+		Quantity:   req.Quantity,
+		LimitPrice: req.LimitPrice,
+		StopPrice:  req.StopPrice,
+	})
+	if err != nil {
+		respondPaperError(c, err, "Erreur lors de la création de l'ordre")
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *Handler) ListPaperOrders(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Query("account_id"), 10, 64)
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id invalide"})
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", paper.StatusOpen, paper.StatusFilled, paper.StatusCanceled, paper.StatusRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status doit être open, filled, canceled ou rejected"})
+		return
+	}
+
+	orders, err := h.db.GetPaperOrders(accountID, status)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des ordres")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"orders": orders,
+		"count":  len(orders),
+	})
+}
+
+func (h *Handler) CancelPaperOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	order, err := h.paper.CancelOrder(id)
+	if err != nil {
+		respondPaperError(c, err, "Erreur lors de l'annulation de l'ordre")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
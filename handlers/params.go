@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/gin-gonic/gin"
+)
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("date invalide: %s (formats acceptés: RFC3339, YYYY-MM-DD, timestamp Unix)", s)
+}
+
+// parseTimeRange reads the from/to query parameters, falling back to a
+// window (e.g. "24h", "7d") ending at to, or now when to is absent.
+func parseTimeRange(c *gin.Context, defaultWindow time.Duration) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := parseTime(toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := parseTime(fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if !from.Before(to) {
+			return time.Time{}, time.Time{}, fmt.Errorf("from doit être antérieur à to")
+		}
+		return from, to, nil
+	}
+
+	window := defaultWindow
+	if windowStr := c.Query("window"); windowStr != "" {
+		w, err := candles.ParseDuration(windowStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if w <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("window doit être positive")
+		}
+		window = w
+	}
+
+	return to.Add(-window), to, nil
+}
+
+func parsePositiveInt(c *gin.Context, name string, def int) (int, error) {
+	s := c.Query(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s doit être un entier positif", name)
+	}
+	return n, nil
+}
+
+// parseBoundedInt is parsePositiveInt with an upper bound, for the
+// parameters that size a query or an upstream request.
+func parseBoundedInt(c *gin.Context, name string, def, max int) (int, error) {
+	s := c.Query(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n > max {
+		return 0, fmt.Errorf("%s doit être un entier entre 1 et %d", name, max)
+	}
+	return n, nil
+}
+
+func parseOptionalDuration(c *gin.Context, name string) (time.Duration, error) {
+	s := c.Query(name)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := candles.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s doit être une durée positive", name)
+	}
+	return d, nil
+}
+
+// parseCandleInterval reads the interval query parameter. Stored candles are
+// 5 minutes wide, so shorter intervals are rejected.
+func parseCandleInterval(c *gin.Context, def string) (time.Duration, string, error) {
+	s := c.DefaultQuery("interval", def)
+	d, err := candles.ParseDuration(s)
+	if err != nil {
+		return 0, "", err
+	}
+	if d < candles.BaseInterval {
+		return 0, "", fmt.Errorf("interval invalide: %s (minimum 5m)", s)
+	}
+	return d, s, nil
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/antonyloussararian/Go-CryptoPrice/portfolio"
+	"github.com/gin-gonic/gin"
+)
+
+type portfolioRequest struct {
+	Name         string `json:"name" binding:"required"`
+	BaseCurrency string `json:"base_currency"`
+}
+
+type holdingRequest struct {
+	Asset     string  `json:"asset"`
+	Quantity  float64 `json:"quantity" binding:"gte=0"`
+	CostBasis float64 `json:"cost_basis" binding:"gte=0"`
+}
+
+func parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " invalide"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondDBError maps the database sentinel errors to HTTP statuses.
+func respondDBError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, database.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		slog.ErrorContext(c.Request.Context(), message, logging.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *Handler) loadPortfolio(c *gin.Context) (*models.Portfolio, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+	p, err := h.db.GetPortfolio(id)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération du portefeuille")
+		return nil, false
+	}
+	return p, true
+}
+
+func (h *Handler) CreatePortfolio(c *gin.Context) {
+	var req portfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p := &models.Portfolio{Name: req.Name, BaseCurrency: assets.Normalize(req.BaseCurrency)}
+	if p.BaseCurrency == "" {
+		p.BaseCurrency = "USD"
+	}
+	if err := h.db.CreatePortfolio(p); err != nil {
+		respondDBError(c, err, "Erreur lors de la création du portefeuille")
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func (h *Handler) ListPortfolios(c *gin.Context) {
+	portfolios, err := h.db.GetPortfolios()
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des portefeuilles")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"portfolios": portfolios,
+		"count":      len(portfolios),
+	})
+}
+
+func (h *Handler) GetPortfolio(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	holdings, err := h.db.GetHoldings(p.ID)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des positions")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"portfolio": p,
+		"holdings":  holdings,
+	})
+}
+
+func (h *Handler) UpdatePortfolio(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	var req portfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p.Name = req.Name
+	if currency := assets.Normalize(req.BaseCurrency); currency != "" && currency != p.BaseCurrency {
+		// Imported ledgers dropped the movements of the base currency as
+		// cash: they would be missing from the lots in another currency.
+		txs, err := h.db.GetTransactions(p.ID)
+		if err != nil {
+			respondDBError(c, err, "Erreur lors de la récupération des transactions")
+			return
+		}
+		if len(txs) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "la devise de base ne peut plus changer: le portefeuille a des transactions"})
+			return
+		}
+		p.BaseCurrency = currency
+	}
+	if err := h.db.UpdatePortfolio(p); err != nil {
+		respondDBError(c, err, "Erreur lors de la mise à jour du portefeuille")
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) DeletePortfolio(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.db.DeletePortfolio(id); err != nil {
+		respondDBError(c, err, "Erreur lors de la suppression du portefeuille")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) CreateHolding(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	var req holdingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Asset) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "asset est obligatoire"})
+		return
+	}
+
+	holding := &models.Holding{
+		PortfolioID: p.ID,
+		Asset:       assets.Normalize(req.Asset),
+		Quantity:    req.Quantity,
+		CostBasis:   req.CostBasis,
+	}
+	if err := h.db.CreateHolding(holding); err != nil {
+		respondDBError(c, err, "Erreur lors de la création de la position")
+		return
+	}
+	c.JSON(http.StatusCreated, holding)
+}
+
+func (h *Handler) UpdateHolding(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	holdingID, ok := parseIDParam(c, "holding_id")
+	if !ok {
+		return
+	}
+	var req holdingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holding := &models.Holding{
+		ID:          holdingID,
+		PortfolioID: p.ID,
+		Quantity:    req.Quantity,
+		CostBasis:   req.CostBasis,
+	}
+	if err := h.db.UpdateHolding(holding); err != nil {
+		respondDBError(c, err, "Erreur lors de la mise à jour de la position")
+		return
+	}
+	// The request only carries the quantity and the cost basis.
+	holding, err := h.db.GetHolding(p.ID, holdingID)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération de la position")
+		return
+	}
+	c.JSON(http.StatusOK, holding)
+}
+
+func (h *Handler) DeleteHolding(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	holdingID, ok := parseIDParam(c, "holding_id")
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteHolding(p.ID, holdingID); err != nil {
+		respondDBError(c, err, "Erreur lors de la suppression de la position")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetPortfolioValuation(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	quote := assets.Normalize(c.DefaultQuery("quote", p.BaseCurrency))
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, _, err := parseCandleInterval(c, "1d")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holdings, err := h.db.GetHoldings(p.ID)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des positions")
+		return
+	}
+	pricer, err := portfolio.NewPricer(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prix"})
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio.Value(pricer, p, holdings, quote, from, to, interval))
+}
+
+type transactionRequest struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type" binding:"required"`
+	Asset    string    `json:"asset" binding:"required"`
+	Quantity float64   `json:"quantity" binding:"gt=0"`
+	Price    float64   `json:"price" binding:"gte=0"`
+	Quote    string    `json:"quote"`
+	Fee      float64   `json:"fee" binding:"gte=0"`
+	FeeAsset string    `json:"fee_asset"`
+	RefID    string    `json:"refid"`
+}
+
+func (h *Handler) CreateTransaction(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	var req transactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !portfolio.ValidTransactionType(req.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type doit être buy, sell, transfer_in, transfer_out ou fee"})
+		return
+	}
+	if req.Time.IsZero() {
+		req.Time = time.Now()
+	}
+
+	tx := models.Transaction{
+		PortfolioID: p.ID,
+		Time:        req.Time,
+		Type:        req.Type,
+		Asset:       assets.Normalize(req.Asset),
+		Quantity:    req.Quantity,
+		Price:       req.Price,
+		Quote:       assets.Normalize(req.Quote),
+		Fee:         req.Fee,
+		FeeAsset:    assets.Normalize(req.FeeAsset),
+		RefID:       req.RefID,
+		Source:      "manual",
+	}
+	txs := []models.Transaction{tx}
+	if _, err := h.db.SaveTransactions(txs); err != nil {
+		respondDBError(c, err, "Erreur lors de l'enregistrement de la transaction")
+		return
+	}
+	c.JSON(http.StatusCreated, txs[0])
+}
+
+func (h *Handler) ListTransactions(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	txs, err := h.db.GetTransactions(p.ID)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des transactions")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": txs,
+		"count":        len(txs),
+	})
+}
+
+func (h *Handler) DeleteTransaction(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	txID, ok := parseIDParam(c, "tx_id")
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteTransaction(p.ID, txID); err != nil {
+		respondDBError(c, err, "Erreur lors de la suppression de la transaction")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ImportTransactions accepts the CSV either as a multipart "file" field or
+// as the raw request body. Like recorded transactions, imported ones do not
+// change the holdings of the portfolio.
+func (h *Handler) ImportTransactions(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	var txs []models.Transaction
+	var err error
+	switch format := c.DefaultQuery("format", portfolio.FormatCSV); format {
+	case portfolio.FormatCSV:
+		txs, err = portfolio.ParseTransactionsCSV(body)
+	case portfolio.FormatKrakenLedger:
+		txs, err = portfolio.ParseKrakenLedgerCSV(body, p.BaseCurrency)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format doit être csv ou kraken"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range txs {
+		txs[i].PortfolioID = p.ID
+	}
+	imported, err := h.db.SaveTransactions(txs)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de l'import des transactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"parsed":   len(txs),
+		"imported": imported,
+		"skipped":  len(txs) - imported,
+	})
+}
+
+func (h *Handler) GetPortfolioLots(c *gin.Context) {
+	p, ok := h.loadPortfolio(c)
+	if !ok {
+		return
+	}
+	method, err := portfolio.ParseMethod(c.DefaultQuery("method", string(portfolio.FIFO)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	txs, err := h.db.GetTransactions(p.ID)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération des transactions")
+		return
+	}
+	pricer, err := portfolio.NewPricer(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prix"})
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio.ComputeLots(pricer, txs, method, p.BaseCurrency))
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// The base currency is locked once transactions exist: imported ledgers
+// dropped the cash movements of the currency they were imported in.
+func TestUpdatePortfolioBaseCurrency(t *testing.T) {
+	db := newTestDB(t)
+	r := newTestEngine(NewHandler(db, newFakeKraken(t, nil)))
+
+	p := &models.Portfolio{Name: "main", BaseCurrency: "USD"}
+	if err := db.CreatePortfolio(p); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/portfolios/" + strconv.FormatInt(p.ID, 10)
+	put := func(body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		return w.Code
+	}
+
+	if code := put(`{"name":"main","base_currency":"eur"}`); code != http.StatusOK {
+		t.Fatalf("change without transactions: status %d", code)
+	}
+	tx := models.Transaction{PortfolioID: p.ID, Time: time.Now(), Type: "buy", Asset: "BTC", Quantity: 1, Source: "manual"}
+	if _, err := db.SaveTransactions([]models.Transaction{tx}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"name":"renamed","base_currency":"USD"}`, http.StatusConflict},
+		{`{"name":"renamed","base_currency":"EUR"}`, http.StatusOK},
+		{`{"name":"renamed"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := put(tt.body); code != tt.status {
+			t.Errorf("PUT %s: status %d, want %d", tt.body, code, tt.status)
+		}
+	}
+	stored, err := db.GetPortfolio(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BaseCurrency != "EUR" || stored.Name != "renamed" {
+		t.Errorf("portfolio %+v", stored)
+	}
+}
+
+// The response of an update carries the whole stored holding.
+func TestUpdateHolding(t *testing.T) {
+	db := newTestDB(t)
+	r := newTestEngine(NewHandler(db, newFakeKraken(t, nil)))
+
+	p := &models.Portfolio{Name: "main", BaseCurrency: "USD"}
+	if err := db.CreatePortfolio(p); err != nil {
+		t.Fatal(err)
+	}
+	holding := &models.Holding{PortfolioID: p.ID, Asset: "BTC", Quantity: 1, CostBasis: 30000}
+	if err := db.CreateHolding(holding); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/portfolios/" + strconv.FormatInt(p.ID, 10) + "/holdings/"
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path+strconv.FormatInt(holding.ID, 10),
+		strings.NewReader(`{"quantity":2,"cost_basis":61000}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var got models.Holding
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != holding.ID || got.Asset != "BTC" || got.Quantity != 2 || got.CostBasis != 61000 ||
+		!got.CreatedAt.Equal(holding.CreatedAt) || got.UpdatedAt.Before(holding.UpdatedAt) {
+		t.Errorf("holding %+v, created %v", got, holding.CreatedAt)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path+"999", strings.NewReader(`{"quantity":2}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown holding: status %d", w.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/antonyloussararian/Go-CryptoPrice/priceindex"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultIndexQuote is the currency the reference prices are computed in
+// during collection.
+const defaultIndexQuote = "USD"
+
+var defaultIndexAssets = []string{"BTC", "ETH"}
+
+// SetIndexAssets sets the assets whose reference price is computed and
+// stored on every save cycle.
+func (h *Handler) SetIndexAssets(list []string) {
+	h.indexAssets = nil
+	for _, asset := range list {
+		if asset = strings.TrimSpace(asset); asset != "" {
+			h.indexAssets = append(h.indexAssets, assets.Normalize(asset))
+		}
+	}
+}
+
+// indexBuilder uses every enabled venue, and Kraken for the conversion
+// rates between quote currencies.
+func (h *Handler) indexBuilder() *priceindex.Builder {
+	venues := make([]exchange.Exchange, 0, len(h.exchanges))
+	for _, name := range h.exchangeNames() {
+		venues = append(venues, h.exchanges[name])
+	}
+	return priceindex.NewBuilder(venues, h.exchanges[database.SourceKraken])
+}
+
+// updateIndexes computes and stores the reference price of every index
+// asset at the candle time of the save cycle.
+func (h *Handler) updateIndexes(timestamp time.Time) {
+	builder := h.indexBuilder()
+	for _, asset := range h.indexAssets {
+		point, err := builder.Build(asset, defaultIndexQuote)
+		if err != nil {
+			slog.WarnContext(h.ctx, "échec du calcul de l'indice", "asset", asset, "quote", defaultIndexQuote, logging.Err(err))
+			continue
+		}
+		point.Timestamp = timestamp
+		if err := h.db.SaveIndexPoint(point); err != nil {
+			slog.ErrorContext(h.ctx, "échec de l'enregistrement de l'indice", "asset", asset, "quote", defaultIndexQuote, logging.Err(err))
+		}
+	}
+}
+
+type indexValue struct {
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	Used      int       `json:"used"`
+	Rejected  int       `json:"rejected"`
+}
+
+// GetPriceIndex returns the latest stored reference price of the asset with
+// its components, and the stored series over the requested range. With
+// live=true the index is computed from the current tickers instead of the
+// latest stored point; that response is cached like the Kraken proxies, and
+// the stored index is served, marked as stale, when no venue answers.
+func (h *Handler) GetPriceIndex(c *gin.Context) {
+	asset := assets.Normalize(c.Param("asset"))
+	quote := assets.Normalize(c.DefaultQuery("quote", defaultIndexQuote))
+	method, err := priceindex.ParseMethod(c.Query("method"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("live") == "true" {
+		key := asset + "|" + c.Request.URL.Query().Encode()
+		h.respondCached(c, cacheIndex, key, func(h *Handler) (any, error) {
+			latest, err := h.indexBuilder().Build(asset, quote)
+			if err != nil {
+				if errors.Is(err, priceindex.ErrNoComponents) {
+					return nil, fmt.Errorf("aucun prix disponible pour %s/%s: %w", asset, quote, err)
+				}
+				return nil, err
+			}
+			series, err := h.indexSeries(asset, quote, method, from, to)
+			if err != nil {
+				return nil, err
+			}
+			return indexResponse(asset, quote, method, latest, true, series), nil
+		}, func(h *Handler) (any, error) {
+			response, latest, err := h.storedIndex(asset, quote, method, from, to)
+			if err != nil {
+				return nil, err
+			}
+			response["stale"] = true
+			response["as_of"] = latest.Timestamp
+			return response, nil
+		})
+		return
+	}
+
+	response, _, err := h.storedIndex(asset, quote, method, from, to)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("aucun indice enregistré pour %s/%s", asset, quote)})
+			return
+		}
+		respondDBError(c, err, "Erreur lors de la récupération de l'indice")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// storedIndex builds the response of the latest stored index point.
+func (h *Handler) storedIndex(asset, quote, method string, from, to time.Time) (gin.H, *models.IndexPoint, error) {
+	series, err := h.indexSeries(asset, quote, method, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	latest, err := h.db.GetLatestIndexPointFromDB(asset, quote)
+	if err != nil {
+		return nil, nil, err
+	}
+	return indexResponse(asset, quote, method, latest, false, series), latest, nil
+}
+
+func (h *Handler) indexSeries(asset, quote, method string, from, to time.Time) ([]indexValue, error) {
+	points, err := h.db.GetIndexPointsFromDB(asset, quote, from, to, false)
+	if err != nil {
+		return nil, err
+	}
+	series := make([]indexValue, len(points))
+	for i, p := range points {
+		series[i] = indexValue{
+			Timestamp: p.Timestamp,
+			Price:     priceindex.Price(&p, method),
+			Used:      p.Used,
+			Rejected:  p.Rejected,
+		}
+	}
+	return series, nil
+}
+
+func indexResponse(asset, quote, method string, latest *models.IndexPoint, live bool, series []indexValue) gin.H {
+	return gin.H{
+		"asset":  asset,
+		"quote":  quote,
+		"method": method,
+		"price":  priceindex.Price(latest, method),
+		"latest": latest,
+		"live":   live,
+		"series": series,
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// scoped runs handle with a copy of the handler scoped to the request, so
+// that its database queries and Kraken calls are traced and logged under
+// the request.
+func (h *Handler) scoped(handle func(*Handler, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handle(h.withContext(c.Request.Context()), c)
+	}
+}
+
+// RegisterRoutes registers the API routes on r.
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/healthz", h.scoped((*Handler).Healthz))
+	r.GET("/readyz", h.scoped((*Handler).Readyz))
+
+	r.GET("/api/status", h.scoped((*Handler).GetServerStatus))
+	r.GET("/api/status/history", h.scoped((*Handler).GetStatusHistory))
+	r.GET("/api/pairs", h.scoped((*Handler).GetTradingPairs))
+	r.GET("/api/pairs/:pair", h.scoped((*Handler).GetPairInfo))
+	r.GET("/api/pairs/:pair/depth", h.scoped((*Handler).GetPairDepth))
+	r.GET("/api/pairs/:pair/depth/history", h.scoped((*Handler).GetPairDepthHistory))
+	r.GET("/api/pairs/:pair/trades", h.scoped((*Handler).GetPairTrades))
+	r.GET("/api/pairs/:pair/trades/volume", h.scoped((*Handler).GetPairTradeVolume))
+	r.GET("/api/pairs/:pair/trades/large", h.scoped((*Handler).GetPairLargeTrades))
+	r.GET("/api/pairs/:pair/vwap", h.scoped((*Handler).GetPairVWAP))
+	r.GET("/api/pairs/:pair/spread", h.scoped((*Handler).GetPairSpread))
+	r.GET("/api/pairs/:pair/indicators", h.scoped((*Handler).GetPairIndicators))
+	r.GET("/api/pairs/:pair/stats", h.scoped((*Handler).GetPairStats))
+	r.GET("/api/stats", h.scoped((*Handler).GetStats))
+	r.GET("/api/correlation", h.scoped((*Handler).GetCorrelation))
+
+	r.POST("/api/portfolios", h.scoped((*Handler).CreatePortfolio))
+	r.GET("/api/portfolios", h.scoped((*Handler).ListPortfolios))
+	r.GET("/api/portfolios/:id", h.scoped((*Handler).GetPortfolio))
+	r.PUT("/api/portfolios/:id", h.scoped((*Handler).UpdatePortfolio))
+	r.DELETE("/api/portfolios/:id", h.scoped((*Handler).DeletePortfolio))
+	r.POST("/api/portfolios/:id/holdings", h.scoped((*Handler).CreateHolding))
+	r.PUT("/api/portfolios/:id/holdings/:holding_id", h.scoped((*Handler).UpdateHolding))
+	r.DELETE("/api/portfolios/:id/holdings/:holding_id", h.scoped((*Handler).DeleteHolding))
+	r.GET("/api/portfolios/:id/valuation", h.scoped((*Handler).GetPortfolioValuation))
+	r.POST("/api/portfolios/:id/transactions", h.scoped((*Handler).CreateTransaction))
+	r.GET("/api/portfolios/:id/transactions", h.scoped((*Handler).ListTransactions))
+	r.DELETE("/api/portfolios/:id/transactions/:tx_id", h.scoped((*Handler).DeleteTransaction))
+	r.POST("/api/portfolios/:id/transactions/import", h.scoped((*Handler).ImportTransactions))
+	r.GET("/api/portfolios/:id/lots", h.scoped((*Handler).GetPortfolioLots))
+
+	r.GET("/api/backtests/strategies", h.scoped((*Handler).ListBacktestStrategies))
+	r.POST("/api/backtests", h.scoped((*Handler).RunBacktest))
+	r.POST("/api/paper/accounts", h.scoped((*Handler).CreatePaperAccount))
+	r.GET("/api/paper/accounts", h.scoped((*Handler).ListPaperAccounts))
+	r.GET("/api/paper/accounts/:id", h.scoped((*Handler).GetPaperAccount))
+	r.GET("/api/paper/accounts/:id/fills", h.scoped((*Handler).GetPaperFills))
+	r.POST("/api/paper/orders", h.scoped((*Handler).CreatePaperOrder))
+	r.GET("/api/paper/orders", h.scoped((*Handler).ListPaperOrders))
+	r.DELETE("/api/paper/orders/:id", h.scoped((*Handler).CancelPaperOrder))
+	r.POST("/api/account/sync", h.scoped((*Handler).SyncAccount))
+	r.GET("/api/account/balances", h.scoped((*Handler).GetAccountBalances))
+	r.GET("/api/account/history", h.scoped((*Handler).GetAccountHistory))
+	r.GET("/api/account/ledger", h.scoped((*Handler).GetAccountLedger))
+	r.GET("/api/account/reconciliation", h.scoped((*Handler).GetAccountReconciliation))
+	r.GET("/api/exchanges", h.scoped((*Handler).ListExchanges))
+	r.GET("/api/exchanges/:exchange/markets", h.scoped((*Handler).GetExchangeMarkets))
+	r.GET("/api/exchanges/:exchange/ticker", h.scoped((*Handler).GetExchangeTicker))
+	r.GET("/api/exchanges/:exchange/ohlc", h.scoped((*Handler).GetExchangeOHLC))
+	r.GET("/api/exchanges/:exchange/depth", h.scoped((*Handler).GetExchangeDepth))
+	r.GET("/api/exchanges/:exchange/trades", h.scoped((*Handler).GetExchangeTrades))
+	r.GET("/api/compare/:asset", h.scoped((*Handler).ComparePrices))
+	r.GET("/api/compare/:asset/history", h.scoped((*Handler).GetCompareHistory))
+	r.GET("/api/compare/:asset/alerts", h.scoped((*Handler).GetSpreadAlerts))
+	r.GET("/api/index/:asset", h.scoped((*Handler).GetPriceIndex))
+	r.GET("/api/historical", h.scoped((*Handler).DownloadHistoricalData))
+	r.GET("/api/db", h.scoped((*Handler).GetDBData))
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetPairSpread(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	infos, err := h.db.GetPairInfoByNameFromDB(pair, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des spreads"})
+		return
+	}
+
+	series := make([]models.SpreadPoint, 0, len(infos))
+	var sumBps float64
+	minBps, maxBps := math.Inf(1), math.Inf(-1)
+	for _, info := range infos {
+		// Rows saved before bid/ask were collected have zeroes for both.
+		if info.Bid <= 0 || info.Ask <= 0 {
+			continue
+		}
+		mid := (info.Bid + info.Ask) / 2
+		p := models.SpreadPoint{
+			Timestamp: info.Timestamp,
+			Bid:       info.Bid,
+			Ask:       info.Ask,
+			Mid:       mid,
+			Spread:    info.Ask - info.Bid,
+			SpreadBps: (info.Ask - info.Bid) / mid * 10000,
+		}
+		series = append(series, p)
+		sumBps += p.SpreadBps
+		minBps = math.Min(minBps, p.SpreadBps)
+		maxBps = math.Max(maxBps, p.SpreadBps)
+	}
+
+	response := gin.H{
+		"pair":   pair,
+		"from":   from,
+		"to":     to,
+		"series": series,
+		"count":  len(series),
+	}
+	if len(series) > 0 {
+		response["summary"] = gin.H{
+			"avg_spread_bps":    sumBps / float64(len(series)),
+			"min_spread_bps":    minBps,
+			"max_spread_bps":    maxBps,
+			"latest_spread_bps": series[len(series)-1].SpreadBps,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
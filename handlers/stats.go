@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/stats"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatsPairs bounds the pairs of one /api/stats request.
+const maxStatsPairs = 20
+
+var standardLookbacks = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// pairStats returns nil when the pair has no stored candle between from and
+// to.
+func (h *Handler) pairStats(pair string, from, to time.Time, interval time.Duration) (gin.H, error) {
+	longest := to.Add(-standardLookbacks["30d"])
+	loadFrom := from
+	if longest.Before(loadFrom) {
+		loadFrom = longest
+	}
+
+	data, err := h.db.GetCandlesFromDB(pair, loadFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	all := candles.FromHistorical(data)
+
+	var window []candles.Candle
+	for _, c := range all {
+		if !c.Time.Before(from) {
+			window = append(window, c)
+		}
+	}
+	if len(window) == 0 {
+		return nil, nil
+	}
+
+	return gin.H{
+		"summary":         stats.Summarize(candles.Resample(window, interval), interval),
+		"percent_changes": stats.PercentChanges(all, standardLookbacks),
+	}, nil
+}
+
+func (h *Handler) GetPairStats(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, intervalStr, err := parseCandleInterval(c, "1h")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.pairStats(pair, from, to, interval)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors du calcul des statistiques")
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("aucune bougie pour %s sur la période demandée", pair)})
+		return
+	}
+
+	result["pair"] = pair
+	result["interval"] = intervalStr
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) GetStats(c *gin.Context) {
+	var pairs []string
+	for _, p := range strings.Split(c.Query("pairs"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pairs = append(pairs, p)
+		}
+	}
+	if len(pairs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pairs parameter is required"})
+		return
+	}
+	if len(pairs) > maxStatsPairs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("au plus %d paires dans pairs", maxStatsPairs)})
+		return
+	}
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, intervalStr, err := parseCandleInterval(c, "1h")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		result, err := h.pairStats(pair, from, to, interval)
+		if err != nil {
+			respondDBError(c, err, "Erreur lors du calcul des statistiques")
+			return
+		}
+		if result == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("aucune bougie pour %s sur la période demandée", pair)})
+			return
+		}
+		results[pair] = result
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interval": intervalStr,
+		"from":     from,
+		"to":       to,
+		"pairs":    results,
+		"count":    len(results),
+	})
+}
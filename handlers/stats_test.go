@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func TestStatsStatus(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC().Truncate(time.Hour)
+	pair := &models.TradingPair{Name: "XXBTZUSD", Base: "XXBT", Quote: "ZUSD", LastUpdated: now}
+	if err := db.SaveTradingPair(pair); err != nil {
+		t.Fatal(err)
+	}
+	var data []models.HistoricalData
+	for i := 0; i < 24; i++ {
+		price := 67000 + float64(i%5)*10
+		data = append(data, models.HistoricalData{PairID: pair.ID, Timestamp: now.Add(time.Duration(i-24) * time.Hour),
+			Open: price, High: price, Low: price, Close: price})
+	}
+	if err := db.SaveHistoricalDataBatch(data); err != nil {
+		t.Fatal(err)
+	}
+	r := newTestEngine(NewHandler(db, newFakeKraken(t, nil)))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/pairs/XXBTZUSD/stats", http.StatusOK},
+		{"/api/pairs/UNKNOWN/stats", http.StatusNotFound},
+		{"/api/stats?pairs=XXBTZUSD", http.StatusOK},
+		{"/api/stats?pairs=XXBTZUSD,UNKNOWN", http.StatusNotFound},
+		{"/api/stats?pairs=,", http.StatusBadRequest},
+		{"/api/stats?pairs=" + strings.Repeat("XXBTZUSD,", maxStatsPairs+1), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("GET %s: status %d, want %d: %s", tt.path, w.Code, tt.status, w.Body)
+		}
+	}
+}
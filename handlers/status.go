@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+// statusPeriod is a run of consecutive checks with the same Kraken status.
+type statusPeriod struct {
+	Status          string    `json:"status"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Checks          int       `json:"checks"`
+}
+
+type clockSkewStats struct {
+	Latest float64 `json:"latest"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+// statusTimeline groups the checks into periods between from and to. The
+// check made before from, if any, gives the status at the start of the
+// range; the last period ends at to, or now when to is in the future.
+func statusTimeline(before *models.ServerStatus, checks []models.ServerStatus, from, to time.Time) []statusPeriod {
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+
+	var periods []statusPeriod
+	add := func(status string, start time.Time) {
+		if n := len(periods); n > 0 && periods[n-1].Status == status {
+			periods[n-1].Checks++
+			return
+		}
+		periods = append(periods, statusPeriod{Status: status, Start: start, Checks: 1})
+	}
+	if before != nil {
+		add(before.Status, from)
+		periods[0].Checks = 0
+	}
+	for _, c := range checks {
+		add(c.Status, c.Timestamp)
+	}
+
+	for i := range periods {
+		end := to
+		if i+1 < len(periods) {
+			end = periods[i+1].Start
+		}
+		periods[i].End = end
+		periods[i].DurationSeconds = end.Sub(periods[i].Start).Seconds()
+	}
+	return periods
+}
+
+func clockSkewSummary(checks []models.ServerStatus) *clockSkewStats {
+	var stats *clockSkewStats
+	var sum float64
+	var n int
+	for _, c := range checks {
+		if c.ClockSkewMs == nil {
+			continue
+		}
+		skew := *c.ClockSkewMs
+		if stats == nil {
+			stats = &clockSkewStats{Min: skew, Max: skew}
+		}
+		stats.Latest = skew
+		stats.Min = math.Min(stats.Min, skew)
+		stats.Max = math.Max(stats.Max, skew)
+		sum += skew
+		n++
+	}
+	if stats != nil {
+		stats.Mean = sum / float64(n)
+	}
+	return stats
+}
+
+// GetStatusHistory returns the timeline of Kraken's status over the
+// requested range (24 hours by default): the periods spent in each status,
+// the transitions, the total time per status and the measured clock skew.
+func (h *Handler) GetStatusHistory(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, err := h.db.GetLatestServerStatusFromDB(from)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		respondDBError(c, err, "Erreur lors de la récupération de l'historique du statut")
+		return
+	}
+	checks, err := h.db.GetServerStatusesFromDB(from, to)
+	if err != nil {
+		respondDBError(c, err, "Erreur lors de la récupération de l'historique du statut")
+		return
+	}
+
+	transitions := []models.ServerStatus{}
+	for _, s := range checks {
+		if s.PreviousStatus != "" {
+			transitions = append(transitions, s)
+		}
+	}
+	timeline := statusTimeline(before, checks, from, to)
+	durations := map[string]float64{}
+	for _, p := range timeline {
+		durations[p.Status] += p.DurationSeconds
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":              from,
+		"to":                to,
+		"checks":            len(checks),
+		"timeline":          timeline,
+		"transitions":       transitions,
+		"durations_seconds": durations,
+		"clock_skew_ms":     clockSkewSummary(checks),
+	})
+}
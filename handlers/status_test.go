@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func TestStatusTimeline(t *testing.T) {
+	from := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	check := func(minutes int, status string) models.ServerStatus {
+		return models.ServerStatus{Timestamp: from.Add(time.Duration(minutes) * time.Minute), Status: status}
+	}
+	period := func(status string, start, end, checks int) statusPeriod {
+		return statusPeriod{
+			Status:          status,
+			Start:           from.Add(time.Duration(start) * time.Minute),
+			End:             from.Add(time.Duration(end) * time.Minute),
+			DurationSeconds: float64((end - start) * 60),
+			Checks:          checks,
+		}
+	}
+	online := check(-5, "online")
+	transitions := []models.ServerStatus{check(10, "online"), check(30, "maintenance"), check(40, "maintenance"), check(50, "online")}
+
+	tests := []struct {
+		name   string
+		before *models.ServerStatus
+		checks []models.ServerStatus
+		want   []statusPeriod
+	}{
+		// The status checked before from holds from the start of the range,
+		// without counting as one of its checks.
+		{"carried over", &online, transitions, []statusPeriod{
+			period("online", 0, 30, 1),
+			period("maintenance", 30, 50, 2),
+			period("online", 50, 60, 1),
+		}},
+		{"no earlier check", nil, transitions, []statusPeriod{
+			period("online", 10, 30, 1),
+			period("maintenance", 30, 50, 2),
+			period("online", 50, 60, 1),
+		}},
+		{"carried over with a transition", &online, []models.ServerStatus{check(20, "cancel_only")}, []statusPeriod{
+			period("online", 0, 20, 0),
+			period("cancel_only", 20, 60, 1),
+		}},
+		{"no check in the range", &online, nil, []statusPeriod{period("online", 0, 60, 0)}},
+		{"no check at all", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		if got := statusTimeline(tt.before, tt.checks, from, to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A range ending in the future stops now.
+func TestStatusTimelineFutureEnd(t *testing.T) {
+	start := time.Now()
+	from := start.Add(-time.Hour)
+	checks := []models.ServerStatus{{Timestamp: from.Add(10 * time.Minute), Status: "online"}}
+
+	periods := statusTimeline(nil, checks, from, start.Add(time.Hour))
+	end := time.Now()
+	if len(periods) != 1 {
+		t.Fatalf("periods %+v", periods)
+	}
+	p := periods[0]
+	if p.End.Before(start) || p.End.After(end) {
+		t.Errorf("end %v, want between %v and %v", p.End, start, end)
+	}
+	if want := p.End.Sub(p.Start).Seconds(); p.DurationSeconds != want || want > 50*60+1 {
+		t.Errorf("duration %v s, want %v s", p.DurationSeconds, want)
+	}
+}
+
+func TestClockSkewSummary(t *testing.T) {
+	skews := func(values ...any) []models.ServerStatus {
+		checks := make([]models.ServerStatus, len(values))
+		for i, v := range values {
+			if f, ok := v.(float64); ok {
+				checks[i].ClockSkewMs = &f
+			}
+		}
+		return checks
+	}
+
+	tests := []struct {
+		name   string
+		checks []models.ServerStatus
+		want   *clockSkewStats
+	}{
+		{"no checks", nil, nil},
+		{"never measured", skews(nil, nil), nil},
+		// The latest measured skew, not the latest check.
+		{"mixed", skews(5.0, nil, -3.0, 10.0, nil), &clockSkewStats{Latest: 10, Min: -3, Max: 10, Mean: 4}},
+		{"single", skews(nil, 2.5), &clockSkewStats{Latest: 2.5, Min: 2.5, Max: 2.5, Mean: 2.5}},
+	}
+	for _, tt := range tests {
+		if got := clockSkewSummary(tt.checks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
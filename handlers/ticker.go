@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func tickerField(data map[string]any, key string, index int) float64 {
+	var raw any = data[key]
+	if values, ok := raw.([]any); ok {
+		if index >= len(values) {
+			return 0
+		}
+		raw = values[index]
+	}
+
+	switch v := raw.(type) {
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// parseTicker maps a Kraken Ticker entry onto a PairInfo. The 24h figures
+// are the second element of the v, p, t, h and l arrays.
+func parseTicker(data map[string]any) models.PairInfo {
+	return models.PairInfo{
+		Price:           tickerField(data, "c", 0),
+		LastTradeVolume: tickerField(data, "c", 1),
+		Ask:             tickerField(data, "a", 0),
+		Bid:             tickerField(data, "b", 0),
+		Volume24h:       tickerField(data, "v", 1),
+		VWAP24h:         tickerField(data, "p", 1),
+		TradeCount24h:   int64(tickerField(data, "t", 1)),
+		High24h:         tickerField(data, "h", 1),
+		Low24h:          tickerField(data, "l", 1),
+		Open:            tickerField(data, "o", 0),
+	}
+}
+
+// formatTicker is the reverse of parseTicker, for the stored tickers served
+// in place of Kraken's. Only the 24h figures are stored, so they also stand
+// for today's, and the ask and bid carry no lot volume.
+func formatTicker(info models.PairInfo) map[string]any {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	both := func(v float64) []string { return []string{format(v), format(v)} }
+	return map[string]any{
+		"a": []string{format(info.Ask)},
+		"b": []string{format(info.Bid)},
+		"c": []string{format(info.Price), format(info.LastTradeVolume)},
+		"v": both(info.Volume24h),
+		"p": both(info.VWAP24h),
+		"t": []int64{info.TradeCount24h, info.TradeCount24h},
+		"l": both(info.Low24h),
+		"h": both(info.High24h),
+		"o": format(info.Open),
+	}
+}
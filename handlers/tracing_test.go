@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestSpans(t *testing.T) {
+	exporter := tracing.SetupInMemory()
+	client := newFakeKraken(t, map[string]string{
+		"AssetPairs": assetPairsBody,
+		"Depth":      `{"error":[],"result":{"XXBTZUSD":{"asks":[["67005.1","1.5",1718020803]],"bids":[["67005.0","3.1",1718020804]]}}}`,
+	})
+	binanceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/exchangeInfo":
+			w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT"}]}`))
+		case "/depth":
+			w.Write([]byte(`{"bids":[["67001.00","2.31"]],"asks":[["67001.01","0.405"]]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(binanceServer.Close)
+	binance := exchange.NewBinance()
+	binance.SetBaseURL(binanceServer.URL)
+
+	h := NewHandler(newTestDB(t), client)
+	h.AddExchange(binance)
+	r := newTestEngine(h)
+
+	tests := []struct {
+		path     string
+		server   string
+		children []string
+	}{
+		{"/api/pairs/XXBTZUSD/trades", "GET /api/pairs/:pair/trades", []string{"select trades"}},
+		{"/api/pairs/XXBTZUSD/depth", "GET /api/pairs/:pair/depth", []string{"kraken public/Depth"}},
+		{"/api/status/history", "GET /api/status/history", []string{"select server_status", "select server_status"}},
+		// Venue adapters carry the request context, markets loads included.
+		{"/api/exchanges/kraken/depth?symbol=BTC/USD", "GET /api/exchanges/:exchange/depth", []string{"kraken public/AssetPairs", "kraken public/Depth"}},
+		{"/api/exchanges/binance/depth?symbol=BTC/USDT", "GET /api/exchanges/:exchange/depth", []string{"HTTP GET", "HTTP GET"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			exporter.Reset()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+
+			spans := exporter.GetSpans()
+			var server *tracetest.SpanStub
+			for i := range spans {
+				if spans[i].Name == tt.server {
+					server = &spans[i]
+				}
+			}
+			if server == nil {
+				t.Fatalf("no span %q in %v", tt.server, spanNames(spans))
+			}
+			if server.Parent.IsValid() {
+				t.Errorf("server span has a parent %s", server.Parent.SpanID())
+			}
+
+			var children []string
+			for _, s := range spans {
+				if s.Parent.SpanID() == server.SpanContext.SpanID() {
+					children = append(children, s.Name)
+					if s.SpanContext.TraceID() != server.SpanContext.TraceID() {
+						t.Errorf("span %q is in another trace", s.Name)
+					}
+				}
+			}
+			if strings.Join(children, ",") != strings.Join(tt.children, ",") {
+				t.Errorf("children of %q = %v, want %v (all spans: %v)", tt.server, children, tt.children, spanNames(spans))
+			}
+		})
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+		if s.Parent.IsValid() {
+			names[i] += " (child of " + s.Parent.SpanID().String() + ")"
+		}
+	}
+	return names
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tradesPageSize       = 1000
+	maxTradePagesPerRun  = 5
+	maxTradesLimit       = 10000
+	defaultTradesWindow  = 24 * time.Hour
+	defaultLargeMultiple = 10.0
+)
+
+func toTrade(t kraken.Trade) models.Trade {
+	side := "buy"
+	if t.Side == "s" {
+		side = "sell"
+	}
+	orderType := "market"
+	if t.OrderType == "l" {
+		orderType = "limit"
+	}
+	return models.Trade{
+		TradeID:   t.TradeID,
+		Price:     t.Price,
+		Volume:    t.Volume,
+		Side:      side,
+		OrderType: orderType,
+		Misc:      t.Misc,
+		Timestamp: t.Time,
+	}
+}
+
+// collectTrades pages through /public/Trades from the stored cursor until it
+// catches up, so consecutive cycles never leave a gap between them.
+func (h *Handler) collectTrades(pair string) (int, error) {
+	cursor, err := h.db.GetTradeCursor(pair)
+	if err != nil {
+		return 0, err
+	}
+
+	saved := 0
+	for page := 0; page < maxTradePagesPerRun; page++ {
+		result, err := h.client.GetRecentTrades(pair, cursor)
+		if err != nil {
+			return saved, err
+		}
+
+		trades := make([]models.Trade, 0, len(result.Trades))
+		for _, t := range result.Trades {
+			trades = append(trades, toTrade(t))
+		}
+
+		if err := h.db.SaveTradesBatch(pair, trades, result.Last); err != nil {
+			return saved, err
+		}
+		saved += len(trades)
+
+		if len(result.Trades) < tradesPageSize || result.Last == cursor {
+			break
+		}
+		cursor = result.Last
+	}
+
+	return saved, nil
+}
+
+func (h *Handler) GetPairTrades(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, defaultTradesWindow)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := parseBoundedInt(c, "limit", 1000, maxTradesLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trades, err := h.db.GetTradesFromDB(pair, from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":   pair,
+		"from":   from,
+		"to":     to,
+		"trades": trades,
+		"count":  len(trades),
+	})
+}
+
+type sideVolume struct {
+	BuyVolume    float64 `json:"buy_volume"`
+	SellVolume   float64 `json:"sell_volume"`
+	BuyCount     int     `json:"buy_count"`
+	SellCount    int     `json:"sell_count"`
+	BuyNotional  float64 `json:"buy_notional"`
+	SellNotional float64 `json:"sell_notional"`
+	BuyRatio     float64 `json:"buy_ratio"`
+}
+
+func (v *sideVolume) add(a models.TradeAggregate) {
+	if a.Side == "sell" {
+		v.SellVolume += a.Volume
+		v.SellNotional += a.Notional
+		v.SellCount += a.Count
+	} else {
+		v.BuyVolume += a.Volume
+		v.BuyNotional += a.Notional
+		v.BuyCount += a.Count
+	}
+	if total := v.BuyVolume + v.SellVolume; total > 0 {
+		v.BuyRatio = v.BuyVolume / total
+	}
+}
+
+// parseTradeBucket reads the bucket query parameter. Trades are bucketed by
+// SQLite on whole seconds.
+func parseTradeBucket(c *gin.Context) (time.Duration, error) {
+	bucket, err := parseOptionalDuration(c, "bucket")
+	if err != nil {
+		return 0, err
+	}
+	if bucket > 0 && bucket%time.Second != 0 {
+		return 0, fmt.Errorf("bucket doit être un nombre entier de secondes")
+	}
+	return bucket, nil
+}
+
+func (h *Handler) GetPairTradeVolume(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, defaultTradesWindow)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := parseTradeBucket(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	aggregates, err := h.db.GetTradeAggregates(pair, from, to, bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des transactions"})
+		return
+	}
+
+	type bucketVolume struct {
+		Start time.Time `json:"start"`
+		sideVolume
+	}
+	var total sideVolume
+	var series []bucketVolume
+	for _, a := range aggregates {
+		total.add(a)
+		if bucket > 0 {
+			// Aggregates are sorted by bucket, then by side.
+			if n := len(series); n == 0 || !series[n-1].Start.Equal(a.Start) {
+				series = append(series, bucketVolume{Start: a.Start})
+			}
+			series[len(series)-1].add(a)
+		}
+	}
+
+	response := gin.H{
+		"pair":  pair,
+		"from":  from,
+		"to":    to,
+		"total": total,
+	}
+	if bucket > 0 {
+		if series == nil {
+			series = []bucketVolume{}
+		}
+		response["buckets"] = series
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetPairLargeTrades(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, defaultTradesWindow)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := parseBoundedInt(c, "limit", 1000, maxTradesLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var threshold float64
+	if minStr := c.Query("min_volume"); minStr != "" {
+		threshold, err = strconv.ParseFloat(minStr, 64)
+		if err != nil || threshold <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_volume doit être un nombre positif"})
+			return
+		}
+	} else {
+		multiple := defaultLargeMultiple
+		if multipleStr := c.Query("multiple"); multipleStr != "" {
+			multiple, err = strconv.ParseFloat(multipleStr, 64)
+			if err != nil || multiple <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "multiple doit être un nombre positif"})
+				return
+			}
+		}
+		aggregates, err := h.db.GetTradeAggregates(pair, from, to, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des transactions"})
+			return
+		}
+		var sum float64
+		var count int
+		for _, a := range aggregates {
+			sum += a.Volume
+			count += a.Count
+		}
+		if count > 0 {
+			threshold = multiple * sum / float64(count)
+		}
+	}
+
+	large, err := h.db.GetLargeTradesFromDB(pair, from, to, threshold, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des transactions"})
+		return
+	}
+	if large == nil {
+		large = []models.Trade{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pair":       pair,
+		"from":       from,
+		"to":         to,
+		"min_volume": threshold,
+		"trades":     large,
+		"count":      len(large),
+	})
+}
+
+type vwapPoint struct {
+	Start  time.Time `json:"start"`
+	VWAP   float64   `json:"vwap"`
+	Volume float64   `json:"volume"`
+	Trades int       `json:"trades"`
+}
+
+func (h *Handler) GetPairVWAP(c *gin.Context) {
+	pair := c.Param("pair")
+	from, to, err := parseTimeRange(c, defaultTradesWindow)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := parseTradeBucket(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	aggregates, err := h.db.GetTradeAggregates(pair, from, to, bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des transactions"})
+		return
+	}
+	if len(aggregates) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "aucune transaction sur la période demandée"})
+		return
+	}
+
+	var notional, volume float64
+	var count int
+	var series []vwapPoint
+	for _, a := range aggregates {
+		notional += a.Notional
+		volume += a.Volume
+		count += a.Count
+		if bucket > 0 {
+			if n := len(series); n == 0 || !series[n-1].Start.Equal(a.Start) {
+				series = append(series, vwapPoint{Start: a.Start})
+			}
+			// VWAP holds the notional until the series is finalised below.
+			p := &series[len(series)-1]
+			p.VWAP += a.Notional
+			p.Volume += a.Volume
+			p.Trades += a.Count
+		}
+	}
+
+	var vwap float64
+	if volume > 0 {
+		vwap = notional / volume
+	}
+
+	response := gin.H{
+		"pair":   pair,
+		"from":   from,
+		"to":     to,
+		"vwap":   vwap,
+		"volume": volume,
+		"trades": count,
+	}
+	if bucket > 0 {
+		for i := range series {
+			if series[i].Volume > 0 {
+				series[i].VWAP /= series[i].Volume
+			}
+		}
+		response["buckets"] = series
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+)
+
+// tradesBody is a /public/Trades page of count trades with consecutive ids
+// starting at first.
+func tradesBody(first, count int, last string) string {
+	rows := make([]string, count)
+	for i := range rows {
+		id := first + i
+		rows[i] = fmt.Sprintf(`["67000.%d","0.01",%d.5,"b","m","",%d]`, id%10, 1718020800+id, id)
+	}
+	return fmt.Sprintf(`{"error":[],"result":{"XXBTZUSD":[%s],"last":"%s"}}`, strings.Join(rows, ","), last)
+}
+
+// The cursor only advances with the trades it covers, so a failed cycle is
+// resumed from where the last stored page ended.
+func TestCollectTradesCursor(t *testing.T) {
+	pages := map[string]string{
+		"":    tradesBody(1, 2, "100"),
+		"100": tradesBody(3, 1, "200"),
+		// A full page is followed by the next one in the same cycle.
+		"200": tradesBody(4, tradesPageSize, "300"),
+		"300": tradesBody(4+tradesPageSize, 1, "400"),
+	}
+	var requested []string
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		since := r.URL.Query().Get("since")
+		requested = append(requested, since)
+		if failing {
+			fmt.Fprint(w, `{"error":["EService:Unavailable"]}`)
+			return
+		}
+		fmt.Fprint(w, pages[since])
+	}))
+	t.Cleanup(server.Close)
+	client := kraken.NewClient()
+	client.SetBaseURL(server.URL + "/0")
+
+	db := newTestDB(t)
+	h := NewHandler(db, client)
+
+	steps := []struct {
+		name      string
+		failing   bool
+		saved     int
+		requested []string
+		cursor    string
+	}{
+		{"first run", false, 2, []string{""}, "100"},
+		{"next cycle", false, 1, []string{"100"}, "200"},
+		{"failure", true, 0, []string{"200"}, "200"},
+		{"resume", false, tradesPageSize + 1, []string{"200", "300"}, "400"},
+	}
+	total := 0
+	for _, step := range steps {
+		requested, failing = nil, step.failing
+		saved, err := h.collectTrades("XXBTZUSD")
+		if (err != nil) != step.failing {
+			t.Fatalf("%s: error %v", step.name, err)
+		}
+		if saved != step.saved || !reflect.DeepEqual(requested, step.requested) {
+			t.Errorf("%s: saved %d with since %q, want %d with %q", step.name, saved, requested, step.saved, step.requested)
+		}
+		if cursor, err := db.GetTradeCursor("XXBTZUSD"); err != nil || cursor != step.cursor {
+			t.Errorf("%s: cursor %q, %v, want %q", step.name, cursor, err, step.cursor)
+		}
+		total += step.saved
+	}
+
+	trades, err := db.GetTradesFromDB("XXBTZUSD", time.Time{}, time.Now(), 0)
+	if err != nil || len(trades) != total {
+		t.Errorf("stored %d trades, %v, want %d", len(trades), err, total)
+	}
+}
+
+func TestTradesLimit(t *testing.T) {
+	r := newTestEngine(NewHandler(newTestDB(t), newFakeKraken(t, nil)))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/pairs/XXBTZUSD/trades?limit=10000", http.StatusOK},
+		{"/api/pairs/XXBTZUSD/trades?limit=10001", http.StatusBadRequest},
+		{"/api/pairs/XXBTZUSD/trades?limit=0", http.StatusBadRequest},
+		{"/api/pairs/XXBTZUSD/trades/large?min_volume=1&limit=10000", http.StatusOK},
+		{"/api/pairs/XXBTZUSD/trades/large?min_volume=1&limit=10001", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("GET %s: status %d, want %d: %s", tt.path, w.Code, tt.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+// Indicator is updated one candle at a time. Update returns false until
+// enough candles have been seen to produce a value.
+type Indicator interface {
+	Outputs() []string
+	Update(c candles.Candle) ([]float64, bool)
+}
+
+type Point struct {
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+// MaxPeriod bounds the periods of the indicators, whose windows are
+// allocated up front.
+const MaxPeriod = 1000
+
+type Spec struct {
+	Name   string
+	Params []float64
+}
+
+var defaults = map[string][]float64{
+	"sma":    {20},
+	"ema":    {20},
+	"rsi":    {14},
+	"macd":   {12, 26, 9},
+	"bbands": {20, 2},
+	"atr":    {14},
+}
+
+// ParseSpec parses "name[:param...]", e.g. "sma:20" or "bbands:20:2".
+// Missing parameters take their default values.
+func ParseSpec(s string) (Spec, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), ":")
+	def, ok := defaults[parts[0]]
+	if !ok {
+		return Spec{}, fmt.Errorf("unknown indicator %q", parts[0])
+	}
+	if len(parts)-1 > len(def) {
+		return Spec{}, fmt.Errorf("too many parameters for %s: expected at most %d", parts[0], len(def))
+	}
+
+	spec := Spec{Name: parts[0], Params: append([]float64(nil), def...)}
+	for i, p := range parts[1:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v <= 0 {
+			return Spec{}, fmt.Errorf("invalid parameter %q for %s", p, parts[0])
+		}
+		spec.Params[i] = v
+	}
+	return spec, nil
+}
+
+func ParseSpecs(s string) ([]Spec, error) {
+	var specs []Spec
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		spec, err := ParseSpec(item)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no indicator requested")
+	}
+	return specs, nil
+}
+
+// String returns the canonical form of the spec, with defaults applied.
+func (s Spec) String() string {
+	parts := []string{s.Name}
+	for _, p := range s.Params {
+		parts = append(parts, strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	return strings.Join(parts, ":")
+}
+
+func New(spec Spec) (Indicator, error) {
+	period := func(i int) (int, error) {
+		p := spec.Params[i]
+		if p != math.Trunc(p) || p < 1 {
+			return 0, fmt.Errorf("%s: period must be a positive integer, got %v", spec.Name, p)
+		}
+		if p > MaxPeriod {
+			return 0, fmt.Errorf("%s: period must be at most %d, got %v", spec.Name, MaxPeriod, p)
+		}
+		return int(p), nil
+	}
+
+	switch spec.Name {
+	case "sma":
+		n, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		return &closeIndicator{output: "sma", f: NewSMA(n)}, nil
+	case "ema":
+		n, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		return &closeIndicator{output: "ema", f: NewEMA(n)}, nil
+	case "rsi":
+		n, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		return &closeIndicator{output: "rsi", f: NewRSI(n)}, nil
+	case "macd":
+		fast, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		slow, err := period(1)
+		if err != nil {
+			return nil, err
+		}
+		signal, err := period(2)
+		if err != nil {
+			return nil, err
+		}
+		if fast >= slow {
+			return nil, fmt.Errorf("macd: fast period must be lower than slow period")
+		}
+		return NewMACD(fast, slow, signal), nil
+	case "bbands":
+		n, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewBollingerBands(n, spec.Params[1]), nil
+	case "atr":
+		n, err := period(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewATR(n), nil
+	}
+	return nil, fmt.Errorf("unknown indicator %q", spec.Name)
+}
+
+// Compute runs the indicator over the candles and returns one point per
+// candle once the indicator is ready.
+func Compute(ind Indicator, data []candles.Candle) []Point {
+	outputs := ind.Outputs()
+	points := make([]Point, 0, len(data))
+	for _, c := range data {
+		values, ok := ind.Update(c)
+		if !ok {
+			continue
+		}
+		p := Point{Time: c.Time, Values: make(map[string]float64, len(outputs))}
+		for i, name := range outputs {
+			p.Values[name] = values[i]
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// closeIndicator adapts a single-output filter over closing prices.
+type closeIndicator struct {
+	output string
+	f      interface {
+		Update(v float64) (float64, bool)
+	}
+}
+
+func (c *closeIndicator) Outputs() []string {
+	return []string{c.output}
+}
+
+func (c *closeIndicator) Update(candle candles.Candle) ([]float64, bool) {
+	v, ok := c.f.Update(candle.Close)
+	if !ok {
+		return nil, false
+	}
+	return []float64{v}, true
+}
@@ -0,0 +1,190 @@
+package indicators
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+// Closing prices of the EMA and RSI examples of StockCharts' ChartSchool,
+// whose published values, rounded to two decimals, are used as references
+// below.
+var (
+	emaCloses = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+		23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+	}
+	rsiCloses = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+)
+
+func toCandles(closes []float64) []candles.Candle {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]candles.Candle, len(closes))
+	for i, c := range closes {
+		data[i] = candles.Candle{Time: start.Add(time.Duration(i) * time.Hour), Open: c, High: c, Low: c, Close: c}
+	}
+	return data
+}
+
+func compute(t *testing.T, spec string, closes []float64) []Point {
+	t.Helper()
+	s, err := ParseSpec(spec)
+	if err != nil {
+		t.Fatalf("ParseSpec(%q): %v", spec, err)
+	}
+	ind, err := New(s)
+	if err != nil {
+		t.Fatalf("New(%q): %v", spec, err)
+	}
+	return Compute(ind, toCandles(closes))
+}
+
+func TestReferenceValues(t *testing.T) {
+	tests := []struct {
+		spec      string
+		closes    []float64
+		tolerance float64
+		want      map[string][]float64
+	}{
+		{
+			spec:      "sma:10",
+			closes:    emaCloses,
+			tolerance: 0.006,
+			want: map[string][]float64{"sma": {
+				22.22, 22.21, 22.23, 22.26, 22.30, 22.42, 22.61, 22.77, 22.91, 23.08,
+				23.21, 23.38, 23.53, 23.65, 23.71, 23.68, 23.61, 23.51, 23.43, 23.28, 23.13,
+			}},
+		},
+		{
+			spec:      "ema:10",
+			closes:    emaCloses,
+			tolerance: 0.006,
+			want: map[string][]float64{"ema": {
+				22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+				23.34, 23.43, 23.51, 23.53, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+			}},
+		},
+		{
+			spec:      "rsi:14",
+			closes:    rsiCloses,
+			tolerance: 0.006,
+			want:      map[string][]float64{"rsi": {70.46, 66.25, 66.48, 69.35, 66.29, 57.92}},
+		},
+		{
+			// EMA(3) - EMA(6), with a signal line seeded once the slow EMA
+			// is ready. Regression values: no published example uses these
+			// periods, they were recorded from this implementation.
+			spec:      "macd:3:6:4",
+			closes:    emaCloses[:12],
+			tolerance: 0.00005,
+			want: map[string][]float64{
+				"macd":      {0.0237, 0.0200, -0.0141, 0.0271},
+				"signal":    {0.0166, 0.0180, 0.0051, 0.0139},
+				"histogram": {0.0070, 0.0020, -0.0193, 0.0132},
+			},
+		},
+		{
+			// Population standard deviation. Regression values, recorded
+			// from this implementation.
+			spec:      "bbands:20:2",
+			closes:    emaCloses[:22],
+			tolerance: 0.00005,
+			want: map[string][]float64{
+				"middle": {22.7155, 22.7930, 22.8770},
+				"upper":  {24.1261, 24.2661, 24.3939},
+				"lower":  {21.3049, 21.3199, 21.3601},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			points := compute(t, tt.spec, tt.closes)
+			for output, want := range tt.want {
+				if len(points) != len(want) {
+					t.Fatalf("got %d points, want %d", len(points), len(want))
+				}
+				for i, w := range want {
+					if got := points[i].Values[output]; math.Abs(got-w) > tt.tolerance {
+						t.Errorf("%s[%d] = %.4f, want %.4f", output, i, got, w)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Computed by hand: the first three true ranges are 2 and seed the average
+// at 2, then a gap up gives 3 (high - previous close) and a drop gives 1.5
+// (previous close - low), each smoothed as (atr × 2 + tr) / 3.
+func TestATR(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := [][3]float64{{10, 8, 9}, {11, 9, 10.5}, {12, 10, 11}, {14, 13, 13.5}, {13, 12, 12.5}}
+	data := make([]candles.Candle, len(bars))
+	for i, b := range bars {
+		data[i] = candles.Candle{Time: start.Add(time.Duration(i) * time.Hour), High: b[0], Low: b[1], Close: b[2]}
+	}
+
+	points := Compute(NewATR(3), data)
+	want := []float64{2, 7.0 / 3, 18.5 / 9}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, w := range want {
+		if got := points[i].Values["atr"]; math.Abs(got-w) > 1e-12 {
+			t.Errorf("atr[%d] = %v, want %v", i, got, w)
+		}
+		if !points[i].Time.Equal(data[i+2].Time) {
+			t.Errorf("atr[%d] at %v, want %v", i, points[i].Time, data[i+2].Time)
+		}
+	}
+}
+
+func TestRSIWithoutLosses(t *testing.T) {
+	points := compute(t, "rsi:3", []float64{1, 2, 3, 4, 5})
+	for _, p := range points {
+		if p.Values["rsi"] != 100 {
+			t.Errorf("rsi = %v, want 100", p.Values["rsi"])
+		}
+	}
+
+	points = compute(t, "rsi:3", []float64{1, 1, 1, 1})
+	if len(points) != 1 || points[0].Values["rsi"] != 50 {
+		t.Errorf("flat prices: got %v, want a single 50", points)
+	}
+}
+
+func TestInvalidSpecs(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{"foo", "unknown indicator"},
+		{"sma:20:3", "too many parameters"},
+		{"sma:-1", "invalid parameter"},
+		{"sma:2.5", "positive integer"},
+		{"sma:1001", "at most 1000"},
+		{"ema:1000000000", "at most 1000"},
+		{"macd:26:12:9", "fast period"},
+		{"bbands:5000", "at most 1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := ParseSpec(tt.spec)
+			if err == nil {
+				_, err = New(spec)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+type SMA struct {
+	period int
+	window []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{period: period, window: make([]float64, period)}
+}
+
+func (s *SMA) Update(v float64) (float64, bool) {
+	if s.count == s.period {
+		s.sum -= s.window[s.next]
+	} else {
+		s.count++
+	}
+	s.window[s.next] = v
+	s.sum += v
+	s.next = (s.next + 1) % s.period
+
+	if s.count < s.period {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+// EMA is seeded with the simple average of the first period values.
+type EMA struct {
+	period int
+	alpha  float64
+	seed   *SMA
+	value  float64
+	ready  bool
+}
+
+func NewEMA(period int) *EMA {
+	return &EMA{period: period, alpha: 2 / float64(period+1), seed: NewSMA(period)}
+}
+
+func (e *EMA) Update(v float64) (float64, bool) {
+	if !e.ready {
+		avg, ok := e.seed.Update(v)
+		if !ok {
+			return 0, false
+		}
+		e.value = avg
+		e.ready = true
+		return e.value, true
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value, true
+}
+
+// RSI uses Wilder's smoothing.
+type RSI struct {
+	period  int
+	prev    float64
+	hasPrev bool
+	count   int
+	avgGain float64
+	avgLoss float64
+}
+
+func NewRSI(period int) *RSI {
+	return &RSI{period: period}
+}
+
+func (r *RSI) Update(v float64) (float64, bool) {
+	if !r.hasPrev {
+		r.prev = v
+		r.hasPrev = true
+		return 0, false
+	}
+
+	change := v - r.prev
+	r.prev = v
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+	n := float64(r.period)
+	if r.count < r.period {
+		r.avgGain += gain / n
+		r.avgLoss += loss / n
+		r.count++
+		if r.count < r.period {
+			return 0, false
+		}
+	} else {
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+
+	if r.avgLoss == 0 {
+		if r.avgGain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss), true
+}
+
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+}
+
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+func (m *MACD) Outputs() []string {
+	return []string{"macd", "signal", "histogram"}
+}
+
+func (m *MACD) Update(c candles.Candle) ([]float64, bool) {
+	fast, fastOK := m.fast.Update(c.Close)
+	slow, slowOK := m.slow.Update(c.Close)
+	if !fastOK || !slowOK {
+		return nil, false
+	}
+
+	macd := fast - slow
+	signal, ok := m.signal.Update(macd)
+	if !ok {
+		return nil, false
+	}
+	return []float64{macd, signal, macd - signal}, true
+}
+
+// BollingerBands uses the population standard deviation, like most charting
+// packages.
+type BollingerBands struct {
+	period int
+	k      float64
+	window []float64
+	next   int
+	count  int
+	sum    float64
+	sumSq  float64
+}
+
+func NewBollingerBands(period int, k float64) *BollingerBands {
+	return &BollingerBands{period: period, k: k, window: make([]float64, period)}
+}
+
+func (b *BollingerBands) Outputs() []string {
+	return []string{"middle", "upper", "lower"}
+}
+
+func (b *BollingerBands) Update(c candles.Candle) ([]float64, bool) {
+	if b.count == b.period {
+		old := b.window[b.next]
+		b.sum -= old
+		b.sumSq -= old * old
+	} else {
+		b.count++
+	}
+	b.window[b.next] = c.Close
+	b.sum += c.Close
+	b.sumSq += c.Close * c.Close
+	b.next = (b.next + 1) % b.period
+
+	if b.count < b.period {
+		return nil, false
+	}
+
+	n := float64(b.period)
+	mean := b.sum / n
+	variance := math.Max(b.sumSq/n-mean*mean, 0)
+	dev := b.k * math.Sqrt(variance)
+	return []float64{mean, mean + dev, mean - dev}, true
+}
+
+// ATR uses Wilder's smoothing, seeded with the average of the first period
+// true ranges.
+type ATR struct {
+	period    int
+	prevClose float64
+	hasPrev   bool
+	count     int
+	value     float64
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{period: period}
+}
+
+func (a *ATR) Outputs() []string {
+	return []string{"atr"}
+}
+
+func (a *ATR) Update(c candles.Candle) ([]float64, bool) {
+	tr := c.High - c.Low
+	if a.hasPrev {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose)))
+	}
+	a.prevClose = c.Close
+	a.hasPrev = true
+
+	n := float64(a.period)
+	if a.count < a.period {
+		a.value += tr / n
+		a.count++
+		if a.count < a.period {
+			return nil, false
+		}
+		return []float64{a.value}, true
+	}
+
+	a.value = (a.value*(n-1) + tr) / n
+	return []float64{a.value}, true
+}
@@ -0,0 +1,120 @@
+package kraken
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+)
+
+// ErrCircuitOpen is returned without calling Kraken while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("kraken: circuit ouvert après des échecs répétés, appels suspendus")
+
+const (
+	// breakerThreshold is the number of consecutive failures that opens the
+	// circuit.
+	breakerThreshold = 5
+	// breakerCooldown is how long the circuit stays open before one trial
+	// call is let through.
+	breakerCooldown = 30 * time.Second
+)
+
+// Circuit states, as returned by CircuitState.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// circuitBreaker stops calling Kraken after repeated failures. Once the
+// cooldown has elapsed a single trial call is allowed: its success closes
+// the circuit, its failure opens it again.
+type circuitBreaker struct {
+	name string
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(name string) *circuitBreaker {
+	metrics.KrakenCircuitOpen.WithLabelValues(name).Set(0)
+	return &circuitBreaker{name: name, state: CircuitClosed}
+}
+
+// allow tells whether a call can be made and whether it is the trial call
+// of a half-open circuit. When it returns true, the outcome of the call must
+// be reported with record, along with probe.
+func (b *circuitBreaker) allow(now time.Time) (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < breakerCooldown {
+			return false, false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true, true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	}
+	return true, false
+}
+
+// record reports the outcome of an allowed call. Calls canceled by the
+// caller are reported as neither success nor failure. Only the trial call
+// closes or reopens a circuit that is not closed: a call allowed before the
+// circuit opened and ending late tells nothing about Kraken now.
+func (b *circuitBreaker) record(now time.Time, probe, failed, canceled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case canceled:
+		return
+	case probe && failed:
+		b.open(now)
+	case probe:
+		slog.Info("circuit Kraken refermé", "client", b.name)
+		metrics.KrakenCircuitOpen.WithLabelValues(b.name).Set(0)
+		b.state = CircuitClosed
+		b.failures = 0
+	case b.state != CircuitClosed:
+		return
+	case failed:
+		b.failures++
+		if b.failures >= breakerThreshold {
+			b.open(now)
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	metrics.KrakenCircuitOpen.WithLabelValues(b.name).Set(1)
+	slog.Warn("circuit Kraken ouvert, appels suspendus", "client", b.name,
+		"failures", b.failures, "cooldown_seconds", breakerCooldown.Seconds())
+}
+
+func (b *circuitBreaker) currentState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
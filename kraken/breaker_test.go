@@ -0,0 +1,133 @@
+package kraken
+
+import (
+	"testing"
+	"time"
+)
+
+// openBreaker returns a breaker opened at now by consecutive failures.
+func openBreaker(t *testing.T, now time.Time) *circuitBreaker {
+	t.Helper()
+	b := newCircuitBreaker("test")
+	for i := 0; i < breakerThreshold; i++ {
+		ok, _ := b.allow(now)
+		if !ok {
+			t.Fatalf("call %d refused before the threshold", i)
+		}
+		b.record(now, false, true, false)
+	}
+	if state := b.currentState(); state != CircuitOpen {
+		t.Fatalf("state %s after %d failures, want %s", state, breakerThreshold, CircuitOpen)
+	}
+	return b
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	now := time.Unix(1718020800, 0)
+	b := newCircuitBreaker("test")
+	for i := 0; i < breakerThreshold-1; i++ {
+		b.allow(now)
+		b.record(now, false, true, false)
+	}
+	// A success resets the count, and canceled calls do not count.
+	b.allow(now)
+	b.record(now, false, false, false)
+	for i := 0; i < breakerThreshold-1; i++ {
+		b.allow(now)
+		b.record(now, false, true, false)
+		b.allow(now)
+		b.record(now, false, true, true)
+	}
+	if state := b.currentState(); state != CircuitClosed {
+		t.Fatalf("state %s, want %s", state, CircuitClosed)
+	}
+
+	b.allow(now)
+	b.record(now, false, true, false)
+	if state := b.currentState(); state != CircuitOpen {
+		t.Fatalf("state %s, want %s", state, CircuitOpen)
+	}
+	if ok, _ := b.allow(now.Add(breakerCooldown - time.Second)); ok {
+		t.Error("call allowed during the cooldown")
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Unix(1718020800, 0)
+	after := now.Add(breakerCooldown)
+
+	tests := []struct {
+		name     string
+		failed   bool
+		canceled bool
+		want     string
+	}{
+		{"success", false, false, CircuitClosed},
+		{"failure", true, false, CircuitOpen},
+		{"canceled", false, true, CircuitHalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := openBreaker(t, now)
+			ok, probe := b.allow(after)
+			if !ok || !probe {
+				t.Fatalf("allow after the cooldown = %v, %v, want a probe", ok, probe)
+			}
+			if ok, _ := b.allow(after); ok {
+				t.Fatal("second call allowed while the probe is running")
+			}
+
+			b.record(after, probe, tt.failed, tt.canceled)
+			if state := b.currentState(); state != tt.want {
+				t.Errorf("state %s, want %s", state, tt.want)
+			}
+			if tt.canceled {
+				// The circuit must not stay stuck without a probe.
+				if ok, probe := b.allow(after); !ok || !probe {
+					t.Errorf("allow after a canceled probe = %v, %v, want a probe", ok, probe)
+				}
+			}
+		})
+	}
+}
+
+// A call allowed while the circuit was closed may end after it opened: its
+// result must not decide for the probe.
+func TestBreakerIgnoresLateCalls(t *testing.T) {
+	now := time.Unix(1718020800, 0)
+	after := now.Add(breakerCooldown)
+
+	b := newCircuitBreaker("test")
+	late, lateProbe := b.allow(now)
+	if !late || lateProbe {
+		t.Fatalf("allow = %v, %v, want a call that is not a probe", late, lateProbe)
+	}
+	for i := 0; i < breakerThreshold; i++ {
+		b.allow(now)
+		b.record(now, false, true, false)
+	}
+
+	// While open.
+	b.record(now, lateProbe, false, false)
+	if state := b.currentState(); state != CircuitOpen {
+		t.Fatalf("state %s after a late success, want %s", state, CircuitOpen)
+	}
+
+	// While half-open, the probe still running.
+	ok, probe := b.allow(after)
+	if !ok || !probe {
+		t.Fatalf("allow after the cooldown = %v, %v, want a probe", ok, probe)
+	}
+	b.record(after, lateProbe, false, false)
+	if state := b.currentState(); state != CircuitHalfOpen {
+		t.Fatalf("state %s after a late success, want %s", state, CircuitHalfOpen)
+	}
+	if ok, _ := b.allow(after); ok {
+		t.Error("second probe allowed after a late success")
+	}
+
+	b.record(after, probe, true, false)
+	if state := b.currentState(); state != CircuitOpen {
+		t.Errorf("state %s after the probe failed, want %s", state, CircuitOpen)
+	}
+}
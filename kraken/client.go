@@ -1,10 +1,14 @@
 package kraken
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,38 +18,52 @@ const (
 
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	ctx        context.Context
 }
 
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: time.Second * 10,
-		},
+		httpClient: newInstrumentedClient("public"),
+		baseURL:    baseURL,
+		ctx:        context.Background(),
 	}
 }
 
-func (c *Client) GetServerStatus() (map[string]any, error) {
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/public/Time", baseURL))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// WithContext returns a copy of the client whose requests carry ctx, so
+// their logs include the request or cycle identifier.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// SetBaseURL points the client to another server, e.g. a local fake of the
+// Kraken API.
+func (c *Client) SetBaseURL(u string) {
+	c.baseURL = strings.TrimSuffix(u, "/")
+}
 
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
+// CircuitState returns the state of the client's circuit breaker:
+// "closed", "open" or "half_open".
+func (c *Client) CircuitState() string {
+	return c.httpClient.Transport.(*instrumentedTransport).breaker.currentState()
+}
+
+func (c *Client) get(u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, u, nil)
+	if err != nil {
 		return nil, err
 	}
+	return c.httpClient.Do(req)
+}
 
-	return result, nil
+func (c *Client) GetServerStatus() (map[string]any, error) {
+	return c.getRaw(fmt.Sprintf("%s/public/Time", c.baseURL))
 }
 
 func (c *Client) GetTradingPairs() (map[string]any, error) {
-	pairsResp, err := c.httpClient.Get(fmt.Sprintf("%s/public/AssetPairs", baseURL))
+	pairsResp, err := c.get(fmt.Sprintf("%s/public/AssetPairs", c.baseURL))
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +87,7 @@ func (c *Client) GetTradingPairs() (map[string]any, error) {
 		return nil, fmt.Errorf("API error: %v", pairsResult.Error)
 	}
 
-	tickerResp, err := c.httpClient.Get(fmt.Sprintf("%s/public/Ticker", baseURL))
+	tickerResp, err := c.get(fmt.Sprintf("%s/public/Ticker", c.baseURL))
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +123,22 @@ func (c *Client) GetTradingPairs() (map[string]any, error) {
 }
 
 func (c *Client) GetPairInfo(pair string) (map[string]any, error) {
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/public/Ticker?pair=%s", baseURL, pair))
+	return c.getRaw(fmt.Sprintf("%s/public/Ticker?pair=%s", c.baseURL, pair))
+}
+
+func (c *Client) GetHistoricalData(pair string, interval int64, since int64) (map[string]any, error) {
+	url := fmt.Sprintf("%s/public/OHLC?pair=%s&interval=%d", c.baseURL, pair, interval)
+	if since > 0 {
+		url += fmt.Sprintf("&since=%d", since)
+	}
+	return c.getRaw(url)
+}
+
+// getRaw returns the whole response of a public endpoint, with its error
+// and result fields. Kraken answers errors with a 200, so a response whose
+// error list is not empty is returned as an error.
+func (c *Client) getRaw(u string) (map[string]any, error) {
+	resp, err := c.get(u)
 	if err != nil {
 		return nil, err
 	}
@@ -121,30 +154,411 @@ func (c *Client) GetPairInfo(pair string) (map[string]any, error) {
 		return nil, err
 	}
 
+	if errs, _ := result["error"].([]any); len(errs) > 0 {
+		return nil, fmt.Errorf("API error: %v", errs)
+	}
+
 	return result, nil
 }
 
-func (c *Client) GetHistoricalData(pair string, interval int64, since int64) (map[string]any, error) {
-	url := fmt.Sprintf("%s/public/OHLC?pair=%s&interval=%d", baseURL, pair, interval)
-	if since > 0 {
-		url += fmt.Sprintf("&since=%d", since)
+type OrderBookEntry struct {
+	Price     float64
+	Volume    float64
+	Timestamp time.Time
+}
+
+type OrderBook struct {
+	Pair string
+	Asks []OrderBookEntry
+	Bids []OrderBookEntry
+}
+
+func (c *Client) GetOrderBook(pair string, count int) (*OrderBook, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+	if count > 0 {
+		params.Set("count", strconv.Itoa(count))
 	}
 
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
+	var result map[string]struct {
+		Asks [][]any `json:"asks"`
+		Bids [][]any `json:"bids"`
+	}
+	if err := c.publicGet("Depth", params, &result); err != nil {
 		return nil, err
 	}
+
+	for name, book := range result {
+		asks, err := parseOrderBookEntries(book.Asks)
+		if err != nil {
+			return nil, err
+		}
+		bids, err := parseOrderBookEntries(book.Bids)
+		if err != nil {
+			return nil, err
+		}
+		return &OrderBook{Pair: name, Asks: asks, Bids: bids}, nil
+	}
+
+	return nil, fmt.Errorf("no order book returned for pair %s", pair)
+}
+
+func parseOrderBookEntries(raw [][]any) ([]OrderBookEntry, error) {
+	entries := make([]OrderBookEntry, 0, len(raw))
+	for _, level := range raw {
+		if len(level) < 3 {
+			return nil, fmt.Errorf("malformed order book level: %v", level)
+		}
+		price, err := parseNumber(level[0])
+		if err != nil {
+			return nil, err
+		}
+		volume, err := parseNumber(level[1])
+		if err != nil {
+			return nil, err
+		}
+		ts, err := parseNumber(level[2])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, OrderBookEntry{
+			Price:     price,
+			Volume:    volume,
+			Timestamp: time.Unix(int64(ts), 0),
+		})
+	}
+	return entries, nil
+}
+
+func parseNumber(v any) (float64, error) {
+	switch n := v.(type) {
+	case string:
+		return strconv.ParseFloat(n, 64)
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unexpected numeric value %v (%T)", v, v)
+	}
+}
+
+func (c *Client) publicGet(endpoint string, params url.Values, result any) error {
+	u := fmt.Sprintf("%s/public/%s", c.baseURL, endpoint)
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	resp, err := c.get(u)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
+	var envelope struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return err
+	}
+
+	if len(envelope.Error) > 0 {
+		return fmt.Errorf("API error: %v", envelope.Error)
+	}
+
+	return json.Unmarshal(envelope.Result, result)
+}
+
+type Trade struct {
+	Price     float64
+	Volume    float64
+	Time      time.Time
+	Side      string
+	OrderType string
+	Misc      string
+	TradeID   int64
+}
+
+type RecentTrades struct {
+	Pair   string
+	Trades []Trade
+	Last   string
+}
+
+func (c *Client) GetRecentTrades(pair string, since string) (*RecentTrades, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+	if since != "" {
+		params.Set("since", since)
+	}
+
+	var result map[string]json.RawMessage
+	if err := c.publicGet("Trades", params, &result); err != nil {
 		return nil, err
 	}
 
+	trades := &RecentTrades{}
+	for key, raw := range result {
+		if key == "last" {
+			trades.Last = strings.Trim(string(raw), `"`)
+			continue
+		}
+
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, err
+		}
+		trades.Pair = key
+		for _, row := range rows {
+			trade, err := parseTrade(row)
+			if err != nil {
+				return nil, err
+			}
+			trades.Trades = append(trades.Trades, trade)
+		}
+	}
+
+	if trades.Pair == "" {
+		return nil, fmt.Errorf("no trades returned for pair %s", pair)
+	}
+
+	return trades, nil
+}
+
+func parseTrade(row []any) (Trade, error) {
+	if len(row) < 6 {
+		return Trade{}, fmt.Errorf("malformed trade: %v", row)
+	}
+
+	price, err := parseNumber(row[0])
+	if err != nil {
+		return Trade{}, err
+	}
+	volume, err := parseNumber(row[1])
+	if err != nil {
+		return Trade{}, err
+	}
+	ts, err := parseNumber(row[2])
+	if err != nil {
+		return Trade{}, err
+	}
+
+	trade := Trade{
+		Price:  price,
+		Volume: volume,
+		Time:   time.Unix(0, int64(ts*float64(time.Second))),
+	}
+	trade.Side, _ = row[3].(string)
+	trade.OrderType, _ = row[4].(string)
+	trade.Misc, _ = row[5].(string)
+	if len(row) > 6 {
+		if id, err := parseNumber(row[6]); err == nil {
+			trade.TradeID = int64(id)
+		}
+	}
+
+	return trade, nil
+}
+
+type AssetPair struct {
+	Altname      string      `json:"altname"`
+	WSName       string      `json:"wsname"`
+	Base         string      `json:"base"`
+	Quote        string      `json:"quote"`
+	PairDecimals int         `json:"pair_decimals"`
+	LotDecimals  int         `json:"lot_decimals"`
+	OrderMin     string      `json:"ordermin"`
+	CostMin      string      `json:"costmin"`
+	TickSize     string      `json:"tick_size"`
+	Fees         [][]float64 `json:"fees"`
+	FeesMaker    [][]float64 `json:"fees_maker"`
+	Status       string      `json:"status"`
+}
+
+func (c *Client) GetAssetPairs() (map[string]AssetPair, error) {
+	var result map[string]AssetPair
+	if err := c.publicGet("AssetPairs", nil, &result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+type Ticker struct {
+	Pair            string
+	Last            float64
+	LastTradeVolume float64
+	Bid             float64
+	Ask             float64
+	Volume24h       float64
+	VWAP24h         float64
+	TradeCount24h   int64
+	High24h         float64
+	Low24h          float64
+	Open            float64
+}
+
+// GetTicker returns the typed ticker of a single pair. The 24h figures are
+// the second element of the v, p, t, h and l arrays.
+func (c *Client) GetTicker(pair string) (*Ticker, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+
+	var result map[string]struct {
+		A []string `json:"a"`
+		B []string `json:"b"`
+		C []string `json:"c"`
+		V []string `json:"v"`
+		P []string `json:"p"`
+		T []int64  `json:"t"`
+		L []string `json:"l"`
+		H []string `json:"h"`
+		O string   `json:"o"`
+	}
+	if err := c.publicGet("Ticker", params, &result); err != nil {
+		return nil, err
+	}
+
+	field := func(values []string, index int) float64 {
+		if index >= len(values) {
+			return 0
+		}
+		f, _ := strconv.ParseFloat(values[index], 64)
+		return f
+	}
+
+	for name, t := range result {
+		ticker := &Ticker{
+			Pair:            name,
+			Last:            field(t.C, 0),
+			LastTradeVolume: field(t.C, 1),
+			Bid:             field(t.B, 0),
+			Ask:             field(t.A, 0),
+			Volume24h:       field(t.V, 1),
+			VWAP24h:         field(t.P, 1),
+			High24h:         field(t.H, 1),
+			Low24h:          field(t.L, 1),
+		}
+		ticker.Open, _ = strconv.ParseFloat(t.O, 64)
+		if len(t.T) > 1 {
+			ticker.TradeCount24h = t.T[1]
+		}
+		return ticker, nil
+	}
+
+	return nil, fmt.Errorf("no ticker returned for pair %s", pair)
+}
+
+type OHLC struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	VWAP   float64
+	Volume float64
+	Count  int64
+}
+
+// GetOHLC returns the typed candles of a pair; interval is in minutes.
+func (c *Client) GetOHLC(pair string, interval int, since int64) ([]OHLC, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+	params.Set("interval", strconv.Itoa(interval))
+	if since > 0 {
+		params.Set("since", strconv.FormatInt(since, 10))
+	}
+
+	var result map[string]json.RawMessage
+	if err := c.publicGet("OHLC", params, &result); err != nil {
+		return nil, err
+	}
+
+	for name, raw := range result {
+		if name == "last" {
+			continue
+		}
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, err
+		}
+
+		candles := make([]OHLC, 0, len(rows))
+		for _, row := range rows {
+			if len(row) < 8 {
+				return nil, fmt.Errorf("malformed OHLC entry: %v", row)
+			}
+			values := make([]float64, 8)
+			for i := range values {
+				v, err := parseNumber(row[i])
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			}
+			candles = append(candles, OHLC{
+				Time:   time.Unix(int64(values[0]), 0),
+				Open:   values[1],
+				High:   values[2],
+				Low:    values[3],
+				Close:  values[4],
+				VWAP:   values[5],
+				Volume: values[6],
+				Count:  int64(values[7]),
+			})
+		}
+		return candles, nil
+	}
+
+	return nil, fmt.Errorf("no OHLC data returned for pair %s", pair)
+}
+
+// Values of SystemStatus.Status.
+const (
+	// StatusOnline means that Kraken is operating normally.
+	StatusOnline = "online"
+	// StatusMaintenance means that the API is offline: no data is served.
+	StatusMaintenance = "maintenance"
+	// StatusCancelOnly means that orders can only be canceled; market data
+	// is still served.
+	StatusCancelOnly = "cancel_only"
+	// StatusPostOnly means that only post-only limit orders are accepted;
+	// market data is still served.
+	StatusPostOnly = "post_only"
+)
+
+// SystemStatus is the trading status of Kraken.
+type SystemStatus struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (c *Client) GetSystemStatus() (*SystemStatus, error) {
+	var result SystemStatus
+	if err := c.publicGet("SystemStatus", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ServerTime is the clock of Kraken's servers, with a one second
+// resolution.
+type ServerTime struct {
+	UnixTime int64  `json:"unixtime"`
+	RFC1123  string `json:"rfc1123"`
+}
+
+func (t ServerTime) Time() time.Time {
+	return time.Unix(t.UnixTime, 0)
+}
+
+func (c *Client) GetServerTime() (*ServerTime, error) {
+	var result ServerTime
+	if err := c.publicGet("Time", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
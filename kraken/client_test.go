@@ -0,0 +1,79 @@
+package kraken
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPublicServer answers every public endpoint with its body in responses,
+// keyed by endpoint name ("Ticker").
+func newPublicServer(t *testing.T, responses map[string]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[strings.TrimPrefix(r.URL.Path, "/0/public/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClient()
+	client.SetBaseURL(srv.URL + "/0/")
+	return client
+}
+
+func TestRawResponses(t *testing.T) {
+	client := newPublicServer(t, map[string]string{
+		"Time":   `{"error":[],"result":{"unixtime":1718020800,"rfc1123":"Mon, 10 Jun 24 12:00:00 +0000"}}`,
+		"Ticker": `{"error":[],"result":{"XXBTZUSD":{"c":["67005.10000","0.00150000"]}}}`,
+		"OHLC":   `{"error":[],"result":{"XXBTZUSD":[[1718020800,"67000.0","67010.0","66990.0","67005.1","67001.2","1.5",12]],"last":1718020500}}`,
+	})
+
+	calls := map[string]func() (map[string]any, error){
+		"GetServerStatus":   client.GetServerStatus,
+		"GetPairInfo":       func() (map[string]any, error) { return client.GetPairInfo("XXBTZUSD") },
+		"GetHistoricalData": func() (map[string]any, error) { return client.GetHistoricalData("XXBTZUSD", 5, 0) },
+	}
+	for name, call := range calls {
+		response, err := call()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if _, ok := response["result"].(map[string]any); !ok {
+			t.Errorf("%s: response without result: %v", name, response)
+		}
+	}
+}
+
+// Kraken reports errors with a 200, so they must not reach the response
+// cache as successes.
+func TestRawResponseErrors(t *testing.T) {
+	client := newPublicServer(t, map[string]string{
+		"Time":   `{"error":["EService:Unavailable"]}`,
+		"Ticker": `{"error":["EQuery:Unknown asset pair"]}`,
+		"OHLC":   `{"error":["EGeneral:Too many requests"]}`,
+	})
+
+	tests := []struct {
+		name string
+		call func() (map[string]any, error)
+		err  string
+	}{
+		{"GetServerStatus", client.GetServerStatus, "EService:Unavailable"},
+		{"GetPairInfo", func() (map[string]any, error) { return client.GetPairInfo("FOOBAR") }, "EQuery:Unknown asset pair"},
+		{"GetHistoricalData", func() (map[string]any, error) { return client.GetHistoricalData("XXBTZUSD", 5, 0) }, "EGeneral:Too many requests"},
+	}
+	for _, tt := range tests {
+		response, err := tt.call()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s = %v, %v, want an error containing %s", tt.name, response, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package kraken
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedTransport records the count, latency and result code of every
+// call to the Kraken API, logs it and traces it in a client span, child of
+// the request context. Kraken reports most errors with a 200 status and an
+// "error" array, so the body is read to find the error code and then handed
+// back to the caller unchanged. Calls are refused while the circuit
+// breaker is open.
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+var tracer = tracing.Tracer("kraken")
+
+// newInstrumentedClient returns the HTTP client of the public or private
+// API client, with its own circuit breaker.
+func newInstrumentedClient(name string) *http.Client {
+	return &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: &instrumentedTransport{next: http.DefaultTransport, breaker: newCircuitBreaker(name)},
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointName(req.URL.Path)
+	ctx, span := tracer.Start(req.Context(), "kraken "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			attribute.String("kraken.endpoint", endpoint),
+		))
+	defer span.End()
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	allowed, probe := t.breaker.allow(start)
+	if !allowed {
+		metrics.KrakenRequests.WithLabelValues(endpoint, "circuit_open").Inc()
+		tracing.RecordError(span, ErrCircuitOpen)
+		return nil, ErrCircuitOpen
+	}
+	resp, err := t.next.RoundTrip(req)
+	var body []byte
+	if err == nil {
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	duration := time.Since(start)
+	metrics.KrakenRequestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+
+	if err != nil {
+		// A timeout counts as a failure, a request abandoned by its caller
+		// does not.
+		t.breaker.record(time.Now(), probe, true, errors.Is(ctx.Err(), context.Canceled))
+		metrics.KrakenRequests.WithLabelValues(endpoint, "transport_error").Inc()
+		tracing.RecordError(span, err)
+		slog.WarnContext(ctx, "échec de l'appel Kraken",
+			"endpoint", endpoint, logging.Duration(duration), logging.Err(err))
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	code := resultCode(resp.StatusCode, body)
+	t.breaker.record(time.Now(), probe, isOutage(resp.StatusCode, code), false)
+	metrics.KrakenRequests.WithLabelValues(endpoint, code).Inc()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode), attribute.String("kraken.result_code", code))
+	level := slog.LevelDebug
+	if code != "ok" {
+		level = slog.LevelWarn
+		span.SetStatus(codes.Error, code)
+	}
+	slog.Log(ctx, level, "appel Kraken",
+		"endpoint", endpoint, "status", resp.StatusCode, "code", code, logging.Duration(duration))
+	return resp, nil
+}
+
+// endpointName turns "/0/public/Ticker" into "public/Ticker".
+func endpointName(path string) string {
+	if i := strings.Index(path, "/public/"); i >= 0 {
+		return path[i+1:]
+	}
+	if i := strings.Index(path, "/private/"); i >= 0 {
+		return path[i+1:]
+	}
+	return "other"
+}
+
+// isOutage tells whether a result means that Kraken is failing, as opposed
+// to an error of the request such as an unknown pair or a rate limit.
+func isOutage(status int, code string) bool {
+	return status >= 500 || code == "invalid_response" || strings.HasPrefix(code, "EService:")
+}
+
+// resultCode is "ok", "http_<status>" or the first Kraken error, such as
+// "EAPI:Rate limit exceeded".
+func resultCode(status int, body []byte) string {
+	if status != http.StatusOK {
+		return "http_" + strconv.Itoa(status)
+	}
+	var envelope struct {
+		Error []string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return "invalid_response"
+	}
+	if len(envelope.Error) == 0 {
+		return "ok"
+	}
+	// Some errors carry a variable suffix after a second colon, such as
+	// "EGeneral:Invalid arguments:price".
+	parts := strings.SplitN(envelope.Error[0], ":", 3)
+	if len(parts) >= 2 {
+		return parts[0] + ":" + parts[1]
+	}
+	return envelope.Error[0]
+}
@@ -0,0 +1,383 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMissingCredentials is returned when no API key or secret is configured.
+var ErrMissingCredentials = errors.New("kraken: API key and secret are required")
+
+type Credentials struct {
+	Key    string
+	Secret string
+}
+
+// LoadCredentials reads the API key and secret from KRAKEN_API_KEY and
+// KRAKEN_API_SECRET, or from the files named by KRAKEN_API_KEY_FILE and
+// KRAKEN_API_SECRET_FILE (e.g. Docker secrets).
+func LoadCredentials() (Credentials, error) {
+	key, err := envOrFile("KRAKEN_API_KEY")
+	if err != nil {
+		return Credentials{}, err
+	}
+	secret, err := envOrFile("KRAKEN_API_SECRET")
+	if err != nil {
+		return Credentials{}, err
+	}
+	if key == "" || secret == "" {
+		return Credentials{}, ErrMissingCredentials
+	}
+	return Credentials{Key: key, Secret: secret}, nil
+}
+
+func envOrFile(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return strings.TrimSpace(value), nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name+"_FILE", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Sign computes the API-Sign header of a private request:
+// base64(HMAC-SHA512(path + SHA256(nonce + postData), base64decode(secret))).
+func Sign(secret []byte, path, nonce, postData string) string {
+	sha := sha256.Sum256([]byte(nonce + postData))
+
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(path))
+	mac.Write(sha[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// nonceSource hands out strictly increasing nonces based on the current time
+// in milliseconds, even when requests are sent concurrently.
+type nonceSource struct {
+	mu   sync.Mutex
+	last int64
+}
+
+func (n *nonceSource) next() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	nonce := time.Now().UnixMilli()
+	if nonce <= n.last {
+		nonce = n.last + 1
+	}
+	n.last = nonce
+	return nonce
+}
+
+// PrivateClient calls Kraken's authenticated endpoints. The API key only
+// needs query permissions for the methods below.
+type PrivateClient struct {
+	httpClient *http.Client
+	baseURL    string
+	key        string
+	secret     []byte
+	// nonces is shared with the copies returned by WithContext.
+	nonces *nonceSource
+	ctx    context.Context
+}
+
+func NewPrivateClient(creds Credentials) (*PrivateClient, error) {
+	if creds.Key == "" || creds.Secret == "" {
+		return nil, ErrMissingCredentials
+	}
+	secret, err := base64.StdEncoding.DecodeString(creds.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("kraken: invalid API secret: %w", err)
+	}
+	return &PrivateClient{
+		httpClient: newInstrumentedClient("private"),
+		baseURL:    baseURL,
+		key:        creds.Key,
+		secret:     secret,
+		nonces:     &nonceSource{},
+		ctx:        context.Background(),
+	}, nil
+}
+
+// WithContext returns a copy of the client whose requests carry ctx, so
+// their logs include the request or cycle identifier.
+func (c *PrivateClient) WithContext(ctx context.Context) *PrivateClient {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+// SetBaseURL points the client to another server, e.g. a local fake of the
+// Kraken API.
+func (c *PrivateClient) SetBaseURL(u string) {
+	c.baseURL = strings.TrimSuffix(u, "/")
+}
+
+func (c *PrivateClient) privatePost(endpoint string, params url.Values, result any) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	nonce := strconv.FormatInt(c.nonces.next(), 10)
+	params.Set("nonce", nonce)
+	postData := params.Encode()
+
+	// The signed path includes the API version prefix, e.g. /0/private/Balance.
+	u := fmt.Sprintf("%s/private/%s", c.baseURL, endpoint)
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, u, strings.NewReader(postData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("API-Key", c.key)
+	req.Header.Set("API-Sign", Sign(c.secret, parsed.Path, nonce, postData))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return err
+	}
+
+	if len(envelope.Error) > 0 {
+		return fmt.Errorf("API error: %v", envelope.Error)
+	}
+
+	return json.Unmarshal(envelope.Result, result)
+}
+
+// UnixTime converts the fractional Unix timestamps used by Kraken, keeping
+// microsecond precision.
+func UnixTime(ts float64) time.Time {
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3)
+}
+
+func setRange(params url.Values, start, end time.Time, offset int) {
+	if !start.IsZero() {
+		params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	}
+	if !end.IsZero() {
+		params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+	if offset > 0 {
+		params.Set("ofs", strconv.Itoa(offset))
+	}
+}
+
+// GetBalance returns the balance of every asset of the account, keyed by
+// Kraken asset code.
+func (c *PrivateClient) GetBalance() (map[string]float64, error) {
+	var result map[string]string
+	if err := c.privatePost("Balance", nil, &result); err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]float64, len(result))
+	for asset, amount := range result {
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance for %s: %v", asset, err)
+		}
+		balances[asset] = value
+	}
+	return balances, nil
+}
+
+type TradeBalance struct {
+	EquivalentBalance float64 `json:"eb,string"`
+	TradeBalance      float64 `json:"tb,string"`
+	MarginUsed        float64 `json:"m,string"`
+	UnrealizedPnL     float64 `json:"n,string"`
+	Cost              float64 `json:"c,string"`
+	Valuation         float64 `json:"v,string"`
+	Equity            float64 `json:"e,string"`
+	FreeMargin        float64 `json:"mf,string"`
+	MarginLevel       float64 `json:"ml,string,omitempty"`
+}
+
+// GetTradeBalance returns the margin summary of the account, expressed in
+// asset (default ZUSD).
+func (c *PrivateClient) GetTradeBalance(asset string) (*TradeBalance, error) {
+	params := url.Values{}
+	if asset != "" {
+		params.Set("asset", asset)
+	}
+
+	var result TradeBalance
+	if err := c.privatePost("TradeBalance", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type OrderDescription struct {
+	Pair      string `json:"pair"`
+	Type      string `json:"type"`
+	OrderType string `json:"ordertype"`
+	Price     string `json:"price"`
+	Price2    string `json:"price2"`
+	Leverage  string `json:"leverage"`
+	Order     string `json:"order"`
+	Close     string `json:"close"`
+}
+
+type OrderInfo struct {
+	RefID          string           `json:"refid"`
+	UserRef        int64            `json:"userref"`
+	Status         string           `json:"status"`
+	Reason         string           `json:"reason"`
+	OpenTime       float64          `json:"opentm"`
+	StartTime      float64          `json:"starttm"`
+	ExpireTime     float64          `json:"expiretm"`
+	CloseTime      float64          `json:"closetm"`
+	Description    OrderDescription `json:"descr"`
+	Volume         float64          `json:"vol,string"`
+	VolumeExecuted float64          `json:"vol_exec,string"`
+	Cost           float64          `json:"cost,string"`
+	Fee            float64          `json:"fee,string"`
+	Price          float64          `json:"price,string"`
+	StopPrice      float64          `json:"stopprice,string"`
+	LimitPrice     float64          `json:"limitprice,string"`
+	Misc           string           `json:"misc"`
+	Flags          string           `json:"oflags"`
+	Trades         []string         `json:"trades"`
+}
+
+// GetOpenOrders returns the open orders of the account keyed by txid.
+func (c *PrivateClient) GetOpenOrders() (map[string]OrderInfo, error) {
+	params := url.Values{}
+	params.Set("trades", "true")
+
+	var result struct {
+		Open map[string]OrderInfo `json:"open"`
+	}
+	if err := c.privatePost("OpenOrders", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Open, nil
+}
+
+type ClosedOrders struct {
+	Closed map[string]OrderInfo `json:"closed"`
+	Count  int                  `json:"count"`
+}
+
+// GetClosedOrders returns one page (up to 50 orders) of closed orders between
+// start and end; zero times are left unbounded.
+func (c *PrivateClient) GetClosedOrders(start, end time.Time, offset int) (*ClosedOrders, error) {
+	params := url.Values{}
+	params.Set("trades", "true")
+	setRange(params, start, end, offset)
+
+	var result ClosedOrders
+	if err := c.privatePost("ClosedOrders", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type TradeInfo struct {
+	OrderTxID string   `json:"ordertxid"`
+	PosTxID   string   `json:"postxid"`
+	Pair      string   `json:"pair"`
+	Time      float64  `json:"time"`
+	Type      string   `json:"type"`
+	OrderType string   `json:"ordertype"`
+	Price     float64  `json:"price,string"`
+	Cost      float64  `json:"cost,string"`
+	Fee       float64  `json:"fee,string"`
+	Volume    float64  `json:"vol,string"`
+	Margin    float64  `json:"margin,string"`
+	Misc      string   `json:"misc"`
+	LedgerIDs []string `json:"ledgers"`
+}
+
+type TradesHistory struct {
+	Trades map[string]TradeInfo `json:"trades"`
+	Count  int                  `json:"count"`
+}
+
+// GetTradesHistory returns one page (up to 50 trades) of the account's
+// trades between start and end.
+func (c *PrivateClient) GetTradesHistory(start, end time.Time, offset int) (*TradesHistory, error) {
+	params := url.Values{}
+	setRange(params, start, end, offset)
+
+	var result TradesHistory
+	if err := c.privatePost("TradesHistory", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type LedgerEntry struct {
+	RefID   string  `json:"refid"`
+	Time    float64 `json:"time"`
+	Type    string  `json:"type"`
+	Subtype string  `json:"subtype"`
+	AClass  string  `json:"aclass"`
+	Asset   string  `json:"asset"`
+	Amount  float64 `json:"amount,string"`
+	Fee     float64 `json:"fee,string"`
+	Balance float64 `json:"balance,string"`
+}
+
+type Ledgers struct {
+	Ledger map[string]LedgerEntry `json:"ledger"`
+	Count  int                    `json:"count"`
+}
+
+// GetLedgers returns one page (up to 50 entries) of ledger entries between
+// start and end, optionally restricted to a comma-separated list of assets.
+func (c *PrivateClient) GetLedgers(asset string, start, end time.Time, offset int) (*Ledgers, error) {
+	params := url.Values{}
+	if asset != "" {
+		params.Set("asset", asset)
+	}
+	setRange(params, start, end, offset)
+
+	var result Ledgers
+	if err := c.privatePost("Ledgers", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
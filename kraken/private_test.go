@@ -0,0 +1,213 @@
+package kraken
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The example of Kraken's REST authentication documentation.
+const (
+	docSecret    = "kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=="
+	docNonce     = "1616492376594"
+	docPostData  = "nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25"
+	docPath      = "/0/private/AddOrder"
+	docSignature = "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ=="
+)
+
+func TestSignDocumentedExample(t *testing.T) {
+	secret, err := base64.StdEncoding.DecodeString(docSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Sign(secret, docPath, docNonce, docPostData); got != docSignature {
+		t.Errorf("Sign = %s, want %s", got, docSignature)
+	}
+}
+
+func TestNoncesIncreaseUnderConcurrency(t *testing.T) {
+	const goroutines, perGoroutine = 20, 200
+	var n nonceSource
+	start := time.Now().UnixMilli()
+	var wg sync.WaitGroup
+	results := make([][]int64, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				results[g] = append(results[g], n.next())
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, goroutines*perGoroutine)
+	for _, nonces := range results {
+		for i, nonce := range nonces {
+			if seen[nonce] {
+				t.Fatalf("nonce %d handed out twice", nonce)
+			}
+			seen[nonce] = true
+			if nonce < start {
+				t.Fatalf("nonce %d is older than the start of the test (%d)", nonce, start)
+			}
+			if i > 0 && nonce <= nonces[i-1] {
+				t.Fatalf("nonce %d after %d", nonce, nonces[i-1])
+			}
+		}
+	}
+}
+
+// fakeKraken checks the authentication headers of every private call and
+// answers with the body registered for its endpoint.
+type fakeKraken struct {
+	t         *testing.T
+	key       string
+	secret    []byte
+	responses map[string]string
+
+	mu     sync.Mutex
+	nonces []string
+	forms  map[string]url.Values
+}
+
+func (f *fakeKraken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.t.Errorf("reading body: %v", err)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		f.t.Errorf("invalid body %q: %v", body, err)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		f.t.Errorf("%s %s, want POST", r.Method, r.URL.Path)
+	}
+	if got := r.Header.Get("API-Key"); got != f.key {
+		f.t.Errorf("API-Key = %q, want %q", got, f.key)
+	}
+	nonce := form.Get("nonce")
+	if want := Sign(f.secret, r.URL.Path, nonce, string(body)); r.Header.Get("API-Sign") != want {
+		f.t.Errorf("API-Sign of %s = %q, want %q", r.URL.Path, r.Header.Get("API-Sign"), want)
+	}
+
+	f.mu.Lock()
+	f.nonces = append(f.nonces, nonce)
+	f.forms[r.URL.Path] = form
+	f.mu.Unlock()
+
+	response, ok := f.responses[r.URL.Path]
+	if !ok {
+		f.t.Errorf("unexpected call to %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, response)
+}
+
+func newFakeKraken(t *testing.T, responses map[string]string) (*PrivateClient, *fakeKraken) {
+	t.Helper()
+	secret, err := base64.StdEncoding.DecodeString(docSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeKraken{t: t, key: "test-key", secret: secret, responses: responses, forms: make(map[string]url.Values)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := NewPrivateClient(Credentials{Key: fake.key, Secret: docSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetBaseURL(srv.URL + "/0/")
+	return client, fake
+}
+
+func TestPrivateCalls(t *testing.T) {
+	client, fake := newFakeKraken(t, map[string]string{
+		"/0/private/Balance": `{"error":[],"result":{"XXBT":"0.5000000000","ZEUR":"1250.25"}}`,
+		"/0/private/Ledgers": `{"error":[],"result":{"ledger":{"L4UESK-KG3EQ-UFO4T5":{"refid":"TJKLXX-PGMUI-4NTLXU","time":1688464484.1787,"type":"trade","subtype":"","aclass":"currency","asset":"ZEUR","amount":"-24.5000","fee":"0.0490","balance":"459567.9171"}},"count":1}}`,
+	})
+
+	balances, err := client.GetBalance()
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balances["XXBT"] != 0.5 || balances["ZEUR"] != 1250.25 {
+		t.Errorf("balances = %v", balances)
+	}
+
+	start := time.Unix(1688000000, 0)
+	ledgers, err := client.GetLedgers("ZEUR", start, time.Time{}, 50)
+	if err != nil {
+		t.Fatalf("GetLedgers: %v", err)
+	}
+	entry, ok := ledgers.Ledger["L4UESK-KG3EQ-UFO4T5"]
+	if ledgers.Count != 1 || !ok {
+		t.Fatalf("ledgers = %+v", ledgers)
+	}
+	if entry.Amount != -24.5 || entry.Fee != 0.049 || entry.Asset != "ZEUR" || entry.Type != "trade" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	form := fake.forms["/0/private/Ledgers"]
+	if form.Get("asset") != "ZEUR" || form.Get("start") != "1688000000" || form.Get("ofs") != "50" || form.Has("end") {
+		t.Errorf("Ledgers parameters = %v", form)
+	}
+}
+
+func TestPrivateCallErrors(t *testing.T) {
+	invalidKey := `{"error":["EAPI:Invalid key"]}`
+	client, _ := newFakeKraken(t, map[string]string{
+		"/0/private/Balance": invalidKey,
+		"/0/private/Ledgers": invalidKey,
+	})
+
+	if _, err := client.GetBalance(); err == nil || !strings.Contains(err.Error(), "EAPI:Invalid key") {
+		t.Errorf("GetBalance error = %v, want EAPI:Invalid key", err)
+	}
+	if _, err := client.GetLedgers("", time.Time{}, time.Time{}, 0); err == nil || !strings.Contains(err.Error(), "EAPI:Invalid key") {
+		t.Errorf("GetLedgers error = %v, want EAPI:Invalid key", err)
+	}
+}
+
+func TestConcurrentPrivateCallsUseDistinctNonces(t *testing.T) {
+	client, fake := newFakeKraken(t, map[string]string{
+		"/0/private/Balance": `{"error":[],"result":{}}`,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetBalance(); err != nil {
+				t.Errorf("GetBalance: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, nonce := range fake.nonces {
+		if seen[nonce] {
+			t.Errorf("nonce %s sent twice", nonce)
+		}
+		seen[nonce] = true
+	}
+	if len(seen) != 20 {
+		t.Errorf("got %d distinct nonces, want 20", len(seen))
+	}
+}
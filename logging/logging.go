@@ -0,0 +1,114 @@
+// Package logging configures the structured JSON logger of the service and
+// carries the request and collection cycle identifiers through contexts.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	cycleIDKey
+)
+
+// ParseLevel reads a LOG_LEVEL value: debug, info (default), warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("niveau de log inconnu %q (debug, info, warn ou error)", s)
+	}
+}
+
+// Setup installs a JSON logger writing to w as the default slog logger. The
+// standard log package is redirected to it too.
+func Setup(w io.Writer, level slog.Level) *slog.Logger {
+	logger := slog.New(&contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// contextHandler adds the request and cycle identifiers, and the current
+// trace and span, found in the context to every record logged with one of
+// the *Context methods.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := CycleID(ctx); id != "" {
+		r.AddAttrs(slog.String("cycle_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
+
+// NewID returns a random 16 bytes identifier in hexadecimal.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithCycleID marks the context of one collection cycle, so the Kraken
+// calls and database writes of the cycle share its identifier.
+func WithCycleID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, cycleIDKey, id)
+}
+
+func CycleID(ctx context.Context) string {
+	id, _ := ctx.Value(cycleIDKey).(string)
+	return id
+}
+
+// Err is the attribute used for errors.
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// Duration is the attribute used for durations, in milliseconds.
+func Duration(d time.Duration) slog.Attr {
+	return slog.Float64("duration_ms", float64(d.Microseconds())/1000)
+}
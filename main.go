@@ -2,50 +2,206 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/antonyloussararian/Go-CryptoPrice/account"
+	"github.com/antonyloussararian/Go-CryptoPrice/auth"
 	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
 	"github.com/antonyloussararian/Go-CryptoPrice/handlers"
 	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+	"github.com/antonyloussararian/Go-CryptoPrice/openapi"
+	"github.com/antonyloussararian/Go-CryptoPrice/ratelimit"
+	"github.com/antonyloussararian/Go-CryptoPrice/tracing"
 	"github.com/gin-gonic/gin"
 )
 
+// accountSyncInterval is how often the Kraken account balances and ledger
+// are synced when API keys are configured.
+const accountSyncInterval = 15 * time.Minute
+
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// LOG_LEVEL sets the minimum level of the JSON logs: debug, info, warn
+	// or error.
+	level, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.Setup(os.Stdout, level)
+
+	// OTEL_TRACES_EXPORTER selects the trace exporter: otlp, stdout or none.
+	// The OTLP endpoint is read from OTEL_EXPORTER_OTLP_ENDPOINT.
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter: os.Getenv("OTEL_TRACES_EXPORTER"),
+	})
+	if err != nil {
+		fatal("échec de l'initialisation des traces", err)
+	}
+
 	db, err := database.NewDB("crypto.db")
 	if err != nil {
-		log.Fatalf("Erreur lors de l'initialisation de la base de données: %v", err)
+		fatal("échec de l'initialisation de la base de données", err)
 	}
 	defer db.Close()
 
 	if err := db.InitSchema(); err != nil {
-		log.Fatalf("Erreur lors de l'initialisation du schéma: %v", err)
+		fatal("échec de l'initialisation du schéma", err)
 	}
 
 	krakenClient := kraken.NewClient()
 
 	h := handlers.NewHandler(db, krakenClient)
 
-	log.Println("Premier enregistrement des données...")
-	if err := h.SaveDataToDB(); err != nil {
-		log.Printf("Erreur lors du premier enregistrement: %v", err)
-	} else {
-		log.Println("Premier enregistrement effectué avec succès")
+	// EXCHANGES lists the other venues whose tickers are collected alongside
+	// Kraken's; set it to an empty value to disable them.
+	venues, ok := os.LookupEnv("EXCHANGES")
+	if !ok {
+		venues = "binance,coinbase"
+	}
+	for _, name := range strings.Split(venues, ",") {
+		if strings.TrimSpace(name) == "" || strings.EqualFold(strings.TrimSpace(name), "kraken") {
+			continue
+		}
+		ex, err := exchange.New(name, krakenClient)
+		if err != nil {
+			fatal("configuration EXCHANGES invalide", err)
+		}
+		h.AddExchange(ex)
+	}
+	if s := os.Getenv("SPREAD_ALERT_BPS"); s != "" {
+		bps, err := strconv.ParseFloat(s, 64)
+		if err != nil || bps < 0 {
+			fatal("configuration SPREAD_ALERT_BPS invalide", fmt.Errorf("valeur %q", s))
+		}
+		h.SetSpreadAlertThreshold(bps)
+	}
+	// INDEX_ASSETS lists the assets whose reference price is stored on
+	// every save cycle.
+	if list, ok := os.LookupEnv("INDEX_ASSETS"); ok {
+		h.SetIndexAssets(strings.Split(list, ","))
+	}
+
+	// CACHE_TTLS overrides the cache TTL of the endpoints proxying Kraken,
+	// e.g. "status=30s,pairs=2m,pair=0" (0 disables the cache).
+	for _, item := range strings.Split(os.Getenv("CACHE_TTLS"), ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		endpoint, value, _ := strings.Cut(item, "=")
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			fatal("configuration CACHE_TTLS invalide", fmt.Errorf("valeur %q", item))
+		}
+		if err := h.SetCacheTTL(strings.TrimSpace(endpoint), ttl); err != nil {
+			fatal("configuration CACHE_TTLS invalide", err)
+		}
 	}
 
+	// SaveDataToDB logs the outcome of the first cycle.
+	h.SaveDataToDB()
+
 	h.StartAutoSave()
 
-	r := gin.Default()
+	creds, err := kraken.LoadCredentials()
+	switch {
+	case err == nil:
+		privateClient, err := kraken.NewPrivateClient(creds)
+		if err != nil {
+			fatal("échec de l'initialisation du client Kraken privé", err)
+		}
+		syncer := account.NewSyncer(db, privateClient)
+		h.SetAccountSyncer(syncer)
+		if _, err := syncer.Sync(); err != nil {
+			slog.Error("échec de la première synchronisation du compte", logging.Err(err))
+		}
+		syncer.Start(accountSyncInterval)
+	case errors.Is(err, kraken.ErrMissingCredentials):
+		slog.Info("clés API Kraken absentes, synchronisation du compte désactivée")
+	default:
+		fatal("échec du chargement des clés API Kraken", err)
+	}
+
+	// Gin's own text logs are replaced by the JSON request logs.
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	// Client IPs are only taken from X-Forwarded-For when the request comes
+	// from one of TRUSTED_PROXIES, so that rate limits cannot be bypassed.
+	var proxies []string
+	if list := os.Getenv("TRUSTED_PROXIES"); list != "" {
+		proxies = strings.Split(list, ",")
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		fatal("configuration TRUSTED_PROXIES invalide", err)
+	}
+	r.Use(gin.Recovery(), tracing.Middleware(), logging.Middleware(), metrics.Middleware())
 
-	r.GET("/api/status", h.GetServerStatus)
-	r.GET("/api/pairs", h.GetTradingPairs)
-	r.GET("/api/pairs/:pair", h.GetPairInfo)
-	r.GET("/api/historical", h.DownloadHistoricalData)
-	r.GET("/api/db", h.GetDBData)
+	// RATE_LIMITS overrides the rules per route and per IP address, e.g.
+	// "/api/pairs=30/m:10,default=300/m:60,ip=600/m:120"; DAILY_QUOTA is the number of
+	// requests per client and UTC day, 0 to disable it.
+	limits := ratelimit.DefaultConfig()
+	if err := limits.ParseRules(os.Getenv("RATE_LIMITS")); err != nil {
+		fatal("configuration RATE_LIMITS invalide", err)
+	}
+	if s := os.Getenv("DAILY_QUOTA"); s != "" {
+		quota, err := strconv.Atoi(s)
+		if err != nil || quota < 0 {
+			fatal("configuration DAILY_QUOTA invalide", fmt.Errorf("valeur %q", s))
+		}
+		limits.DailyQuota = quota
+	}
+	// The limit per IP address runs before authentication, so that invalid
+	// keys and tokens are limited too; the limits per client need the
+	// authenticated principal and run after it.
+	limiter := ratelimit.NewLimiter(db, limits)
+	r.Use(limiter.IPMiddleware())
+
+	// AUTH_DISABLED=true leaves the API open, e.g. for local development.
+	var scopeFor func(method, route string) string
+	if os.Getenv("AUTH_DISABLED") == "true" {
+		slog.Warn("authentification désactivée, l'API est ouverte à tous")
+	} else {
+		authenticator := auth.NewAuthenticator(db)
+		verifier, err := auth.LoadJWTVerifier()
+		switch {
+		case err == nil:
+			authenticator.SetJWTVerifier(verifier)
+		case !errors.Is(err, auth.ErrJWTNotConfigured):
+			fatal("configuration JWT invalide", err)
+		}
+		scopeFor = routeScope
+		r.Use(authenticator.Middleware(scopeFor))
+	}
+
+	r.Use(limiter.Middleware())
+
+	r.GET("/metrics", metrics.Handler())
+	h.RegisterRoutes(r)
+
+	// Registered last, so that the document lists every route.
+	if err := openapi.Register(r, scopeFor); err != nil {
+		fatal("échec de la génération du document OpenAPI", err)
+	}
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -56,17 +212,43 @@ func main() {
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
+		slog.Info("serveur HTTP démarré", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Erreur serveur HTTP: %v", err)
+			fatal("erreur du serveur HTTP", err)
 		}
 	}()
 
 	<-stopChan
-	log.Println("Arrêt du serveur...")
+	slog.Info("arrêt du serveur")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Erreur lors de l'arrêt du serveur: %v", err)
+		slog.Error("échec de l'arrêt du serveur", logging.Err(err))
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("échec de l'envoi des dernières traces", logging.Err(err))
 	}
 }
+
+// routeScope returns the scope required by a route. Probes, metrics and the
+// API documentation stay public; raw database reads and backtests need read:db, writes and the
+// Kraken account need admin, and the other reads need read:prices.
+func routeScope(method, route string) string {
+	switch {
+	case !strings.HasPrefix(route, "/api/"):
+		return ""
+	case route == "/api/db", route == "/api/historical", route == "/api/backtests":
+		return auth.ScopeReadDB
+	case strings.HasPrefix(route, "/api/account/"), method != http.MethodGet:
+		return auth.ScopeAdmin
+	default:
+		return auth.ScopeReadPrices
+	}
+}
+
+// fatal logs err and stops the service.
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/auth"
+)
+
+func TestRouteScope(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{http.MethodGet, "/healthz", ""},
+		{http.MethodGet, "/metrics", ""},
+		{http.MethodGet, "/openapi.json", ""},
+		{http.MethodGet, "", ""}, // unmatched routes
+		{http.MethodGet, "/api/pairs/:pair", auth.ScopeReadPrices},
+		{http.MethodGet, "/api/backtests/strategies", auth.ScopeReadPrices},
+		{http.MethodGet, "/api/paper/accounts", auth.ScopeReadPrices},
+		{http.MethodGet, "/api/db", auth.ScopeReadDB},
+		{http.MethodGet, "/api/historical", auth.ScopeReadDB},
+		// A backtest reads the stored candles and writes nothing.
+		{http.MethodPost, "/api/backtests", auth.ScopeReadDB},
+		{http.MethodGet, "/api/account/balances", auth.ScopeAdmin},
+		{http.MethodGet, "/api/account/reconciliation", auth.ScopeAdmin},
+		{http.MethodPost, "/api/account/sync", auth.ScopeAdmin},
+		{http.MethodPost, "/api/portfolios", auth.ScopeAdmin},
+		{http.MethodPut, "/api/portfolios/:id", auth.ScopeAdmin},
+		{http.MethodDelete, "/api/paper/orders/:id", auth.ScopeAdmin},
+		{http.MethodPost, "/api/paper/orders", auth.ScopeAdmin},
+	}
+	for _, tt := range tests {
+		if got := routeScope(tt.method, tt.route); got != tt.want {
+			t.Errorf("routeScope(%s, %q) = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package metrics defines the Prometheus metrics of the collector and the
+// API, exposed at /metrics.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cryptoprice"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durée des requêtes HTTP par route, méthode et code de statut.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	KrakenRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kraken_requests_total",
+		Help:      "Appels à l'API Kraken par endpoint et code de résultat.",
+	}, []string{"endpoint", "code"})
+
+	KrakenCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kraken_circuit_open",
+		Help:      "1 quand le disjoncteur du client Kraken (public ou private) est ouvert.",
+	}, []string{"client"})
+
+	KrakenRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kraken_request_duration_seconds",
+		Help:      "Durée des appels à l'API Kraken par endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	SaveCycleDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "save_cycle_duration_seconds",
+		Help:      "Durée des cycles d'enregistrement des données.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300},
+	})
+
+	SaveCycles = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "save_cycles_total",
+		Help:      "Cycles d'enregistrement par résultat (success, failure ou paused).",
+	}, []string{"result"})
+
+	LastSuccessfulSave = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_successful_save_timestamp_seconds",
+		Help:      "Horodatage Unix du dernier cycle d'enregistrement réussi.",
+	})
+
+	DBRowsWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_rows_written_total",
+		Help:      "Lignes insérées ou modifiées par table.",
+	}, []string{"table"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durée des requêtes SQL par opération et table.",
+		Buckets:   []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+
+	RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requêtes refusées par route et raison (rate ou quota).",
+	}, []string{"route", "reason"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Réponses servies par le cache des endpoints Kraken, par endpoint et résultat (hit, miss, stale, stale-if-error ou fallback).",
+	}, []string{"endpoint", "result"})
+)
+
+// Middleware records the latency and status of every request. Requests that
+// match no route are grouped under "unmatched" to bound the label values.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObserveSaveCycle records the outcome of a save cycle started at start.
+func ObserveSaveCycle(start time.Time, err error) {
+	SaveCycleDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		SaveCycles.WithLabelValues("failure").Inc()
+		return
+	}
+	SaveCycles.WithLabelValues("success").Inc()
+	LastSuccessfulSave.SetToCurrentTime()
+}
+
+// ObservePausedSaveCycle records a save cycle skipped because Kraken is in
+// maintenance.
+func ObservePausedSaveCycle(start time.Time) {
+	SaveCycleDuration.Observe(time.Since(start).Seconds())
+	SaveCycles.WithLabelValues("paused").Inc()
+}
@@ -2,11 +2,20 @@ package models
 
 import "time"
 
+// ServerStatus is the outcome of the Kraken check made at the start of
+// every collection cycle. Status is Kraken's system status, or
+// "unreachable" when the check failed; Error is a JSON array of messages.
+// PreviousStatus is set when the status differs from the previous check,
+// and ClockSkewMs is Kraken's clock minus the local one, when measured.
 type ServerStatus struct {
-	ID        int64     `json:"id" db:"id"`
-	Timestamp time.Time `json:"timestamp" db:"timestamp"`
-	Status    string    `json:"status" db:"status"`
-	Error     string    `json:"error" db:"error"`
+	ID             int64     `json:"id" db:"id"`
+	Timestamp      time.Time `json:"timestamp" db:"timestamp"`
+	Status         string    `json:"status" db:"status"`
+	Error          string    `json:"error" db:"error"`
+	LatencyMs      float64   `json:"latency_ms" db:"latency_ms"`
+	SystemTime     time.Time `json:"system_time" db:"system_time"`
+	PreviousStatus string    `json:"previous_status,omitempty" db:"previous_status"`
+	ClockSkewMs    *float64  `json:"clock_skew_ms,omitempty" db:"clock_skew_ms"`
 }
 
 type TradingPair struct {
@@ -14,17 +23,32 @@ type TradingPair struct {
 	Name        string    `json:"name" db:"name"`
 	Base        string    `json:"base" db:"base"`
 	Quote       string    `json:"quote" db:"quote"`
+	Source      string    `json:"source" db:"source"`
 	LastUpdated time.Time `json:"last_updated" db:"last_updated"`
 }
 
 type PairInfo struct {
-	ID        int64     `json:"id" db:"id"`
-	PairID    int64     `json:"pair_id" db:"pair_id"`
-	Price     float64   `json:"price" db:"price"`
-	Volume24h float64   `json:"volume_24h" db:"volume_24h"`
-	High24h   float64   `json:"high_24h" db:"high_24h"`
-	Low24h    float64   `json:"low_24h" db:"low_24h"`
-	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	ID              int64     `json:"id" db:"id"`
+	PairID          int64     `json:"pair_id" db:"pair_id"`
+	Price           float64   `json:"price" db:"price"`
+	Volume24h       float64   `json:"volume_24h" db:"volume_24h"`
+	High24h         float64   `json:"high_24h" db:"high_24h"`
+	Low24h          float64   `json:"low_24h" db:"low_24h"`
+	Bid             float64   `json:"bid" db:"bid"`
+	Ask             float64   `json:"ask" db:"ask"`
+	LastTradeVolume float64   `json:"last_trade_volume" db:"last_trade_volume"`
+	VWAP24h         float64   `json:"vwap_24h" db:"vwap_24h"`
+	TradeCount24h   int64     `json:"trade_count_24h" db:"trade_count_24h"`
+	Open            float64   `json:"open" db:"open"`
+	Timestamp       time.Time `json:"timestamp" db:"timestamp"`
+}
+
+// PairSnapshot is a stored ticker snapshot with its pair.
+type PairSnapshot struct {
+	Name  string `json:"name"`
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+	PairInfo
 }
 
 type HistoricalData struct {
@@ -37,3 +61,230 @@ type HistoricalData struct {
 	Close     float64   `json:"close" db:"close"`
 	Volume    float64   `json:"volume" db:"volume"`
 }
+
+type OrderBookLevel struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+}
+
+type DepthMetrics struct {
+	BestBid      float64 `json:"best_bid" db:"best_bid"`
+	BestAsk      float64 `json:"best_ask" db:"best_ask"`
+	Spread       float64 `json:"spread" db:"spread"`
+	MidPrice     float64 `json:"mid_price" db:"mid_price"`
+	BidDepth1Pct float64 `json:"bid_depth_1pct" db:"bid_depth_1pct"`
+	AskDepth1Pct float64 `json:"ask_depth_1pct" db:"ask_depth_1pct"`
+	BidDepth2Pct float64 `json:"bid_depth_2pct" db:"bid_depth_2pct"`
+	AskDepth2Pct float64 `json:"ask_depth_2pct" db:"ask_depth_2pct"`
+	Imbalance    float64 `json:"imbalance" db:"imbalance"`
+}
+
+type OrderBookSnapshot struct {
+	ID        int64     `json:"id" db:"id"`
+	PairID    int64     `json:"pair_id" db:"pair_id"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	DepthMetrics
+	Bids []OrderBookLevel `json:"bids" db:"bids"`
+	Asks []OrderBookLevel `json:"asks" db:"asks"`
+}
+
+type Trade struct {
+	ID        int64     `json:"id" db:"id"`
+	Pair      string    `json:"pair" db:"pair"`
+	TradeID   int64     `json:"trade_id" db:"trade_id"`
+	Price     float64   `json:"price" db:"price"`
+	Volume    float64   `json:"volume" db:"volume"`
+	Side      string    `json:"side" db:"side"`
+	OrderType string    `json:"order_type" db:"order_type"`
+	Misc      string    `json:"misc" db:"misc"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+}
+
+// TradeAggregate sums the trades of one side, over a whole range or over
+// one bucket of it.
+type TradeAggregate struct {
+	Start    time.Time
+	Side     string
+	Count    int
+	Volume   float64
+	Notional float64
+}
+
+type SpreadPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	Mid       float64   `json:"mid"`
+	Spread    float64   `json:"spread"`
+	SpreadBps float64   `json:"spread_bps"`
+}
+
+type Portfolio struct {
+	ID           int64     `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	BaseCurrency string    `json:"base_currency" db:"base_currency"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type Holding struct {
+	ID          int64     `json:"id" db:"id"`
+	PortfolioID int64     `json:"portfolio_id" db:"portfolio_id"`
+	Asset       string    `json:"asset" db:"asset"`
+	Quantity    float64   `json:"quantity" db:"quantity"`
+	CostBasis   float64   `json:"cost_basis" db:"cost_basis"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type PairPrice struct {
+	Source    string    `json:"source"`
+	Name      string    `json:"name"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Price     float64   `json:"price"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Transaction struct {
+	ID          int64     `json:"id" db:"id"`
+	PortfolioID int64     `json:"portfolio_id" db:"portfolio_id"`
+	Time        time.Time `json:"time" db:"time"`
+	Type        string    `json:"type" db:"type"`
+	Asset       string    `json:"asset" db:"asset"`
+	Quantity    float64   `json:"quantity" db:"quantity"`
+	Price       float64   `json:"price" db:"price"`
+	Quote       string    `json:"quote" db:"quote"`
+	Fee         float64   `json:"fee" db:"fee"`
+	FeeAsset    string    `json:"fee_asset" db:"fee_asset"`
+	RefID       string    `json:"refid" db:"refid"`
+	ExternalID  string    `json:"external_id,omitempty" db:"external_id"`
+	Source      string    `json:"source" db:"source"`
+}
+
+type PaperAccount struct {
+	ID            int64     `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	InitialValue  float64   `json:"initial_value" db:"initial_value"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+type PaperOrder struct {
+	ID         int64      `json:"id" db:"id"`
+	AccountID  int64      `json:"account_id" db:"account_id"`
+	Pair       string     `json:"pair" db:"pair"`
+	Base       string     `json:"base" db:"base"`
+	Quote      string     `json:"quote" db:"quote"`
+	Side       string     `json:"side" db:"side"`
+	Type       string     `json:"type" db:"type"`
+	Quantity   float64    `json:"quantity" db:"quantity"`
+	LimitPrice float64    `json:"limit_price,omitempty" db:"limit_price"`
+	StopPrice  float64    `json:"stop_price,omitempty" db:"stop_price"`
+	Status     string     `json:"status" db:"status"`
+	Reason     string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+}
+
+type PaperFill struct {
+	ID        int64     `json:"id" db:"id"`
+	OrderID   int64     `json:"order_id" db:"order_id"`
+	AccountID int64     `json:"account_id" db:"account_id"`
+	Pair      string    `json:"pair" db:"pair"`
+	Side      string    `json:"side" db:"side"`
+	Quantity  float64   `json:"quantity" db:"quantity"`
+	Price     float64   `json:"price" db:"price"`
+	Fee       float64   `json:"fee" db:"fee"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+}
+
+type AccountBalance struct {
+	ID        int64     `json:"id" db:"id"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Asset     string    `json:"asset" db:"asset"`
+	Amount    float64   `json:"amount" db:"amount"`
+}
+
+type LedgerEntry struct {
+	ID      string    `json:"id" db:"id"`
+	RefID   string    `json:"refid" db:"refid"`
+	Time    time.Time `json:"time" db:"time"`
+	Type    string    `json:"type" db:"type"`
+	Subtype string    `json:"subtype,omitempty" db:"subtype"`
+	AClass  string    `json:"aclass,omitempty" db:"aclass"`
+	Asset   string    `json:"asset" db:"asset"`
+	Amount  float64   `json:"amount" db:"amount"`
+	Fee     float64   `json:"fee" db:"fee"`
+	Balance float64   `json:"balance" db:"balance"`
+}
+
+// LedgerTotal summarizes the stored ledger entries of one asset.
+type LedgerTotal struct {
+	Asset       string    `json:"asset"`
+	Entries     int       `json:"entries"`
+	Net         float64   `json:"net"`
+	LastBalance float64   `json:"last_balance"`
+	LastTime    time.Time `json:"last_time"`
+}
+
+// SpreadAlert records a cross-venue price gap above the alert threshold.
+type SpreadAlert struct {
+	ID         int64     `json:"id" db:"id"`
+	Symbol     string    `json:"symbol" db:"symbol"`
+	Timestamp  time.Time `json:"timestamp" db:"timestamp"`
+	HighSource string    `json:"high_source" db:"high_source"`
+	HighPrice  float64   `json:"high_price" db:"high_price"`
+	LowSource  string    `json:"low_source" db:"low_source"`
+	LowPrice   float64   `json:"low_price" db:"low_price"`
+	SpreadBps  float64   `json:"spread_bps" db:"spread_bps"`
+}
+
+// IndexComponent is one venue price used to build a reference price, with
+// its conversion to the index quote currency.
+type IndexComponent struct {
+	Source    string    `json:"source"`
+	Pair      string    `json:"pair"`
+	Quote     string    `json:"quote"`
+	Price     float64   `json:"price"`
+	Rate      float64   `json:"rate"`
+	Converted float64   `json:"converted"`
+	Volume    float64   `json:"volume"`
+	Timestamp time.Time `json:"timestamp"`
+	Excluded  string    `json:"excluded,omitempty"`
+}
+
+type IndexPoint struct {
+	ID         int64            `json:"id" db:"id"`
+	Asset      string           `json:"asset" db:"asset"`
+	Quote      string           `json:"quote" db:"quote"`
+	Timestamp  time.Time        `json:"timestamp" db:"timestamp"`
+	VWAP       float64          `json:"vwap" db:"vwap"`
+	Median     float64          `json:"median" db:"median"`
+	Used       int              `json:"used" db:"used"`
+	Rejected   int              `json:"rejected" db:"rejected"`
+	Components []IndexComponent `json:"components,omitempty" db:"components"`
+}
+
+// APIKey is a client key of the HTTP API. Only the SHA-256 hash of the key
+// is stored; Prefix is its first characters, shown to identify it.
+type APIKey struct {
+	ID         int64      `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Hash       string     `json:"-" db:"hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// APIKeyUsage counts the requests made with a key on one route during one
+// UTC day (YYYY-MM-DD), and how many of them failed.
+type APIKeyUsage struct {
+	KeyID    int64  `json:"key_id" db:"key_id"`
+	Day      string `json:"day" db:"day"`
+	Route    string `json:"route" db:"route"`
+	Requests int64  `json:"requests" db:"requests"`
+	Errors   int64  `json:"errors" db:"errors"`
+}
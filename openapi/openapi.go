@@ -0,0 +1,208 @@
+// Package openapi describes the routes of the HTTP API in an OpenAPI 3.1
+// document, served at /openapi.json with a Swagger UI at /docs. The schemas
+// are built from the types of the client package.
+package openapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/client"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyScheme = "apiKey"
+	bearerScheme = "bearerAuth"
+)
+
+// Build documents the routes registered on the engine. A route missing from
+// the catalog is listed without parameters nor schema and a warning is
+// logged, so that a new route shows up until it is described. scopeFor
+// gives the scope required by a route; it is nil when authentication is
+// disabled.
+func Build(routes gin.RoutesInfo, scopeFor func(method, route string) string) *Document {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "Go Crypto Price API",
+			Version:     "1.0.0",
+			Description: "Cryptocurrency prices collected from Kraken and other exchanges, with analytics, portfolios and paper trading.",
+		},
+		Tags:  tags,
+		Paths: make(map[string]map[string]*Operation),
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	for _, route := range routes {
+		scope := ""
+		if scopeFor != nil {
+			scope = scopeFor(route.Method, route.Path)
+		}
+		path, params := convertPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = s.operation(route, params, scope)
+	}
+
+	s.of(reflect.TypeOf(client.ErrorResponse{}))
+	s.components["ErrorResponse"].Properties["error"].Description = "Message, or list of messages"
+	doc.Components.Schemas = s.components
+	if scopeFor != nil {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			apiKeyScheme: {Type: "apiKey", In: "header", Name: client.APIKeyHeader, Description: "API key created with the keys command"},
+			bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "JWT, or an API key as a bearer token"},
+		}
+	}
+	return doc
+}
+
+func (s *schemas) operation(route gin.RouteInfo, params []string, scope string) *Operation {
+	desc, ok := operations[route.Method+" "+route.Path]
+	if !ok {
+		slog.Warn("route absente de la documentation OpenAPI", "method", route.Method, "route", route.Path)
+		desc = operation{summary: "Route non documentée"}
+	}
+
+	op := &Operation{
+		OperationID: desc.id,
+		Summary:     desc.summary,
+		Description: desc.description,
+		Responses:   make(map[string]*Response),
+	}
+	if desc.tag != "" {
+		op.Tags = []string{desc.tag}
+	}
+
+	for _, name := range params {
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        name,
+			In:          "path",
+			Description: pathParameters[name],
+			Required:    true,
+			Schema:      schema,
+		})
+	}
+	op.Parameters = append(op.Parameters, s.queryParameters(desc.query)...)
+
+	switch {
+	case desc.body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: s.of(reflect.TypeOf(desc.body))}},
+		}
+	case desc.bodyType != "":
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{desc.bodyType: {Schema: &Schema{Type: "string"}}},
+		}
+	}
+
+	status := desc.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case desc.response != nil:
+		success.Content = map[string]*MediaType{"application/json": {Schema: s.of(reflect.TypeOf(desc.response))}}
+	case desc.produces != "":
+		success.Content = map[string]*MediaType{desc.produces: {Schema: &Schema{Type: "string"}}}
+	case ok && status != http.StatusNoContent:
+		success.Content = map[string]*MediaType{"application/json": {Schema: &Schema{}}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorContent := map[string]*MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}}}
+	if strings.HasPrefix(route.Path, "/api/") {
+		op.Responses["default"] = &Response{Description: "Error", Content: errorContent}
+		op.Responses["429"] = &Response{
+			Description: "Rate limit or daily quota exceeded",
+			Headers: map[string]*Header{
+				"Retry-After": {Description: "Seconds to wait", Schema: &Schema{Type: "integer"}},
+			},
+			Content: errorContent,
+		}
+	}
+	if scope != "" {
+		op.Description = strings.TrimSpace(op.Description + " Requires the " + scope + " scope.")
+		op.Security = []map[string][]string{
+			{apiKeyScheme: {scope}},
+			{bearerScheme: {scope}},
+		}
+		op.Responses["401"] = &Response{Description: "Missing or invalid credentials", Content: errorContent}
+		op.Responses["403"] = &Response{Description: "Missing scope", Content: errorContent}
+	}
+	return op
+}
+
+// convertPath turns the Gin parameters of a route, ":pair" or "*path",
+// into OpenAPI templates and returns their names.
+func convertPath(route string) (string, []string) {
+	segments := strings.Split(route, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// Register serves the document of the routes registered on r, its own
+// included, at /openapi.json and the Swagger UI at /docs. It must be called
+// once every route is registered.
+func Register(r *gin.Engine, scopeFor func(method, route string) string) error {
+	var body []byte
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	})
+	r.GET("/docs", DocsHandler("/openapi.json"))
+
+	var err error
+	body, err = json.Marshal(Build(r.Routes(), scopeFor))
+	return err
+}
+
+// DocsHandler serves a Swagger UI, loaded from a CDN, that reads the
+// document at specURL.
+func DocsHandler(specURL string) gin.HandlerFunc {
+	page := strings.Replace(swaggerUI, "{{SPEC_URL}}", strconv.Quote(specURL), 1)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
+
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Go Crypto Price API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: {{SPEC_URL}}, dom_id: "#swagger-ui", persistAuthorization: true});
+  </script>
+</body>
+</html>
+`
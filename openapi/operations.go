@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/client"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// operation documents a route. Its query, body and response are values of
+// the client package types, so that the document and the Go client cannot
+// disagree; id is the name of the client method, in lower camel case.
+type operation struct {
+	id          string
+	summary     string
+	description string
+	tag         string
+	query       any
+	body        any
+	// bodyType is the media type of a body that is not JSON.
+	bodyType string
+	// status is the status of a successful response, 200 by default.
+	status   int
+	response any
+	// produces is the media type of a response that is not JSON.
+	produces string
+}
+
+var tags = []Tag{
+	{Name: "health", Description: "Liveness, readiness and metrics"},
+	{Name: "market", Description: "Kraken status, pairs and the market data collected from Kraken"},
+	{Name: "analytics", Description: "Statistics and correlations computed from the stored candles"},
+	{Name: "portfolios", Description: "Portfolios, holdings, transactions and tax lots"},
+	{Name: "backtests", Description: "Strategy backtests over the stored candles"},
+	{Name: "paper", Description: "Paper trading accounts and orders"},
+	{Name: "account", Description: "Kraken account balances and ledger"},
+	{Name: "exchanges", Description: "Market data of every venue, cross-exchange comparison and price index"},
+	{Name: "data", Description: "Raw stored data"},
+	{Name: "docs", Description: "This document"},
+}
+
+const cachedDescription = "Cached: see the X-Cache, Age and ETag headers; a matching If-None-Match gets a 304."
+
+// operations is keyed by method and Gin route.
+var operations = map[string]operation{
+	"GET /metrics": {
+		id: "metrics", summary: "Prometheus metrics", tag: "health",
+		produces: "text/plain",
+	},
+	"GET /healthz": {
+		id: "health", summary: "Liveness probe", tag: "health",
+		response: client.Health{},
+	},
+	"GET /readyz": {
+		id: "ready", summary: "Readiness probe",
+		description: "Checks the database, the age of the last collection and Kraken. Answers 503, with the same body, when the service is not ready.",
+		tag:         "health", response: client.Readiness{},
+	},
+	"GET /openapi.json": {
+		id: "openAPI", summary: "This OpenAPI document", tag: "docs",
+	},
+	"GET /docs": {
+		id: "docs", summary: "Swagger UI", tag: "docs", produces: "text/html",
+	},
+
+	"GET /api/status": {
+		id: "serverStatus", summary: "Kraken server time", tag: "market",
+		description: cachedDescription, response: client.ServerTime{},
+	},
+	"GET /api/status/history": {
+		id: "statusHistory", summary: "Timeline of Kraken's system status", tag: "market",
+		query: client.TimeRange{}, response: client.StatusHistory{},
+	},
+	"GET /api/pairs": {
+		id: "tradingPairs", summary: "Top 10 pairs by 24 hours volume", tag: "market",
+		description: cachedDescription + " Served from the stored data, with stale set, when Kraken fails.",
+		response:    client.TradingPairs{},
+	},
+	"GET /api/pairs/:pair": {
+		id: "pairTicker", summary: "Live ticker of a pair", tag: "market",
+		description: cachedDescription + " Served from the stored data, with stale set, when Kraken fails.",
+		response:    client.PairTicker{},
+	},
+	"GET /api/pairs/:pair/depth": {
+		id: "depth", summary: "Live order book and depth metrics", tag: "market",
+		query: client.DepthQuery{}, response: client.Depth{},
+	},
+	"GET /api/pairs/:pair/depth/history": {
+		id: "depthHistory", summary: "Stored order book snapshots", tag: "market",
+		query: client.LimitQuery{}, response: client.DepthHistory{},
+	},
+	"GET /api/pairs/:pair/trades": {
+		id: "pairTrades", summary: "Stored trades", tag: "market",
+		query: client.TradesQuery{}, response: client.PairTrades{},
+	},
+	"GET /api/pairs/:pair/trades/volume": {
+		id: "tradeVolume", summary: "Buy and sell volume", tag: "market",
+		query: client.BucketQuery{}, response: client.TradeVolume{},
+	},
+	"GET /api/pairs/:pair/trades/large": {
+		id: "largeTrades", summary: "Large trades", tag: "market",
+		query: client.LargeTradesQuery{}, response: client.LargeTrades{},
+	},
+	"GET /api/pairs/:pair/vwap": {
+		id: "vwap", summary: "Volume-weighted average price", tag: "market",
+		query: client.BucketQuery{}, response: client.VWAP{},
+	},
+	"GET /api/pairs/:pair/spread": {
+		id: "spread", summary: "Bid-ask spread series", tag: "market",
+		query: client.TimeRange{}, response: client.Spread{},
+	},
+	"GET /api/pairs/:pair/indicators": {
+		id: "indicators", summary: "Technical indicators", tag: "analytics",
+		query: client.IndicatorsQuery{}, response: client.Indicators{},
+	},
+	"GET /api/pairs/:pair/stats": {
+		id: "pairStats", summary: "Return and volatility statistics of a pair", tag: "analytics",
+		query: client.StatsQuery{}, response: client.PairStats{},
+	},
+	"GET /api/stats": {
+		id: "stats", summary: "Statistics of several pairs", tag: "analytics",
+		description: "At most 20 pairs. Answers 404 when a pair has no stored candle in the window.",
+		query:       client.MultiStatsQuery{}, response: client.Stats{},
+	},
+	"GET /api/correlation": {
+		id: "correlation", summary: "Correlation of the returns of pairs", tag: "analytics",
+		query: client.CorrelationQuery{}, response: client.Correlation{},
+	},
+
+	"POST /api/portfolios": {
+		id: "createPortfolio", summary: "Create a portfolio", tag: "portfolios",
+		body: client.PortfolioRequest{}, status: http.StatusCreated, response: models.Portfolio{},
+	},
+	"GET /api/portfolios": {
+		id: "portfolios", summary: "List the portfolios", tag: "portfolios",
+		response: client.Portfolios{},
+	},
+	"GET /api/portfolios/:id": {
+		id: "portfolio", summary: "Portfolio and its holdings", tag: "portfolios",
+		response: client.PortfolioDetail{},
+	},
+	"PUT /api/portfolios/:id": {
+		id: "updatePortfolio", summary: "Rename a portfolio or change its base currency", tag: "portfolios",
+		description: "The base currency cannot change once the portfolio has transactions: the request then gets a 409.",
+		body:        client.PortfolioRequest{}, response: models.Portfolio{},
+	},
+	"DELETE /api/portfolios/:id": {
+		id: "deletePortfolio", summary: "Delete a portfolio", tag: "portfolios",
+		status: http.StatusNoContent,
+	},
+	"POST /api/portfolios/:id/holdings": {
+		id: "createHolding", summary: "Add a holding", tag: "portfolios",
+		body: client.HoldingRequest{}, status: http.StatusCreated, response: models.Holding{},
+	},
+	"PUT /api/portfolios/:id/holdings/:holding_id": {
+		id: "updateHolding", summary: "Update the quantity and cost basis of a holding", tag: "portfolios",
+		body: client.HoldingRequest{}, response: models.Holding{},
+	},
+	"DELETE /api/portfolios/:id/holdings/:holding_id": {
+		id: "deleteHolding", summary: "Delete a holding", tag: "portfolios",
+		status: http.StatusNoContent,
+	},
+	"GET /api/portfolios/:id/valuation": {
+		id: "valuation", summary: "Value and P&L of the holdings", tag: "portfolios",
+		query: client.ValuationQuery{}, response: client.Valuation{},
+	},
+	"POST /api/portfolios/:id/transactions": {
+		id: "createTransaction", summary: "Record a transaction", tag: "portfolios",
+		body: client.TransactionRequest{}, status: http.StatusCreated, response: models.Transaction{},
+	},
+	"GET /api/portfolios/:id/transactions": {
+		id: "transactions", summary: "List the transactions", tag: "portfolios",
+		response: client.Transactions{},
+	},
+	"DELETE /api/portfolios/:id/transactions/:tx_id": {
+		id: "deleteTransaction", summary: "Delete a transaction", tag: "portfolios",
+		status: http.StatusNoContent,
+	},
+	"POST /api/portfolios/:id/transactions/import": {
+		id: "importTransactions", summary: "Import transactions from a CSV file", tag: "portfolios",
+		description: "The CSV is the request body, or the file field of a multipart form. Transactions already imported are skipped. The holdings of the portfolio are not updated.",
+		query:       client.ImportQuery{}, bodyType: "text/csv", response: client.ImportResult{},
+	},
+	"GET /api/portfolios/:id/lots": {
+		id: "lots", summary: "Tax lots and realized gains", tag: "portfolios",
+		query: client.LotsQuery{}, response: client.LotReport{},
+	},
+
+	"GET /api/backtests/strategies": {
+		id: "backtestStrategies", summary: "List the strategies", tag: "backtests",
+		response: client.BacktestStrategies{},
+	},
+	"POST /api/backtests": {
+		id: "runBacktest", summary: "Run a backtest", tag: "backtests",
+		body: client.BacktestRequest{}, response: client.BacktestResult{},
+	},
+
+	"POST /api/paper/accounts": {
+		id: "createPaperAccount", summary: "Create a paper trading account", tag: "paper",
+		body: client.PaperAccountRequest{}, status: http.StatusCreated, response: models.PaperAccount{},
+	},
+	"GET /api/paper/accounts": {
+		id: "paperAccounts", summary: "List the paper trading accounts", tag: "paper",
+		response: client.PaperAccounts{},
+	},
+	"GET /api/paper/accounts/:id": {
+		id: "paperAccount", summary: "Balances and P&L of an account", tag: "paper",
+		response: client.PaperAccountDetail{},
+	},
+	"GET /api/paper/accounts/:id/fills": {
+		id: "paperFills", summary: "Fills of an account", tag: "paper",
+		response: client.PaperFills{},
+	},
+	"POST /api/paper/orders": {
+		id: "createPaperOrder", summary: "Place an order", tag: "paper",
+		description: "A rejected order gets a 400 whose error is the list of reasons.",
+		body:        client.PaperOrderRequest{}, status: http.StatusCreated, response: models.PaperOrder{},
+	},
+	"GET /api/paper/orders": {
+		id: "paperOrders", summary: "List the orders of an account", tag: "paper",
+		query: client.PaperOrdersQuery{}, response: client.PaperOrders{},
+	},
+	"DELETE /api/paper/orders/:id": {
+		id: "cancelPaperOrder", summary: "Cancel an open order", tag: "paper",
+		response: models.PaperOrder{},
+	},
+
+	"POST /api/account/sync": {
+		id: "syncAccount", summary: "Sync the balances and ledger from Kraken", tag: "account",
+		description: "Answers 503 when the Kraken API keys are not configured, and 409 while another sync is running.",
+		response:    client.AccountSync{},
+	},
+	"GET /api/account/balances": {
+		id: "accountBalances", summary: "Latest balances and their value", tag: "account",
+		query: client.QuoteQuery{}, response: client.AccountBalances{},
+	},
+	"GET /api/account/history": {
+		id: "accountHistory", summary: "Value of the account over time", tag: "account",
+		query: client.AccountHistoryQuery{}, response: client.AccountHistory{},
+	},
+	"GET /api/account/ledger": {
+		id: "accountLedger", summary: "Stored ledger entries", tag: "account",
+		query: client.LedgerQuery{}, response: client.Ledger{},
+	},
+	"GET /api/account/reconciliation": {
+		id: "accountReconciliation", summary: "Reconcile the balances with the ledger", tag: "account",
+		response: client.Reconciliation{},
+	},
+
+	"GET /api/exchanges": {
+		id: "exchanges", summary: "List the enabled exchanges", tag: "exchanges",
+		response: client.Exchanges{},
+	},
+	"GET /api/exchanges/:exchange/markets": {
+		id: "exchangeMarkets", summary: "Markets of an exchange", tag: "exchanges",
+		query: client.MarketsQuery{}, response: client.Markets{},
+	},
+	"GET /api/exchanges/:exchange/ticker": {
+		id: "exchangeTicker", summary: "Live ticker on an exchange", tag: "exchanges",
+		query: client.SymbolQuery{}, response: client.VenueTicker{},
+	},
+	"GET /api/exchanges/:exchange/ohlc": {
+		id: "exchangeOHLC", summary: "Candles on an exchange", tag: "exchanges",
+		query: client.OHLCQuery{}, response: client.VenueCandles{},
+	},
+	"GET /api/exchanges/:exchange/depth": {
+		id: "exchangeDepth", summary: "Order book on an exchange", tag: "exchanges",
+		query: client.VenueDepthQuery{}, response: client.VenueOrderBook{},
+	},
+	"GET /api/exchanges/:exchange/trades": {
+		id: "exchangeTrades", summary: "Recent trades on an exchange", tag: "exchanges",
+		query: client.VenueTradesQuery{}, response: client.VenueTrades{},
+	},
+	"GET /api/compare/:asset": {
+		id: "comparePrices", summary: "Compare the live prices of an asset across exchanges", tag: "exchanges",
+		query: client.CompareQuery{}, response: client.PriceComparison{},
+	},
+	"GET /api/compare/:asset/history": {
+		id: "compareHistory", summary: "Cross-exchange spread over time", tag: "exchanges",
+		query: client.CompareHistoryQuery{}, response: client.CompareHistory{},
+	},
+	"GET /api/compare/:asset/alerts": {
+		id: "spreadAlerts", summary: "Cross-exchange spread alerts", tag: "exchanges",
+		query: client.SpreadAlertsQuery{}, response: client.SpreadAlerts{},
+	},
+	"GET /api/index/:asset": {
+		id: "priceIndex", summary: "Reference price of an asset", tag: "exchanges",
+		query: client.IndexQuery{}, response: client.PriceIndex{},
+	},
+
+	"GET /api/historical": {
+		id: "historicalCSV", summary: "Download the CSV export of a day", tag: "data",
+		query: client.HistoricalQuery{}, produces: "text/csv",
+	},
+	"GET /api/db": {
+		id: "dbData", summary: "Every stored pair with its tickers and candles", tag: "data",
+		response: client.DBData{},
+	},
+}
+
+// pathParameters describes the path parameters of the routes.
+var pathParameters = map[string]string{
+	"pair":       "Kraken pair name, e.g. XXBTZUSD",
+	"id":         "Identifier",
+	"holding_id": "Holding identifier",
+	"tx_id":      "Transaction identifier",
+	"exchange":   "Exchange name, e.g. kraken",
+	"asset":      "Asset code, e.g. BTC",
+}
@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/client"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas builds the JSON schemas of Go types as encoding/json marshals
+// them. Named structs become components referenced with $ref, and
+// embedded structs are flattened into their parent.
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		types:      make(map[string]reflect.Type),
+	}
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// component registers the schema of a named struct once. Two types with
+// the same name would overwrite each other's schema.
+func (s *schemas) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if known, ok := s.types[name]; ok && known != t {
+		panic(fmt.Sprintf("openapi: %s et %s ont le même nom", known, t))
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := s.types[name]; ok {
+		return ref
+	}
+	s.types[name] = t
+	s.components[name] = s.object(t)
+	return ref
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the fields of t to schema. A field is required unless it
+// is omitempty; pointers, slices and maps that are not omitempty may be
+// null.
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(schema, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		field := s.of(ft)
+		if hasOption(options, "omitempty") {
+			schema.Properties[name] = field
+			continue
+		}
+		switch ft.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			field = nullable(field)
+		}
+		schema.Properties[name] = field
+		schema.Required = append(schema.Required, name)
+	}
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	}
+	if typ, ok := schema.Type.(string); ok {
+		copied := *schema
+		copied.Type = []string{typ, "null"}
+		return &copied
+	}
+	return schema
+}
+
+// queryParameters documents the fields of a query struct of the client
+// package, with their "query" and "doc" tags.
+func (s *schemas) queryParameters(query any) []Parameter {
+	if query == nil {
+		return nil
+	}
+	return s.appendQueryParameters(nil, reflect.TypeOf(query))
+}
+
+func (s *schemas) appendQueryParameters(params []Parameter, t reflect.Type) []Parameter {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = s.appendQueryParameters(params, f.Type)
+			continue
+		}
+		name, required := client.QueryName(f)
+		if name == "" {
+			continue
+		}
+		schema := s.of(f.Type)
+		// Lists are sent comma-separated.
+		if f.Type.Kind() == reflect.Slice {
+			schema = &Schema{Type: "string"}
+		}
+		params = append(params, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: f.Tag.Get("doc"),
+			Required:    required,
+			Schema:      schema,
+		})
+	}
+	return params
+}
@@ -0,0 +1,362 @@
+package paper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+
+	TypeMarket = "market"
+	TypeLimit  = "limit"
+	TypeStop   = "stop"
+
+	StatusOpen     = "open"
+	StatusFilled   = "filled"
+	StatusCanceled = "canceled"
+	StatusRejected = "rejected"
+)
+
+// OrderError is a validation error worded like Kraken's AddOrder errors.
+type OrderError string
+
+func (e OrderError) Error() string {
+	return string(e)
+}
+
+var (
+	errUnknownPair       = OrderError("EQuery:Unknown asset pair")
+	errInvalidType       = OrderError("EGeneral:Invalid arguments:type")
+	errInvalidOrderType  = OrderError("EGeneral:Invalid arguments:ordertype")
+	errInvalidVolume     = OrderError("EGeneral:Invalid arguments:volume")
+	errInvalidPrice      = OrderError("EGeneral:Invalid arguments:price")
+	errOrderMinimum      = OrderError("EOrder:Order minimum not met")
+	errCostMinimum       = OrderError("EOrder:Cost minimum not met")
+	errInsufficientFunds = OrderError("EOrder:Insufficient funds")
+	errOrderClosed       = OrderError("EOrder:Order already closed")
+)
+
+type OrderRequest struct {
+	AccountID  int64
+	Pair       string
+	Side       string
+	Type       string
+	Quantity   float64
+	LimitPrice float64
+	StopPrice  float64
+}
+
+// Engine validates paper orders and matches the open ones against the
+// ticker snapshots captured by the collector.
+type Engine struct {
+	db    *database.DB
+	rules *rulesCache
+
+	// mu serializes order placement and matching so that balance checks
+	// and fills never interleave.
+	mu sync.Mutex
+}
+
+func NewEngine(db *database.DB, client *kraken.Client) *Engine {
+	return &Engine{
+		db:    db,
+		rules: newRulesCache(client),
+	}
+}
+
+// PlaceOrder validates the request and stores it as an open order. Market
+// orders are filled on the next ticker snapshot.
+func (e *Engine) PlaceOrder(req OrderRequest) (*models.PaperOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.db.GetPaperAccount(req.AccountID); err != nil {
+		return nil, err
+	}
+
+	req.Side = strings.ToLower(req.Side)
+	req.Type = strings.ToLower(req.Type)
+	if req.Type == "stop-loss" {
+		req.Type = TypeStop
+	}
+	if req.Side != SideBuy && req.Side != SideSell {
+		return nil, errInvalidType
+	}
+
+	rules, err := e.rules.lookup(req.Pair)
+	if err != nil {
+		return nil, err
+	}
+	if rules.Status != "" && rules.Status != "online" {
+		return nil, OrderError(fmt.Sprintf("EService:Market in %s mode", rules.Status))
+	}
+
+	if req.Quantity <= 0 || decimals(req.Quantity) > rules.LotDecimals {
+		return nil, errInvalidVolume
+	}
+	if req.Quantity < rules.OrderMin {
+		return nil, errOrderMinimum
+	}
+
+	var price float64
+	switch req.Type {
+	case TypeMarket:
+		req.LimitPrice, req.StopPrice = 0, 0
+		price, err = e.lastPrice(rules.Pair)
+		if err != nil {
+			return nil, err
+		}
+	case TypeLimit:
+		if req.LimitPrice <= 0 || decimals(req.LimitPrice) > rules.PairDecimals {
+			return nil, errInvalidPrice
+		}
+		req.StopPrice = 0
+		price = req.LimitPrice
+	case TypeStop:
+		if req.StopPrice <= 0 || decimals(req.StopPrice) > rules.PairDecimals {
+			return nil, errInvalidPrice
+		}
+		req.LimitPrice = 0
+		price = req.StopPrice
+	default:
+		return nil, errInvalidOrderType
+	}
+
+	if price > 0 && req.Quantity*price < rules.CostMin {
+		return nil, errCostMinimum
+	}
+
+	if err := e.checkFunds(req, rules, price); err != nil {
+		return nil, err
+	}
+
+	order := &models.PaperOrder{
+		AccountID:  req.AccountID,
+		Pair:       rules.Pair,
+		Base:       rules.Base,
+		Quote:      rules.Quote,
+		Side:       req.Side,
+		Type:       req.Type,
+		Quantity:   req.Quantity,
+		LimitPrice: req.LimitPrice,
+		StopPrice:  req.StopPrice,
+		Status:     StatusOpen,
+	}
+	if err := e.db.CreatePaperOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelOrder cancels an open order.
+func (e *Engine) CancelOrder(id int64) (*models.PaperOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, err := e.db.GetPaperOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != StatusOpen {
+		return nil, errOrderClosed
+	}
+	if err := e.db.ClosePaperOrder(id, StatusCanceled, ""); err != nil {
+		return nil, err
+	}
+	return e.db.GetPaperOrder(id)
+}
+
+// lastPrice returns the latest stored ticker price of a pair, or zero when
+// the pair has never been collected.
+func (e *Engine) lastPrice(pair string) (float64, error) {
+	prices, err := e.db.GetLatestPairPricesFromDB()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range prices {
+		if p.Source == database.SourceKraken && p.Name == pair {
+			return p.Price, nil
+		}
+	}
+	return 0, nil
+}
+
+// checkFunds makes sure the available balance, i.e. the balance minus what
+// the other open orders of the account reserve, covers the new order.
+// Market buys are estimated with the last stored price and the taker fee.
+func (e *Engine) checkFunds(req OrderRequest, rules *Rules, price float64) error {
+	balances, err := e.db.GetPaperBalances(req.AccountID)
+	if err != nil {
+		return err
+	}
+	open, err := e.db.GetPaperOrders(req.AccountID, StatusOpen)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := e.reserved(open, rules.Pair, price)
+	if err != nil {
+		return err
+	}
+	asset, required := reservation(req.Side, req.Type, rules.Base, rules.Quote, req.Quantity, price, rules)
+	available := balances[asset] - reserved[asset]
+	if required > available {
+		return errInsufficientFunds
+	}
+	return nil
+}
+
+// Reserved returns the amounts the open orders of an account lock, per
+// asset. Open market orders are valued with the last stored price of their
+// pair.
+func (e *Engine) Reserved(accountID int64) (map[string]float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	open, err := e.db.GetPaperOrders(accountID, StatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	return e.reserved(open, "", 0)
+}
+
+// reserved sums the reservations of open orders per asset. Market orders on
+// pair are valued at price, the others at the last stored price of their
+// pair. Orders on pairs that are no longer listed reserve nothing.
+func (e *Engine) reserved(open []models.PaperOrder, pair string, price float64) (map[string]float64, error) {
+	amounts := make(map[string]float64)
+	for _, o := range open {
+		orderRules, err := e.rules.lookup(o.Pair)
+		if err != nil {
+			continue
+		}
+		orderPrice := o.LimitPrice
+		if o.Type == TypeStop {
+			orderPrice = o.StopPrice
+		}
+		if o.Type == TypeMarket {
+			orderPrice = price
+			if o.Pair != pair {
+				if orderPrice, err = e.lastPrice(o.Pair); err != nil {
+					return nil, err
+				}
+			}
+		}
+		asset, amount := reservation(o.Side, o.Type, o.Base, o.Quote, o.Quantity, orderPrice, orderRules)
+		amounts[asset] += amount
+	}
+	return amounts, nil
+}
+
+// reservation returns the asset and amount an order locks until it is
+// filled or canceled.
+func reservation(side, orderType, base, quote string, quantity, price float64, rules *Rules) (string, float64) {
+	if side == SideSell {
+		return base, quantity
+	}
+	return quote, quantity * price * (1 + feeRate(orderType, rules))
+}
+
+// feeRate returns the fee applied to an order type as a fraction: resting
+// limit orders pay the maker fee, market and stop orders the taker fee.
+func feeRate(orderType string, rules *Rules) float64 {
+	if orderType == TypeLimit {
+		return rules.MakerFee / 100
+	}
+	return rules.TakerFee / 100
+}
+
+// OnTicker matches the open orders of a pair against a ticker snapshot:
+// market orders fill at the ask (buy) or bid (sell), limit orders fill at
+// their limit price once the opposite side crosses it, and stop orders
+// trigger on the last trade price and fill at the ask or bid.
+func (e *Engine) OnTicker(pair string, bid, ask, last float64, at time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	orders, err := e.db.GetOpenPaperOrdersForPair(pair)
+	if err != nil || len(orders) == 0 {
+		return err
+	}
+
+	rules, err := e.rules.lookup(pair)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		price, ok := matchPrice(o, bid, ask, last)
+		if !ok {
+			continue
+		}
+		if err := e.fill(o, rules, price, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchPrice(o models.PaperOrder, bid, ask, last float64) (float64, bool) {
+	buy := o.Side == SideBuy
+	switch o.Type {
+	case TypeMarket:
+		if buy {
+			return ask, ask > 0
+		}
+		return bid, bid > 0
+	case TypeLimit:
+		if buy {
+			return o.LimitPrice, ask > 0 && ask <= o.LimitPrice
+		}
+		return o.LimitPrice, bid > 0 && bid >= o.LimitPrice
+	case TypeStop:
+		if buy {
+			return ask, ask > 0 && last >= o.StopPrice
+		}
+		return bid, bid > 0 && last > 0 && last <= o.StopPrice
+	}
+	return 0, false
+}
+
+func (e *Engine) fill(o models.PaperOrder, rules *Rules, price float64, at time.Time) error {
+	cost := o.Quantity * price
+	fee := cost * feeRate(o.Type, rules)
+
+	deltas := map[string]float64{}
+	if o.Side == SideBuy {
+		deltas[o.Quote] = -(cost + fee)
+		deltas[o.Base] = o.Quantity
+	} else {
+		deltas[o.Base] = -o.Quantity
+		deltas[o.Quote] = cost - fee
+	}
+
+	balances, err := e.db.GetPaperBalances(o.AccountID)
+	if err != nil {
+		return err
+	}
+	for asset, delta := range deltas {
+		if balances[asset]+delta < 0 {
+			return e.db.ClosePaperOrder(o.ID, StatusRejected, errInsufficientFunds.Error())
+		}
+	}
+
+	return e.db.FillPaperOrder(&models.PaperFill{
+		OrderID:   o.ID,
+		AccountID: o.AccountID,
+		Pair:      o.Pair,
+		Side:      o.Side,
+		Quantity:  o.Quantity,
+		Price:     price,
+		Fee:       fee,
+		Timestamp: at,
+	}, deltas)
+}
@@ -0,0 +1,214 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestEngine returns an engine whose rules come from a fake Kraken
+// listing XXBTZUSD: prices with one decimal, volumes with eight, a 0.0001
+// BTC order minimum, a 0.5 USD cost minimum, a 0.4% taker fee and a 0.25%
+// maker fee.
+func newTestEngine(t *testing.T, db *database.DB) *Engine {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/0/public/AssetPairs" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"error":[],"result":{"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","base":"XXBT","quote":"ZUSD",`+
+			`"pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001","costmin":"0.5",`+
+			`"fees":[[0,0.4]],"fees_maker":[[0,0.25]],"status":"online"}}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := kraken.NewClient()
+	client.SetBaseURL(server.URL + "/0")
+	return NewEngine(db, client)
+}
+
+func newTestAccount(t *testing.T, db *database.DB) int64 {
+	t.Helper()
+	account := &models.PaperAccount{Name: "test", QuoteCurrency: "USD", InitialValue: 10000}
+	if err := db.CreatePaperAccount(account); err != nil {
+		t.Fatal(err)
+	}
+	return account.ID
+}
+
+func TestPlaceOrderValidation(t *testing.T) {
+	db := newTestDB(t)
+	e := newTestEngine(t, db)
+	id := newTestAccount(t, db)
+
+	tests := []struct {
+		name string
+		req  OrderRequest
+		err  error
+	}{
+		{"unknown pair", OrderRequest{Pair: "FOOBAR", Side: "buy", Type: "limit", Quantity: 1, LimitPrice: 1}, errUnknownPair},
+		{"invalid side", OrderRequest{Pair: "XBTUSD", Side: "hold", Type: "limit", Quantity: 1, LimitPrice: 1}, errInvalidType},
+		{"invalid type", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "iceberg", Quantity: 1, LimitPrice: 1}, errInvalidOrderType},
+		{"zero volume", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", LimitPrice: 60000}, errInvalidVolume},
+		{"volume decimals", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.000100001, LimitPrice: 60000}, errInvalidVolume},
+		{"order minimum", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.00009, LimitPrice: 60000}, errOrderMinimum},
+		{"price decimals", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.01, LimitPrice: 60000.25}, errInvalidPrice},
+		{"missing stop price", OrderRequest{Pair: "XBTUSD", Side: "sell", Type: "stop-loss", Quantity: 0.01}, errInvalidPrice},
+		// 0.0001 × 4000 = 0.4 USD.
+		{"cost minimum", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.0001, LimitPrice: 4000}, errCostMinimum},
+		// 0.2 × 50000 plus the maker fee exceeds 10000 USD.
+		{"insufficient funds", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.2, LimitPrice: 50000}, errInsufficientFunds},
+		{"no base to sell", OrderRequest{Pair: "XBTUSD", Side: "sell", Type: "limit", Quantity: 0.01, LimitPrice: 60000}, errInsufficientFunds},
+		{"valid", OrderRequest{Pair: "XBT/USD", Side: "BUY", Type: "limit", Quantity: 0.1, LimitPrice: 60000}, nil},
+		// 6015 USD are reserved by the previous order.
+		{"reserved funds", OrderRequest{Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.1, LimitPrice: 60000}, errInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.AccountID = id
+			order, err := e.PlaceOrder(tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("PlaceOrder = %v, want %v", err, tt.err)
+			}
+			if err == nil && (order.Pair != "XXBTZUSD" || order.Base != "BTC" || order.Quote != "USD" || order.Side != SideBuy) {
+				t.Errorf("order %+v", order)
+			}
+		})
+	}
+
+	if _, err := e.PlaceOrder(OrderRequest{AccountID: id + 1, Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.1, LimitPrice: 1}); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("unknown account: %v, want ErrNotFound", err)
+	}
+}
+
+func TestMatchPrice(t *testing.T) {
+	order := func(side, orderType string, limit, stop float64) models.PaperOrder {
+		return models.PaperOrder{Side: side, Type: orderType, Quantity: 1, LimitPrice: limit, StopPrice: stop}
+	}
+
+	tests := []struct {
+		name           string
+		order          models.PaperOrder
+		bid, ask, last float64
+		price          float64
+		ok             bool
+	}{
+		{"market buy at the ask", order("buy", "market", 0, 0), 99, 101, 100, 101, true},
+		{"market sell at the bid", order("sell", "market", 0, 0), 99, 101, 100, 99, true},
+		{"market without quotes", order("buy", "market", 0, 0), 0, 0, 100, 0, false},
+		{"limit buy crossed", order("buy", "limit", 101, 0), 99, 101, 100, 101, true},
+		{"limit buy above the ask fills at its limit", order("buy", "limit", 105, 0), 99, 101, 100, 105, true},
+		{"limit buy not crossed", order("buy", "limit", 100, 0), 99, 101, 100, 100, false},
+		{"limit sell crossed", order("sell", "limit", 98, 0), 99, 101, 100, 98, true},
+		{"limit sell not crossed", order("sell", "limit", 100, 0), 99, 101, 100, 100, false},
+		{"stop buy triggered", order("buy", "stop", 0, 100), 99, 101, 100, 101, true},
+		{"stop buy waiting", order("buy", "stop", 0, 102), 99, 101, 100, 101, false},
+		{"stop sell triggered", order("sell", "stop", 0, 100), 99, 101, 100, 99, true},
+		{"stop sell waiting", order("sell", "stop", 0, 98), 99, 101, 100, 99, false},
+		{"stop sell without last price", order("sell", "stop", 0, 98), 99, 101, 0, 99, false},
+		{"unknown type", order("buy", "iceberg", 0, 0), 99, 101, 100, 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := matchPrice(tt.order, tt.bid, tt.ask, tt.last)
+		if ok != tt.ok || (ok && price != tt.price) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, price, ok, tt.price, tt.ok)
+		}
+	}
+}
+
+func TestOnTickerFills(t *testing.T) {
+	db := newTestDB(t)
+	e := newTestEngine(t, db)
+	id := newTestAccount(t, db)
+	at := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	buy, err := e.PlaceOrder(OrderRequest{AccountID: id, Pair: "XBTUSD", Side: "buy", Type: "limit", Quantity: 0.1, LimitPrice: 60000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.1 × 60000 plus the 0.25% maker fee.
+	if reserved, err := e.Reserved(id); err != nil || reserved["USD"] != 6015 {
+		t.Fatalf("reserved %v, %v, want 6015 USD", reserved, err)
+	}
+
+	if err := e.OnTicker("XXBTZUSD", 60000, 60010, 60005, at); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := db.GetPaperOrder(buy.ID); o.Status != StatusOpen {
+		t.Fatalf("order %s before the ask crossed its limit", o.Status)
+	}
+
+	if err := e.OnTicker("XXBTZUSD", 59980, 59990, 59985, at); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := db.GetPaperOrder(buy.ID); o.Status != StatusFilled {
+		t.Fatalf("order %s, want filled", o.Status)
+	}
+	balances, err := db.GetPaperBalances(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balances["USD"] != 3985 || balances["BTC"] != 0.1 {
+		t.Errorf("balances %v, want 3985 USD and 0.1 BTC", balances)
+	}
+	fills, err := db.GetPaperFills(id)
+	if err != nil || len(fills) != 1 || fills[0].Price != 60000 || fills[0].Fee != 15 {
+		t.Errorf("fills %+v, %v", fills, err)
+	}
+	if reserved, err := e.Reserved(id); err != nil || len(reserved) != 0 {
+		t.Errorf("reserved %v, %v after the fill", reserved, err)
+	}
+}
+
+// A fill that would overdraw a balance rejects the order instead.
+func TestFillRejectsOverdraft(t *testing.T) {
+	db := newTestDB(t)
+	e := newTestEngine(t, db)
+	id := newTestAccount(t, db)
+
+	order := &models.PaperOrder{AccountID: id, Pair: "XXBTZUSD", Base: "BTC", Quote: "USD", Side: SideSell,
+		Type: TypeMarket, Quantity: 1, Status: StatusOpen}
+	if err := db.CreatePaperOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := e.rules.lookup("XXBTZUSD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.fill(*order, rules, 60000, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := db.GetPaperOrder(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != StatusRejected || stored.Reason != errInsufficientFunds.Error() {
+		t.Errorf("order %s (%s), want rejected for insufficient funds", stored.Status, stored.Reason)
+	}
+	if balances, _ := db.GetPaperBalances(id); balances["USD"] != 10000 || balances["BTC"] != 0 {
+		t.Errorf("balances %v changed", balances)
+	}
+}
@@ -0,0 +1,120 @@
+package paper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/kraken"
+)
+
+// rulesTTL bounds how long the AssetPairs metadata is reused before being
+// fetched again.
+const rulesTTL = time.Hour
+
+// Rules are the trading constraints of a pair, as published by Kraken's
+// AssetPairs endpoint.
+type Rules struct {
+	Pair         string
+	Base         string
+	Quote        string
+	Status       string
+	PairDecimals int
+	LotDecimals  int
+	OrderMin     float64
+	CostMin      float64
+	TakerFee     float64 // percent
+	MakerFee     float64 // percent
+}
+
+type rulesCache struct {
+	client *kraken.Client
+
+	mu        sync.Mutex
+	byName    map[string]*Rules
+	fetchedAt time.Time
+}
+
+func newRulesCache(client *kraken.Client) *rulesCache {
+	return &rulesCache{client: client}
+}
+
+// lookup resolves a pair given by its Kraken name ("XXBTZUSD"), its altname
+// ("XBTUSD"), its websocket name ("XBT/USD") or normalized codes
+// ("BTC/USD").
+func (r *rulesCache) lookup(pair string) (*Rules, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byName == nil || time.Since(r.fetchedAt) > rulesTTL {
+		pairs, err := r.client.GetAssetPairs()
+		if err != nil {
+			if r.byName == nil {
+				return nil, fmt.Errorf("erreur lors de la récupération des paires: %v", err)
+			}
+		} else {
+			r.byName = indexRules(pairs)
+			r.fetchedAt = time.Now()
+		}
+	}
+
+	rules, ok := r.byName[strings.ToUpper(strings.TrimSpace(pair))]
+	if !ok {
+		return nil, errUnknownPair
+	}
+	return rules, nil
+}
+
+func indexRules(pairs map[string]kraken.AssetPair) map[string]*Rules {
+	byName := make(map[string]*Rules, len(pairs)*4)
+	for name, p := range pairs {
+		rules := &Rules{
+			Pair:         name,
+			Base:         assets.Normalize(p.Base),
+			Quote:        assets.Normalize(p.Quote),
+			Status:       p.Status,
+			PairDecimals: p.PairDecimals,
+			LotDecimals:  p.LotDecimals,
+			TakerFee:     firstTier(p.Fees),
+			MakerFee:     firstTier(p.FeesMaker),
+		}
+		rules.OrderMin, _ = strconv.ParseFloat(p.OrderMin, 64)
+		rules.CostMin, _ = strconv.ParseFloat(p.CostMin, 64)
+		if len(p.FeesMaker) == 0 {
+			rules.MakerFee = rules.TakerFee
+		}
+
+		for _, key := range []string{name, p.Altname, p.WSName, rules.Base + "/" + rules.Quote, rules.Base + rules.Quote} {
+			if key == "" {
+				continue
+			}
+			key = strings.ToUpper(key)
+			// Canonical names win over aliases of other pairs.
+			if existing, ok := byName[key]; ok && existing.Pair == key {
+				continue
+			}
+			byName[key] = rules
+		}
+	}
+	return byName
+}
+
+// firstTier returns the fee of the lowest volume tier, in percent.
+func firstTier(tiers [][]float64) float64 {
+	if len(tiers) == 0 || len(tiers[0]) < 2 {
+		return 0
+	}
+	return tiers[0][1]
+}
+
+// decimals returns the number of significant decimals of v.
+func decimals(v float64) int {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
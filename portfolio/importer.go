@@ -0,0 +1,332 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const (
+	FormatCSV          = "csv"
+	FormatKrakenLedger = "kraken"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseCSVTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date invalide %q", s)
+}
+
+func parseCSVFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// readCSV returns the rows of the CSV as maps keyed by the lower-cased
+// header names.
+func readCSV(r io.Reader, required ...string) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête CSV illisible: %v", err)
+	}
+	for i, h := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+
+	present := make(map[string]bool, len(header))
+	for _, h := range header {
+		present[h] = true
+	}
+	for _, col := range required {
+		if !present[col] {
+			return nil, fmt.Errorf("colonne %q manquante", col)
+		}
+	}
+
+	var rows []map[string]string
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ligne %d: %v", line, err)
+		}
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		row["_line"] = strconv.Itoa(line)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ParseTransactionsCSV reads the generic format, with the columns
+// time,type,asset,quantity and optionally price,quote,fee,fee_asset,refid,id.
+func ParseTransactionsCSV(r io.Reader) ([]models.Transaction, error) {
+	rows, err := readCSV(r, "time", "type", "asset", "quantity")
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		line := row["_line"]
+		t, err := parseCSVTime(row["time"])
+		if err != nil {
+			return nil, fmt.Errorf("ligne %s: %v", line, err)
+		}
+		txType := strings.ToLower(row["type"])
+		if !ValidTransactionType(txType) {
+			return nil, fmt.Errorf("ligne %s: type %q inconnu", line, row["type"])
+		}
+
+		tx := models.Transaction{
+			Time:       t,
+			Type:       txType,
+			Asset:      assets.Normalize(row["asset"]),
+			Quote:      assets.Normalize(row["quote"]),
+			FeeAsset:   assets.Normalize(row["fee_asset"]),
+			RefID:      row["refid"],
+			ExternalID: row["id"],
+			Source:     FormatCSV,
+		}
+		if tx.Quantity, err = parseCSVFloat(row["quantity"]); err != nil || tx.Quantity <= 0 {
+			return nil, fmt.Errorf("ligne %s: quantité invalide %q", line, row["quantity"])
+		}
+		if tx.Price, err = parseCSVFloat(row["price"]); err != nil || tx.Price < 0 {
+			return nil, fmt.Errorf("ligne %s: prix invalide %q", line, row["price"])
+		}
+		if tx.Fee, err = parseCSVFloat(row["fee"]); err != nil || tx.Fee < 0 {
+			return nil, fmt.Errorf("ligne %s: frais invalides %q", line, row["fee"])
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+type ledgerRow struct {
+	txid    string
+	refid   string
+	time    time.Time
+	typ     string
+	subtype string
+	asset   string
+	amount  float64
+	fee     float64
+}
+
+// ParseKrakenLedgerCSV converts Kraken's ledger export ("ledgers.csv") into
+// transactions. The two ledger entries of a trade share a refid and become a
+// buy, a sell, or both for crypto-to-crypto trades. Movements of currency
+// (the portfolio base currency) are cash and only kept as trade prices or
+// fees.
+func ParseKrakenLedgerCSV(r io.Reader, currency string) ([]models.Transaction, error) {
+	rows, err := readCSV(r, "txid", "refid", "time", "type", "asset", "amount", "fee")
+	if err != nil {
+		return nil, err
+	}
+	currency = assets.Normalize(currency)
+
+	var entries []ledgerRow
+	for _, row := range rows {
+		// Entries still pending have no txid yet.
+		if row["txid"] == "" {
+			continue
+		}
+		line := row["_line"]
+		t, err := parseCSVTime(row["time"])
+		if err != nil {
+			return nil, fmt.Errorf("ligne %s: %v", line, err)
+		}
+		amount, err := parseCSVFloat(row["amount"])
+		if err != nil {
+			return nil, fmt.Errorf("ligne %s: montant invalide %q", line, row["amount"])
+		}
+		fee, err := parseCSVFloat(row["fee"])
+		if err != nil {
+			return nil, fmt.Errorf("ligne %s: frais invalides %q", line, row["fee"])
+		}
+		entries = append(entries, ledgerRow{
+			txid:    row["txid"],
+			refid:   row["refid"],
+			time:    t,
+			typ:     strings.ToLower(row["type"]),
+			subtype: strings.ToLower(row["subtype"]),
+			asset:   assets.Normalize(row["asset"]),
+			amount:  amount,
+			fee:     fee,
+		})
+	}
+
+	groups := make(map[string][]ledgerRow)
+	var order []string
+	for _, e := range entries {
+		key := e.txid
+		if isTradeEntry(e.typ) && e.refid != "" {
+			key = "trade:" + e.refid
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	var txs []models.Transaction
+	for _, key := range order {
+		group := groups[key]
+		if trade, ok := ledgerTrade(group, currency); ok {
+			txs = append(txs, trade...)
+		} else {
+			for _, e := range group {
+				txs = append(txs, ledgerMovement(e, currency)...)
+			}
+		}
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Time.Before(txs[j].Time) })
+	return txs, nil
+}
+
+func isTradeEntry(typ string) bool {
+	return typ == "trade" || typ == "spend" || typ == "receive"
+}
+
+func ledgerTrade(group []ledgerRow, currency string) ([]models.Transaction, bool) {
+	if len(group) != 2 || !isTradeEntry(group[0].typ) {
+		return nil, false
+	}
+	acquired, disposed := group[0], group[1]
+	if acquired.amount < 0 {
+		acquired, disposed = disposed, acquired
+	}
+	if acquired.amount <= 0 || disposed.amount >= 0 {
+		return nil, false
+	}
+
+	a, b := acquired.amount, -disposed.amount
+	base := models.Transaction{RefID: acquired.refid, Time: acquired.time, Source: FormatKrakenLedger}
+
+	var txs []models.Transaction
+	var cashFee float64
+	for _, leg := range group {
+		if leg.fee <= 0 {
+			continue
+		}
+		if leg.asset == currency {
+			cashFee += leg.fee
+			continue
+		}
+		fee := base
+		fee.Type = TxFee
+		fee.Asset = leg.asset
+		fee.Quantity = leg.fee
+		fee.ExternalID = leg.txid + ":fee"
+		txs = append(txs, fee)
+	}
+
+	buy := base
+	buy.Type = TxBuy
+	buy.Asset = acquired.asset
+	buy.Quantity = a
+	buy.Price = b / a
+	buy.Quote = disposed.asset
+	buy.ExternalID = acquired.txid
+
+	sell := base
+	sell.Type = TxSell
+	sell.Asset = disposed.asset
+	sell.Quantity = b
+	sell.Price = a / b
+	sell.Quote = acquired.asset
+	sell.ExternalID = disposed.txid
+
+	if cashFee > 0 {
+		buy.Fee, buy.FeeAsset = cashFee, currency
+		sell.Fee, sell.FeeAsset = cashFee, currency
+	}
+
+	switch {
+	case disposed.asset == currency:
+		txs = append(txs, buy)
+	case acquired.asset == currency:
+		txs = append(txs, sell)
+	default:
+		txs = append(txs, sell, buy)
+	}
+	return txs, true
+}
+
+// internalTransfers are moves between Kraken wallets of the same asset.
+var internalTransfers = map[string]bool{
+	"spottostaking":   true,
+	"stakingfromspot": true,
+	"stakingtospot":   true,
+	"spotfromstaking": true,
+	"spottofutures":   true,
+	"spotfromfutures": true,
+}
+
+func ledgerMovement(e ledgerRow, currency string) []models.Transaction {
+	if e.asset == currency || (e.typ == "transfer" && internalTransfers[e.subtype]) {
+		return nil
+	}
+
+	tx := models.Transaction{
+		Time:       e.time,
+		Asset:      e.asset,
+		Quantity:   math.Abs(e.amount),
+		RefID:      e.refid,
+		ExternalID: e.txid,
+		Source:     FormatKrakenLedger,
+	}
+	switch {
+	case e.amount > 0:
+		tx.Type = TxTransferIn
+	case e.amount < 0 && (e.typ == "margin" || e.typ == "rollover"):
+		tx.Type = TxFee
+	case e.amount < 0:
+		tx.Type = TxTransferOut
+	}
+
+	var txs []models.Transaction
+	if tx.Type != "" {
+		txs = append(txs, tx)
+	}
+	if e.fee > 0 {
+		fee := tx
+		fee.Type = TxFee
+		fee.Quantity = e.fee
+		fee.ExternalID = e.txid + ":fee"
+		txs = append(txs, fee)
+	}
+	return txs
+}
@@ -0,0 +1,87 @@
+package portfolio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const ledgerCSV = `"txid","refid","time","type","subtype","aclass","asset","amount","fee","balance"
+"L1","T1","2024-01-15 10:00:00","trade","","currency","ZUSD",-4200.0000,6.7200,5793.2800
+"L2","T1","2024-01-15 10:00:00","trade","","currency","XXBT",0.1000000000,0.0000000000,0.1000000000
+"L3","T2","2024-01-16 09:30:00","trade","","currency","XXBT",-0.0500000000,0.0000000000,0.0500000000
+"L4","T2","2024-01-16 09:30:00","trade","","currency","XETH",1.0000000000,0.0020000000,0.9980000000
+"L5","D1","2024-01-10 08:00:00","deposit","","currency","ZUSD",10000.0000,0.0000,10000.0000
+"L6","D2","2024-01-17 12:00:00","deposit","","currency","XXBT",0.5000000000,0.0000000000,0.5500000000
+"L7","S1","2024-01-18 12:00:00","transfer","spottostaking","currency","XETH",-0.9980000000,0.0000000000,0.0000000000
+"","P1","2024-01-19 12:00:00","withdrawal","","currency","XXBT",-0.5000000000,0.0000000000,0.0500000000
+`
+
+func TestParseKrakenLedgerCSV(t *testing.T) {
+	txs, err := ParseKrakenLedgerCSV(strings.NewReader(ledgerCSV), "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jan := func(d, h, m int) time.Time { return time.Date(2024, 1, d, h, m, 0, 0, time.UTC) }
+	want := []models.Transaction{
+		// The USD leg of T1 is cash: only its fee is kept, on the buy.
+		{Time: jan(15, 10, 0), Type: TxBuy, Asset: "BTC", Quantity: 0.1, Price: 42000, Quote: "USD", Fee: 6.72, FeeAsset: "USD", RefID: "T1", ExternalID: "L2", Source: FormatKrakenLedger},
+		// A crypto-to-crypto trade is a sale and a buy; the ETH fee is a
+		// disposal of its own.
+		{Time: jan(16, 9, 30), Type: TxFee, Asset: "ETH", Quantity: 0.002, RefID: "T2", ExternalID: "L4:fee", Source: FormatKrakenLedger},
+		{Time: jan(16, 9, 30), Type: TxSell, Asset: "BTC", Quantity: 0.05, Price: 20, Quote: "ETH", RefID: "T2", ExternalID: "L3", Source: FormatKrakenLedger},
+		{Time: jan(16, 9, 30), Type: TxBuy, Asset: "ETH", Quantity: 1, Price: 0.05, Quote: "BTC", RefID: "T2", ExternalID: "L4", Source: FormatKrakenLedger},
+		{Time: jan(17, 12, 0), Type: TxTransferIn, Asset: "BTC", Quantity: 0.5, RefID: "D2", ExternalID: "L6", Source: FormatKrakenLedger},
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions %+v, want %d", len(txs), txs, len(want))
+	}
+	for i := range want {
+		got := txs[i]
+		if !near(got.Quantity, want[i].Quantity) || !near(got.Price, want[i].Price) || !near(got.Fee, want[i].Fee) {
+			t.Errorf("transaction %d = %+v, want %+v", i, got, want[i])
+			continue
+		}
+		got.Quantity, got.Price, got.Fee = want[i].Quantity, want[i].Price, want[i].Fee
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("transaction %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseTransactionsCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		err  string
+	}{
+		{"valid", "time,type,asset,quantity,price,quote\n2024-01-15,BUY,XBT,0.1,42000,ZUSD\n", ""},
+		{"zero quantity", "time,type,asset,quantity\n2024-01-15,buy,BTC,0\n", `ligne 2: quantité invalide "0"`},
+		{"negative quantity", "time,type,asset,quantity\n2024-01-15,sell,BTC,-1\n", `ligne 2: quantité invalide "-1"`},
+		{"negative price", "time,type,asset,quantity,price\n2024-01-15,buy,BTC,1,-5\n", "prix invalide"},
+		{"unknown type", "time,type,asset,quantity\n2024-01-15,swap,BTC,1\n", `type "swap" inconnu`},
+		{"missing column", "time,type,asset\n2024-01-15,buy,BTC\n", `colonne "quantity" manquante`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := ParseTransactionsCSV(strings.NewReader(tt.csv))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("ParseTransactionsCSV = %+v, %v, want an error containing %q", txs, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := models.Transaction{Time: day("2024-01-15"), Type: TxBuy, Asset: "BTC", Quantity: 0.1, Price: 42000, Quote: "USD", Source: FormatCSV}
+			if len(txs) != 1 || !reflect.DeepEqual(txs[0], want) {
+				t.Errorf("transactions %+v, want %+v", txs, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+type Method string
+
+const (
+	FIFO    Method = "fifo"
+	LIFO    Method = "lifo"
+	Average Method = "average"
+)
+
+const (
+	TxBuy         = "buy"
+	TxSell        = "sell"
+	TxTransferIn  = "transfer_in"
+	TxTransferOut = "transfer_out"
+	TxFee         = "fee"
+)
+
+// dust ignores the rounding residue left when a lot is fully consumed.
+const dust = 1e-12
+
+func ParseMethod(s string) (Method, error) {
+	switch Method(s) {
+	case FIFO, LIFO, Average:
+		return Method(s), nil
+	}
+	return "", fmt.Errorf("méthode inconnue %q (fifo, lifo ou average)", s)
+}
+
+func ValidTransactionType(t string) bool {
+	switch t {
+	case TxBuy, TxSell, TxTransferIn, TxTransferOut, TxFee:
+		return true
+	}
+	return false
+}
+
+type Lot struct {
+	Asset         string    `json:"asset"`
+	TransactionID int64     `json:"transaction_id"`
+	Acquired      time.Time `json:"acquired"`
+	Quantity      float64   `json:"quantity"`
+	CostPerUnit   float64   `json:"cost_per_unit"`
+	Cost          float64   `json:"cost"`
+	Price         float64   `json:"price"`
+	Value         float64   `json:"value"`
+	Unrealized    float64   `json:"unrealized"`
+}
+
+type Disposal struct {
+	Asset         string    `json:"asset"`
+	TransactionID int64     `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Acquired      time.Time `json:"acquired"`
+	Quantity      float64   `json:"quantity"`
+	Proceeds      float64   `json:"proceeds"`
+	CostBasis     float64   `json:"cost_basis"`
+	Gain          float64   `json:"gain"`
+	HoldingDays   int       `json:"holding_days"`
+	LongTerm      bool      `json:"long_term"`
+}
+
+type AssetSummary struct {
+	Asset      string  `json:"asset"`
+	Quantity   float64 `json:"quantity"`
+	Cost       float64 `json:"cost"`
+	Value      float64 `json:"value"`
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+}
+
+type LotReport struct {
+	Method          Method         `json:"method"`
+	Currency        string         `json:"currency"`
+	OpenLots        []Lot          `json:"open_lots"`
+	Disposals       []Disposal     `json:"disposals"`
+	Assets          []AssetSummary `json:"assets"`
+	TotalRealized   float64        `json:"total_realized"`
+	TotalUnrealized float64        `json:"total_unrealized"`
+	Warnings        []string       `json:"warnings"`
+}
+
+type lotEngine struct {
+	pricer   *Pricer
+	method   Method
+	currency string
+	lots     map[string][]Lot
+	report   *LotReport
+}
+
+// ComputeLots replays the transactions in time order and matches disposals
+// against acquisition lots with the given method. Amounts are expressed in
+// currency, which is never tracked as a lot itself. Values missing from a
+// transaction are taken from the stored candles at the transaction time.
+// Open lots are valued with the latest stored prices.
+func ComputeLots(pricer *Pricer, txs []models.Transaction, method Method, currency string) *LotReport {
+	e := &lotEngine{
+		pricer:   pricer,
+		method:   method,
+		currency: assets.Normalize(currency),
+		lots:     make(map[string][]Lot),
+		report: &LotReport{
+			Method:    method,
+			Currency:  assets.Normalize(currency),
+			OpenLots:  []Lot{},
+			Disposals: []Disposal{},
+			Assets:    []AssetSummary{},
+			Warnings:  []string{},
+		},
+	}
+
+	sorted := make([]models.Transaction, len(txs))
+	copy(sorted, txs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	for _, tx := range sorted {
+		e.apply(tx)
+	}
+	e.finish()
+	return e.report
+}
+
+func (e *lotEngine) warn(format string, args ...any) {
+	e.report.Warnings = append(e.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// value returns the value in the report currency of quantity units of asset
+// at t, preferring the price recorded on the transaction.
+func (e *lotEngine) value(tx models.Transaction) float64 {
+	if tx.Price > 0 {
+		quote := assets.Normalize(tx.Quote)
+		if quote == "" || quote == e.currency {
+			return tx.Quantity * tx.Price
+		}
+		rate, err := e.pricer.PriceAt(quote, e.currency, tx.Time)
+		if err == nil {
+			return tx.Quantity * tx.Price * rate
+		}
+		e.warn("transaction %d: conversion %s→%s impossible: %v", tx.ID, quote, e.currency, err)
+	}
+
+	price, err := e.pricer.PriceAt(tx.Asset, e.currency, tx.Time)
+	if err != nil {
+		e.warn("transaction %d: prix de %s introuvable: %v", tx.ID, tx.Asset, err)
+		return 0
+	}
+	return tx.Quantity * price
+}
+
+func (e *lotEngine) feeValue(tx models.Transaction) float64 {
+	if tx.Fee <= 0 {
+		return 0
+	}
+	feeAsset := assets.Normalize(tx.FeeAsset)
+	if feeAsset == "" || feeAsset == e.currency {
+		return tx.Fee
+	}
+	price, err := e.pricer.PriceAt(feeAsset, e.currency, tx.Time)
+	if err != nil {
+		e.warn("transaction %d: prix des frais en %s introuvable: %v", tx.ID, feeAsset, err)
+		return 0
+	}
+	return tx.Fee * price
+}
+
+func (e *lotEngine) apply(tx models.Transaction) {
+	tx.Asset = assets.Normalize(tx.Asset)
+	if tx.Asset == e.currency {
+		return
+	}
+	if tx.Quantity <= 0 {
+		e.warn("transaction %d: quantité %g ignorée", tx.ID, tx.Quantity)
+		return
+	}
+
+	switch tx.Type {
+	case TxBuy, TxTransferIn:
+		cost := e.value(tx)
+		if tx.Type == TxBuy {
+			cost += e.feeValue(tx)
+		}
+		e.acquire(Lot{
+			Asset:         tx.Asset,
+			TransactionID: tx.ID,
+			Acquired:      tx.Time,
+			Quantity:      tx.Quantity,
+			Cost:          cost,
+			CostPerUnit:   cost / tx.Quantity,
+		})
+	case TxSell:
+		proceeds := e.value(tx) - e.feeValue(tx)
+		e.dispose(tx, proceeds, true)
+	case TxFee:
+		e.dispose(tx, 0, true)
+	case TxTransferOut:
+		e.dispose(tx, 0, false)
+	default:
+		e.warn("transaction %d: type %q ignoré", tx.ID, tx.Type)
+	}
+}
+
+func (e *lotEngine) acquire(lot Lot) {
+	if e.method != Average {
+		e.lots[lot.Asset] = append(e.lots[lot.Asset], lot)
+		return
+	}
+
+	// With average cost, each asset is a single pooled lot.
+	pool := e.lots[lot.Asset]
+	if len(pool) == 0 {
+		e.lots[lot.Asset] = []Lot{lot}
+		return
+	}
+	p := &pool[0]
+	p.Quantity += lot.Quantity
+	p.Cost += lot.Cost
+	p.CostPerUnit = p.Cost / p.Quantity
+}
+
+// dispose removes tx.Quantity from the asset lots. When realize is false the
+// quantity leaves the portfolio without producing a gain (transfer out).
+func (e *lotEngine) dispose(tx models.Transaction, proceeds float64, realize bool) {
+	remaining := tx.Quantity
+	lots := e.lots[tx.Asset]
+
+	for remaining > dust && len(lots) > 0 {
+		idx := 0
+		if e.method == LIFO {
+			idx = len(lots) - 1
+		}
+		lot := &lots[idx]
+
+		qty := math.Min(remaining, lot.Quantity)
+		cost := qty * lot.CostPerUnit
+		if realize {
+			share := proceeds * qty / tx.Quantity
+			held := tx.Time.Sub(lot.Acquired)
+			e.report.Disposals = append(e.report.Disposals, Disposal{
+				Asset:         tx.Asset,
+				TransactionID: tx.ID,
+				Type:          tx.Type,
+				Time:          tx.Time,
+				Acquired:      lot.Acquired,
+				Quantity:      qty,
+				Proceeds:      share,
+				CostBasis:     cost,
+				Gain:          share - cost,
+				HoldingDays:   int(held.Hours() / 24),
+				LongTerm:      held > 365*24*time.Hour,
+			})
+		}
+
+		lot.Quantity -= qty
+		lot.Cost -= cost
+		remaining -= qty
+		if lot.Quantity <= dust {
+			lots = append(lots[:idx], lots[idx+1:]...)
+		}
+	}
+	e.lots[tx.Asset] = lots
+
+	if remaining > dust {
+		e.warn("transaction %d: %g %s cédés au-delà des lots disponibles", tx.ID, remaining, tx.Asset)
+		if realize {
+			share := proceeds * remaining / tx.Quantity
+			e.report.Disposals = append(e.report.Disposals, Disposal{
+				Asset:         tx.Asset,
+				TransactionID: tx.ID,
+				Type:          tx.Type,
+				Time:          tx.Time,
+				Quantity:      remaining,
+				Proceeds:      share,
+				Gain:          share,
+			})
+		}
+	}
+}
+
+func (e *lotEngine) finish() {
+	summaries := make(map[string]*AssetSummary)
+	summary := func(asset string) *AssetSummary {
+		if summaries[asset] == nil {
+			summaries[asset] = &AssetSummary{Asset: asset}
+		}
+		return summaries[asset]
+	}
+
+	for _, d := range e.report.Disposals {
+		summary(d.Asset).Realized += d.Gain
+		e.report.TotalRealized += d.Gain
+	}
+
+	assetNames := make([]string, 0, len(e.lots))
+	for asset := range e.lots {
+		assetNames = append(assetNames, asset)
+	}
+	sort.Strings(assetNames)
+
+	for _, asset := range assetNames {
+		price, _, err := e.pricer.Price(asset, e.currency)
+		if err != nil && len(e.lots[asset]) > 0 {
+			e.warn("prix actuel de %s introuvable: %v", asset, err)
+		}
+		for _, lot := range e.lots[asset] {
+			if err == nil {
+				lot.Price = price
+				lot.Value = lot.Quantity * price
+				lot.Unrealized = lot.Value - lot.Cost
+			}
+			s := summary(asset)
+			s.Quantity += lot.Quantity
+			s.Cost += lot.Cost
+			s.Value += lot.Value
+			s.Unrealized += lot.Unrealized
+			e.report.TotalUnrealized += lot.Unrealized
+			e.report.OpenLots = append(e.report.OpenLots, lot)
+		}
+	}
+
+	names := make([]string, 0, len(summaries))
+	for asset := range summaries {
+		names = append(names, asset)
+	}
+	sort.Strings(names)
+	for _, asset := range names {
+		e.report.Assets = append(e.report.Assets, *summaries[asset])
+	}
+}
@@ -0,0 +1,160 @@
+package portfolio
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-9*math.Max(1, math.Abs(want))
+}
+
+// btcPricer prices BTC at 400 USD; it has no database, so every transaction
+// of the tests carries its price.
+func btcPricer() *Pricer {
+	return &Pricer{pairs: []models.PairPrice{{Source: "kraken", Name: "XXBTZUSD", Base: "XXBT", Quote: "ZUSD", Price: 400}}}
+}
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Two buys, then a sale of 1.5 BTC at 300 with 3 USD of fees: 447 of
+// proceeds, shared pro rata between the lots it consumes.
+var partialSale = []models.Transaction{
+	{ID: 3, Time: day("2024-03-01"), Type: TxSell, Asset: "XBT", Quantity: 1.5, Price: 300, Quote: "USD", Fee: 3, FeeAsset: "USD"},
+	{ID: 1, Time: day("2023-01-01"), Type: TxBuy, Asset: "BTC", Quantity: 1, Price: 100, Quote: "USD", Fee: 1, FeeAsset: "USD"},
+	{ID: 2, Time: day("2023-06-01"), Type: TxBuy, Asset: "BTC", Quantity: 1, Price: 200, Quote: "USD"},
+	// Cash movements are not lots.
+	{ID: 4, Time: day("2023-01-01"), Type: TxTransferIn, Asset: "USD", Quantity: 1000},
+}
+
+func TestComputeLotsPartialSale(t *testing.T) {
+	tests := []struct {
+		method     Method
+		disposals  []Disposal
+		open       Lot
+		realized   float64
+		unrealized float64
+	}{
+		{
+			method: FIFO,
+			// The first lot cost 100 + 1 of fees, held 425 days.
+			disposals: []Disposal{
+				{TransactionID: 3, Acquired: day("2023-01-01"), Quantity: 1, Proceeds: 298, CostBasis: 101, Gain: 197, HoldingDays: 425, LongTerm: true},
+				{TransactionID: 3, Acquired: day("2023-06-01"), Quantity: 0.5, Proceeds: 149, CostBasis: 100, Gain: 49, HoldingDays: 274},
+			},
+			open:       Lot{TransactionID: 2, Acquired: day("2023-06-01"), Quantity: 0.5, CostPerUnit: 200, Cost: 100, Price: 400, Value: 200, Unrealized: 100},
+			realized:   246,
+			unrealized: 100,
+		},
+		{
+			method: LIFO,
+			disposals: []Disposal{
+				{TransactionID: 3, Acquired: day("2023-06-01"), Quantity: 1, Proceeds: 298, CostBasis: 200, Gain: 98, HoldingDays: 274},
+				{TransactionID: 3, Acquired: day("2023-01-01"), Quantity: 0.5, Proceeds: 149, CostBasis: 50.5, Gain: 98.5, HoldingDays: 425, LongTerm: true},
+			},
+			open:       Lot{TransactionID: 1, Acquired: day("2023-01-01"), Quantity: 0.5, CostPerUnit: 101, Cost: 50.5, Price: 400, Value: 200, Unrealized: 149.5},
+			realized:   196.5,
+			unrealized: 149.5,
+		},
+		{
+			// One pool of 2 BTC for 301.
+			method: Average,
+			disposals: []Disposal{
+				{TransactionID: 3, Acquired: day("2023-01-01"), Quantity: 1.5, Proceeds: 447, CostBasis: 225.75, Gain: 221.25, HoldingDays: 425, LongTerm: true},
+			},
+			open:       Lot{TransactionID: 1, Acquired: day("2023-01-01"), Quantity: 0.5, CostPerUnit: 150.5, Cost: 75.25, Price: 400, Value: 200, Unrealized: 124.75},
+			realized:   221.25,
+			unrealized: 124.75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			report := ComputeLots(btcPricer(), partialSale, tt.method, "ZUSD")
+			if report.Currency != "USD" || len(report.Warnings) != 0 {
+				t.Fatalf("currency %s, warnings %v", report.Currency, report.Warnings)
+			}
+
+			if len(report.Disposals) != len(tt.disposals) {
+				t.Fatalf("disposals %+v, want %+v", report.Disposals, tt.disposals)
+			}
+			for i, want := range tt.disposals {
+				want.Asset, want.Type, want.Time = "BTC", TxSell, day("2024-03-01")
+				if !sameDisposal(report.Disposals[i], want) {
+					t.Errorf("disposal %d = %+v, want %+v", i, report.Disposals[i], want)
+				}
+			}
+
+			tt.open.Asset = "BTC"
+			if len(report.OpenLots) != 1 || !sameLot(report.OpenLots[0], tt.open) {
+				t.Errorf("open lots %+v, want %+v", report.OpenLots, tt.open)
+			}
+			if !near(report.TotalRealized, tt.realized) || !near(report.TotalUnrealized, tt.unrealized) {
+				t.Errorf("realized %v, unrealized %v, want %v, %v", report.TotalRealized, report.TotalUnrealized, tt.realized, tt.unrealized)
+			}
+			want := AssetSummary{Asset: "BTC", Quantity: 0.5, Cost: tt.open.Cost, Value: 200, Realized: tt.realized, Unrealized: tt.unrealized}
+			if len(report.Assets) != 1 || !sameSummary(report.Assets[0], want) {
+				t.Errorf("assets %+v, want %+v", report.Assets, want)
+			}
+		})
+	}
+}
+
+// Selling more than the lots realizes the excess with no cost basis, and
+// transfers out leave without a gain.
+func TestComputeLotsSaleExceedingHoldings(t *testing.T) {
+	txs := []models.Transaction{
+		{ID: 1, Time: day("2024-01-01"), Type: TxBuy, Asset: "BTC", Quantity: 2, Price: 100, Quote: "USD"},
+		{ID: 2, Time: day("2024-01-02"), Type: TxTransferOut, Asset: "BTC", Quantity: 1},
+		{ID: 3, Time: day("2024-01-03"), Type: TxSell, Asset: "BTC", Quantity: 2, Price: 150, Quote: "USD"},
+		{ID: 4, Time: day("2024-01-04"), Type: TxBuy, Asset: "BTC", Quantity: 0, Price: 150, Quote: "USD"},
+	}
+	report := ComputeLots(btcPricer(), txs, FIFO, "USD")
+
+	want := []Disposal{
+		{Asset: "BTC", TransactionID: 3, Type: TxSell, Time: day("2024-01-03"), Acquired: day("2024-01-01"), Quantity: 1, Proceeds: 150, CostBasis: 100, Gain: 50, HoldingDays: 2},
+		{Asset: "BTC", TransactionID: 3, Type: TxSell, Time: day("2024-01-03"), Quantity: 1, Proceeds: 150, Gain: 150},
+	}
+	if len(report.Disposals) != len(want) {
+		t.Fatalf("disposals %+v, want %+v", report.Disposals, want)
+	}
+	for i := range want {
+		if !sameDisposal(report.Disposals[i], want[i]) {
+			t.Errorf("disposal %d = %+v, want %+v", i, report.Disposals[i], want[i])
+		}
+	}
+	if len(report.OpenLots) != 0 || !near(report.TotalRealized, 200) {
+		t.Errorf("open lots %+v, realized %v", report.OpenLots, report.TotalRealized)
+	}
+	if len(report.Warnings) != 2 || !strings.Contains(report.Warnings[0], "transaction 3: 1 BTC cédés au-delà") ||
+		!strings.Contains(report.Warnings[1], "transaction 4: quantité 0 ignorée") {
+		t.Errorf("warnings %q", report.Warnings)
+	}
+}
+
+func sameDisposal(a, b Disposal) bool {
+	return a.Asset == b.Asset && a.TransactionID == b.TransactionID && a.Type == b.Type && a.Time.Equal(b.Time) &&
+		a.Acquired.Equal(b.Acquired) && near(a.Quantity, b.Quantity) && near(a.Proceeds, b.Proceeds) &&
+		near(a.CostBasis, b.CostBasis) && near(a.Gain, b.Gain) && a.HoldingDays == b.HoldingDays && a.LongTerm == b.LongTerm
+}
+
+func sameLot(a, b Lot) bool {
+	return a.Asset == b.Asset && a.TransactionID == b.TransactionID && a.Acquired.Equal(b.Acquired) &&
+		near(a.Quantity, b.Quantity) && near(a.CostPerUnit, b.CostPerUnit) && near(a.Cost, b.Cost) &&
+		near(a.Price, b.Price) && near(a.Value, b.Value) && near(a.Unrealized, b.Unrealized)
+}
+
+func sameSummary(a, b AssetSummary) bool {
+	return a.Asset == b.Asset && near(a.Quantity, b.Quantity) && near(a.Cost, b.Cost) && near(a.Value, b.Value) &&
+		near(a.Realized, b.Realized) && near(a.Unrealized, b.Unrealized)
+}
@@ -0,0 +1,186 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// maxLegs bounds the number of pairs chained to convert one asset into
+// another, e.g. ETH → BTC → USD → EUR.
+const maxLegs = 3
+
+type leg struct {
+	pair   models.PairPrice
+	to     string
+	invert bool
+}
+
+func (l leg) price() float64 {
+	if l.invert {
+		return 1 / l.pair.Price
+	}
+	return l.pair.Price
+}
+
+// Pricer converts assets using the latest stored ticker prices and, for
+// past dates, the stored candles of the same pairs.
+type Pricer struct {
+	db    *database.DB
+	pairs []models.PairPrice
+}
+
+func NewPricer(db *database.DB) (*Pricer, error) {
+	pairs, err := db.GetLatestPairPricesFromDB()
+	if err != nil {
+		return nil, err
+	}
+	// Conversions only use Kraken pairs, whose candles are stored too.
+	krakenPairs := pairs[:0]
+	for _, pair := range pairs {
+		if pair.Source == database.SourceKraken {
+			krakenPairs = append(krakenPairs, pair)
+		}
+	}
+	return &Pricer{db: db, pairs: krakenPairs}, nil
+}
+
+// route finds the shortest chain of stored pairs converting asset into
+// quote.
+func (p *Pricer) route(asset, quote string) ([]leg, error) {
+	asset, quote = assets.Normalize(asset), assets.Normalize(quote)
+	if asset == quote {
+		return nil, nil
+	}
+
+	edges := make(map[string][]leg)
+	for _, pair := range p.pairs {
+		if pair.Price <= 0 {
+			continue
+		}
+		base, q := assets.Normalize(pair.Base), assets.Normalize(pair.Quote)
+		edges[base] = append(edges[base], leg{pair: pair, to: q})
+		edges[q] = append(edges[q], leg{pair: pair, to: base, invert: true})
+	}
+
+	type path struct {
+		at   string
+		legs []leg
+	}
+	visited := map[string]bool{asset: true}
+	queue := []path{{at: asset}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if len(current.legs) == maxLegs {
+			continue
+		}
+		for _, l := range edges[current.at] {
+			if visited[l.to] {
+				continue
+			}
+			legs := append(append([]leg(nil), current.legs...), l)
+			if l.to == quote {
+				return legs, nil
+			}
+			visited[l.to] = true
+			queue = append(queue, path{at: l.to, legs: legs})
+		}
+	}
+
+	return nil, fmt.Errorf("aucune paire suivie pour convertir %s en %s", asset, quote)
+}
+
+// Price returns the latest price of asset in quote, and the time of the
+// oldest ticker used to compute it.
+func (p *Pricer) Price(asset, quote string) (float64, time.Time, error) {
+	legs, err := p.route(asset, quote)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	price, asOf := 1.0, time.Now()
+	for _, l := range legs {
+		price *= l.price()
+		if l.pair.Timestamp.Before(asOf) {
+			asOf = l.pair.Timestamp
+		}
+	}
+	return price, asOf, nil
+}
+
+// Series returns, for each aligned bucket, the price of every asset in
+// quote. Assets that cannot be priced are reported in the error.
+func (p *Pricer) Series(assetList []string, quote string, from, to time.Time, interval time.Duration) ([]time.Time, map[string][]float64, error) {
+	routes := make(map[string][]leg, len(assetList))
+	series := make(map[string][]candles.Candle)
+	for _, asset := range assetList {
+		legs, err := p.route(asset, quote)
+		if err != nil {
+			return nil, nil, err
+		}
+		routes[asset] = legs
+		for _, l := range legs {
+			if _, ok := series[l.pair.Name]; ok {
+				continue
+			}
+			data, err := p.db.GetCandlesFromDB(l.pair.Name, from, to)
+			if err != nil {
+				return nil, nil, err
+			}
+			series[l.pair.Name] = candles.FromHistorical(data)
+		}
+	}
+
+	if len(series) == 0 {
+		return nil, nil, nil
+	}
+
+	aligned := candles.Align(series, interval, candles.FillForward)
+	prices := make(map[string][]float64, len(assetList))
+	for asset, legs := range routes {
+		values := make([]float64, len(aligned.Times))
+		for i := range aligned.Times {
+			values[i] = 1
+			for _, l := range legs {
+				close := aligned.Closes[l.pair.Name][i]
+				if l.invert {
+					close = 1 / close
+				}
+				values[i] *= close
+			}
+		}
+		prices[asset] = values
+	}
+	return aligned.Times, prices, nil
+}
+
+// PriceAt returns the price of asset in quote at t, using the last stored
+// candle close at or before t for every pair of the conversion route.
+func (p *Pricer) PriceAt(asset, quote string, t time.Time) (float64, error) {
+	legs, err := p.route(asset, quote)
+	if err != nil {
+		return 0, err
+	}
+
+	price := 1.0
+	for _, l := range legs {
+		close, _, err := p.db.GetCloseAtOrBeforeFromDB(l.pair.Name, t)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return 0, fmt.Errorf("aucune bougie %s avant le %s", l.pair.Name, t.Format(time.RFC3339))
+			}
+			return 0, err
+		}
+		if l.invert {
+			close = 1 / close
+		}
+		price *= close
+	}
+	return price, nil
+}
@@ -0,0 +1,70 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func TestRoute(t *testing.T) {
+	p := &Pricer{pairs: []models.PairPrice{
+		{Name: "XXBTZUSD", Base: "XXBT", Quote: "ZUSD", Price: 60000},
+		{Name: "XETHXXBT", Base: "XETH", Quote: "XXBT", Price: 0.05},
+		{Name: "ZEURZUSD", Base: "ZEUR", Quote: "ZUSD", Price: 1.25},
+		{Name: "SOLETH", Base: "SOL", Quote: "XETH", Price: 0.05},
+		{Name: "ADAJPY", Base: "ADA", Quote: "ZJPY", Price: 70},
+		// Pairs without a price are not conversions.
+		{Name: "DOTUSD", Base: "DOT", Quote: "ZUSD", Price: 0},
+	}}
+
+	tests := []struct {
+		asset, quote string
+		pairs        []string
+		price        float64
+	}{
+		{"XBT", "BTC", nil, 1},
+		{"BTC", "USD", []string{"XXBTZUSD"}, 60000},
+		{"USD", "BTC", []string{"XXBTZUSD"}, 1.0 / 60000},
+		{"ETH", "USD", []string{"XETHXXBT", "XXBTZUSD"}, 3000},
+		{"ETH", "EUR", []string{"XETHXXBT", "XXBTZUSD", "ZEURZUSD"}, 2400},
+		{"EUR", "ETH", []string{"ZEURZUSD", "XXBTZUSD", "XETHXXBT"}, 1.0 / 2400},
+		// SOL → ETH → BTC → USD → EUR takes more than maxLegs pairs.
+		{"SOL", "USD", []string{"SOLETH", "XETHXXBT", "XXBTZUSD"}, 150},
+		{"SOL", "EUR", nil, 0},
+		{"ADA", "USD", nil, 0},
+		{"DOT", "USD", nil, 0},
+		{"JPY", "ADA", []string{"ADAJPY"}, 1.0 / 70},
+	}
+	for _, tt := range tests {
+		legs, err := p.route(tt.asset, tt.quote)
+		if tt.price == 0 {
+			if err == nil {
+				t.Errorf("%s → %s: route %+v, want an error", tt.asset, tt.quote, legs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s → %s: %v", tt.asset, tt.quote, err)
+			continue
+		}
+		var pairs []string
+		for _, l := range legs {
+			pairs = append(pairs, l.pair.Name)
+		}
+		if len(pairs) != len(tt.pairs) {
+			t.Errorf("%s → %s: pairs %v, want %v", tt.asset, tt.quote, pairs, tt.pairs)
+			continue
+		}
+		for i := range pairs {
+			if pairs[i] != tt.pairs[i] {
+				t.Errorf("%s → %s: pairs %v, want %v", tt.asset, tt.quote, pairs, tt.pairs)
+				break
+			}
+		}
+		price, _, err := p.Price(tt.asset, tt.quote)
+		if err != nil || math.Abs(price-tt.price) > 1e-9*tt.price {
+			t.Errorf("Price(%s, %s) = %v, %v, want %v", tt.asset, tt.quote, price, err, tt.price)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+type HoldingValuation struct {
+	HoldingID  int64     `json:"holding_id"`
+	Asset      string    `json:"asset"`
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	PriceAsOf  time.Time `json:"price_as_of"`
+	Value      float64   `json:"value"`
+	CostBasis  float64   `json:"cost_basis"`
+	PnL        float64   `json:"pnl"`
+	PnLPercent float64   `json:"pnl_percent"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type ValuePoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type Valuation struct {
+	PortfolioID     int64              `json:"portfolio_id"`
+	Quote           string             `json:"quote"`
+	AsOf            time.Time          `json:"as_of"`
+	Holdings        []HoldingValuation `json:"holdings"`
+	TotalValue      float64            `json:"total_value"`
+	TotalCost       float64            `json:"total_cost"`
+	TotalPnL        float64            `json:"total_pnl"`
+	TotalPnLPercent float64            `json:"total_pnl_percent"`
+	Series          []ValuePoint       `json:"series"`
+	SeriesError     string             `json:"series_error,omitempty"`
+}
+
+// Value prices the holdings in quote. Cost bases are expressed in the
+// portfolio base currency and converted at the latest rate. Holdings that
+// cannot be priced are reported individually and left out of the totals.
+// The value-over-time series applies the current quantities to past prices.
+func Value(pricer *Pricer, p *models.Portfolio, holdings []models.Holding, quote string, from, to time.Time, interval time.Duration) *Valuation {
+	v := &Valuation{
+		PortfolioID: p.ID,
+		Quote:       quote,
+		AsOf:        time.Now(),
+		Holdings:    make([]HoldingValuation, 0, len(holdings)),
+		Series:      []ValuePoint{},
+	}
+
+	costRate, _, costErr := pricer.Price(p.BaseCurrency, quote)
+
+	var priced []models.Holding
+	for _, h := range holdings {
+		hv := HoldingValuation{HoldingID: h.ID, Asset: h.Asset, Quantity: h.Quantity}
+
+		price, asOf, err := pricer.Price(h.Asset, quote)
+		if err != nil {
+			hv.Error = err.Error()
+			v.Holdings = append(v.Holdings, hv)
+			continue
+		}
+		if costErr != nil {
+			hv.Error = costErr.Error()
+			v.Holdings = append(v.Holdings, hv)
+			continue
+		}
+
+		hv.Price = price
+		hv.PriceAsOf = asOf
+		hv.Value = h.Quantity * price
+		hv.CostBasis = h.CostBasis * costRate
+		hv.PnL = hv.Value - hv.CostBasis
+		if hv.CostBasis != 0 {
+			hv.PnLPercent = hv.PnL / hv.CostBasis * 100
+		}
+		if asOf.Before(v.AsOf) {
+			v.AsOf = asOf
+		}
+
+		v.TotalValue += hv.Value
+		v.TotalCost += hv.CostBasis
+		v.Holdings = append(v.Holdings, hv)
+		priced = append(priced, h)
+	}
+
+	v.TotalPnL = v.TotalValue - v.TotalCost
+	if v.TotalCost != 0 {
+		v.TotalPnLPercent = v.TotalPnL / v.TotalCost * 100
+	}
+
+	if interval > 0 && len(priced) > 0 {
+		assetList := make([]string, 0, len(priced))
+		for _, h := range priced {
+			assetList = append(assetList, h.Asset)
+		}
+		times, prices, err := pricer.Series(assetList, quote, from, to, interval)
+		if err != nil {
+			v.SeriesError = err.Error()
+			return v
+		}
+		for i, t := range times {
+			point := ValuePoint{Time: t}
+			for _, h := range priced {
+				point.Value += h.Quantity * prices[h.Asset][i]
+			}
+			v.Series = append(v.Series, point)
+		}
+	}
+
+	return v
+}
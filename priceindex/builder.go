@@ -0,0 +1,122 @@
+package priceindex
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/assets"
+	"github.com/antonyloussararian/Go-CryptoPrice/exchange"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+// DefaultQuotes are the quote currencies whose pairs feed an index, e.g.
+// XBTUSD, XBTUSDT, XBTUSDC and XBTEUR for BTC in USD.
+var DefaultQuotes = []string{"USD", "USDT", "USDC", "EUR"}
+
+// Builder fetches the tickers of an asset on every venue and quote
+// currency, and converts them into the index quote currency with the rates
+// of a reference venue.
+type Builder struct {
+	venues []exchange.Exchange
+	rates  exchange.Exchange
+	quotes []string
+	cfg    Config
+}
+
+func NewBuilder(venues []exchange.Exchange, rates exchange.Exchange) *Builder {
+	return &Builder{
+		venues: venues,
+		rates:  rates,
+		quotes: DefaultQuotes,
+		cfg:    DefaultConfig,
+	}
+}
+
+// Build computes the index of asset in quote from the live tickers.
+func (b *Builder) Build(asset, quote string) (*models.IndexPoint, error) {
+	asset, quote = assets.Normalize(asset), assets.Normalize(quote)
+
+	quotes := b.quotes
+	if !contains(quotes, quote) {
+		quotes = append([]string{quote}, quotes...)
+	}
+
+	rates := make(map[string]float64)
+	for _, q := range quotes {
+		if q == asset {
+			continue
+		}
+		rate, err := b.rate(q, quote)
+		if err != nil {
+			continue
+		}
+		rates[q] = rate
+	}
+
+	var mu sync.Mutex
+	var components []models.IndexComponent
+	var wg sync.WaitGroup
+	for _, venue := range b.venues {
+		for q, rate := range rates {
+			wg.Add(1)
+			go func(venue exchange.Exchange, q string, rate float64) {
+				defer wg.Done()
+				ticker, err := venue.Ticker(asset + "/" + q)
+				if err != nil {
+					var unknown exchange.ErrUnknownMarket
+					if !errors.As(err, &unknown) {
+						slog.Warn("échec de la récupération du ticker", "symbol", asset+"/"+q, "exchange", venue.Name(), logging.Err(err))
+					}
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				components = append(components, models.IndexComponent{
+					Source:    venue.Name(),
+					Pair:      ticker.Native,
+					Quote:     q,
+					Price:     ticker.Last,
+					Rate:      rate,
+					Converted: ticker.Last * rate,
+					Volume:    ticker.Volume24h,
+					Timestamp: ticker.Timestamp,
+				})
+			}(venue, q, rate)
+		}
+	}
+	wg.Wait()
+
+	return Compute(asset, quote, components, b.cfg, time.Now().UTC())
+}
+
+// rate returns the price of one unit of from in to, using the direct or the
+// inverse pair of the reference venue.
+func (b *Builder) rate(from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if ticker, err := b.rates.Ticker(from + "/" + to); err == nil && ticker.Last > 0 {
+		return ticker.Last, nil
+	}
+	ticker, err := b.rates.Ticker(to + "/" + from)
+	if err != nil {
+		return 0, err
+	}
+	if ticker.Last <= 0 {
+		return 0, fmt.Errorf("taux %s/%s invalide", from, to)
+	}
+	return 1 / ticker.Last, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
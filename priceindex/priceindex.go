@@ -0,0 +1,125 @@
+// Package priceindex builds a composite reference price of an asset from
+// the prices of several venues and quote currencies.
+package priceindex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+const (
+	MethodVWAP   = "vwap"
+	MethodMedian = "median"
+
+	excludedStale   = "stale"
+	excludedOutlier = "outlier"
+	excludedInvalid = "invalid"
+)
+
+var ErrNoComponents = errors.New("aucune composante valide pour l'indice")
+
+type Config struct {
+	// MaxDeviation is the largest relative distance to the median of all
+	// fresh components that a component may have (0.02 = 2%).
+	MaxDeviation float64
+	// MaxAge is the age above which a component is considered stale.
+	MaxAge time.Duration
+}
+
+var DefaultConfig = Config{
+	MaxDeviation: 0.02,
+	MaxAge:       15 * time.Minute,
+}
+
+// ParseMethod validates the method names accepted by the API.
+func ParseMethod(s string) (string, error) {
+	switch s {
+	case "", MethodVWAP:
+		return MethodVWAP, nil
+	case MethodMedian:
+		return MethodMedian, nil
+	default:
+		return "", fmt.Errorf("méthode inconnue %q (vwap ou median)", s)
+	}
+}
+
+// Compute flags stale and outlier components and returns the volume
+// weighted and median prices of the others. Components must already be
+// converted to the index quote currency.
+func Compute(asset, quote string, components []models.IndexComponent, cfg Config, now time.Time) (*models.IndexPoint, error) {
+	point := &models.IndexPoint{
+		Asset:      asset,
+		Quote:      quote,
+		Timestamp:  now,
+		Components: components,
+	}
+
+	var fresh []float64
+	for i := range components {
+		c := &components[i]
+		switch {
+		case c.Converted <= 0 || math.IsNaN(c.Converted) || math.IsInf(c.Converted, 0):
+			c.Excluded = excludedInvalid
+		case cfg.MaxAge > 0 && now.Sub(c.Timestamp) > cfg.MaxAge:
+			c.Excluded = excludedStale
+		default:
+			fresh = append(fresh, c.Converted)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, ErrNoComponents
+	}
+
+	reference := median(fresh)
+	var kept []float64
+	weighted, volume := 0.0, 0.0
+	for i := range components {
+		c := &components[i]
+		if c.Excluded != "" {
+			point.Rejected++
+			continue
+		}
+		if cfg.MaxDeviation > 0 && math.Abs(c.Converted/reference-1) > cfg.MaxDeviation {
+			c.Excluded = excludedOutlier
+			point.Rejected++
+			continue
+		}
+		point.Used++
+		kept = append(kept, c.Converted)
+		weighted += c.Converted * c.Volume
+		volume += c.Volume
+	}
+
+	point.Median = median(kept)
+	point.VWAP = point.Median
+	if volume > 0 {
+		point.VWAP = weighted / volume
+	}
+	return point, nil
+}
+
+// Price returns the index price for a method.
+func Price(point *models.IndexPoint, method string) float64 {
+	if method == MethodMedian {
+		return point.Median
+	}
+	return point.VWAP
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,171 @@
+package priceindex
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/models"
+)
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Minute)
+	component := func(source string, price, volume float64, timestamp time.Time) models.IndexComponent {
+		return models.IndexComponent{Source: source, Price: price, Rate: 1, Converted: price, Volume: volume, Timestamp: timestamp}
+	}
+
+	tests := []struct {
+		name       string
+		components []models.IndexComponent
+		vwap       float64
+		median     float64
+		used       int
+		excluded   map[string]string
+	}{
+		{
+			name: "vwap and median",
+			components: []models.IndexComponent{
+				component("kraken", 100, 1, fresh),
+				component("binance", 101, 3, fresh),
+				component("coinbase", 100.5, 0, fresh),
+			},
+			// (100*1 + 101*3 + 100.5*0) / 4
+			vwap:   100.75,
+			median: 100.5,
+			used:   3,
+		},
+		{
+			name: "even number of components",
+			components: []models.IndexComponent{
+				component("kraken", 100, 2, fresh),
+				component("binance", 101, 2, fresh),
+			},
+			vwap:   100.5,
+			median: 100.5,
+			used:   2,
+		},
+		{
+			name: "without volume the vwap is the median",
+			components: []models.IndexComponent{
+				component("kraken", 100, 0, fresh),
+				component("binance", 101, 0, fresh),
+				component("coinbase", 101.5, 0, fresh),
+			},
+			vwap:   101,
+			median: 101,
+			used:   3,
+		},
+		{
+			name: "outlier",
+			components: []models.IndexComponent{
+				component("kraken", 100, 1, fresh),
+				component("binance", 100.2, 1, fresh),
+				component("coinbase", 100.4, 1, fresh),
+				// 10% above the median of the fresh components.
+				component("stale-book", 110.3, 100, fresh),
+			},
+			vwap:     100.2,
+			median:   100.2,
+			used:     3,
+			excluded: map[string]string{"stale-book": excludedOutlier},
+		},
+		{
+			name: "stale",
+			components: []models.IndexComponent{
+				component("kraken", 100, 1, fresh),
+				component("binance", 100.4, 1, fresh),
+				component("coinbase", 90, 1, now.Add(-16*time.Minute)),
+			},
+			vwap:     100.2,
+			median:   100.2,
+			used:     2,
+			excluded: map[string]string{"coinbase": excludedStale},
+		},
+		{
+			// A stale price must not move the median the outliers are
+			// measured against.
+			name: "stale components do not shift the reference",
+			components: []models.IndexComponent{
+				component("kraken", 100, 1, fresh),
+				component("old-1", 50, 1, now.Add(-time.Hour)),
+				component("old-2", 50, 1, now.Add(-time.Hour)),
+			},
+			vwap:     100,
+			median:   100,
+			used:     1,
+			excluded: map[string]string{"old-1": excludedStale, "old-2": excludedStale},
+		},
+		{
+			name: "invalid",
+			components: []models.IndexComponent{
+				component("kraken", 100, 1, fresh),
+				component("zero", 0, 1, fresh),
+				component("negative", -100, 1, fresh),
+				component("nan", math.NaN(), 1, fresh),
+				component("inf", math.Inf(1), 1, fresh),
+			},
+			vwap:   100,
+			median: 100,
+			used:   1,
+			excluded: map[string]string{
+				"zero":     excludedInvalid,
+				"negative": excludedInvalid,
+				"nan":      excludedInvalid,
+				"inf":      excludedInvalid,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point, err := Compute("BTC", "USD", tt.components, DefaultConfig, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(point.VWAP-tt.vwap) > 1e-9 || math.Abs(point.Median-tt.median) > 1e-9 {
+				t.Errorf("vwap = %v, median = %v, want %v and %v", point.VWAP, point.Median, tt.vwap, tt.median)
+			}
+			if point.Used != tt.used || point.Rejected != len(tt.components)-tt.used {
+				t.Errorf("used %d, rejected %d, want %d and %d", point.Used, point.Rejected, tt.used, len(tt.components)-tt.used)
+			}
+			for _, c := range point.Components {
+				if c.Excluded != tt.excluded[c.Source] {
+					t.Errorf("%s excluded as %q, want %q", c.Source, c.Excluded, tt.excluded[c.Source])
+				}
+			}
+			if got := Price(point, MethodMedian); got != point.Median {
+				t.Errorf("median price = %v, want %v", got, point.Median)
+			}
+			if got := Price(point, MethodVWAP); got != point.VWAP {
+				t.Errorf("vwap price = %v, want %v", got, point.VWAP)
+			}
+		})
+	}
+}
+
+func TestComputeWithoutValidComponents(t *testing.T) {
+	now := time.Now()
+	tests := map[string][]models.IndexComponent{
+		"none":    nil,
+		"invalid": {{Source: "kraken", Converted: 0, Timestamp: now}},
+		"stale":   {{Source: "kraken", Converted: 100, Timestamp: now.Add(-time.Hour)}},
+	}
+	for name, components := range tests {
+		if _, err := Compute("BTC", "USD", components, DefaultConfig, now); !errors.Is(err, ErrNoComponents) {
+			t.Errorf("%s: error %v, want ErrNoComponents", name, err)
+		}
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	for input, want := range map[string]string{"": MethodVWAP, "vwap": MethodVWAP, "median": MethodMedian} {
+		if got, err := ParseMethod(input); err != nil || got != want {
+			t.Errorf("ParseMethod(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseMethod("twap"); err == nil {
+		t.Error("ParseMethod(twap) accepted")
+	}
+}
@@ -0,0 +1,80 @@
+// Package ratelimit limits the requests of every client of the HTTP API
+// with token buckets per route, and with a daily quota persisted in the
+// database.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets refilled to their burst are
+// dropped, so that one-off clients do not accumulate.
+const sweepInterval = time.Minute
+
+// Rule lets a client make Rate requests per second on average, with bursts
+// of up to Burst requests.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	rule   Rule
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(b.rule.Burst), b.tokens+elapsed*b.rule.Rate)
+	b.last = now
+}
+
+// bucketSet holds one token bucket per key. It is safe for concurrent use.
+type bucketSet struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBucketSet() *bucketSet {
+	return &bucketSet{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// allow takes a token from the bucket of key, created full with rule. It
+// returns whether the request is allowed, the tokens left and, when it is
+// not, how long to wait for the next token.
+func (l *bucketSet) allow(key string, rule Rule, now time.Time) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok || b.rule != rule {
+		b = &bucket{rule: rule, tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rule.Rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+func (l *bucketSet) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
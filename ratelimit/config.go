@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the rules per route, the rule of the other /api routes, the
+// rule of all the /api requests of an IP address, applied before
+// authentication (zero to disable it), and the number of requests a client
+// can make per UTC day (0 for no quota).
+type Config struct {
+	Routes     map[string]Rule
+	Default    Rule
+	PerIP      Rule
+	DailyQuota int
+}
+
+// DefaultConfig is stricter on the routes that call Kraken on every
+// request.
+func DefaultConfig() Config {
+	return Config{
+		Routes: map[string]Rule{
+			"/api/pairs":       {Rate: 30.0 / 60, Burst: 10},
+			"/api/pairs/:pair": {Rate: 60.0 / 60, Burst: 20},
+		},
+		Default:    Rule{Rate: 300.0 / 60, Burst: 60},
+		PerIP:      Rule{Rate: 600.0 / 60, Burst: 120},
+		DailyQuota: 10000,
+	}
+}
+
+func (c Config) ruleFor(route string) Rule {
+	if rule, ok := c.Routes[route]; ok {
+		return rule
+	}
+	return c.Default
+}
+
+// ParseRules reads a comma-separated list of route=rate[:burst] entries into
+// cfg, where rate is a number of requests per second, minute or hour
+// (e.g. 30/m) and route is a Gin route pattern, "default" or "ip". The
+// burst defaults to the number of requests of the rate, at least one.
+func (c *Config) ParseRules(s string) error {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("règle invalide %q, format attendu: route=30/m:10", item)
+		}
+		rule, err := parseRule(spec)
+		if err != nil {
+			return fmt.Errorf("règle invalide pour %s: %v", route, err)
+		}
+		switch route = strings.TrimSpace(route); route {
+		case "default":
+			c.Default = rule
+			continue
+		case "ip":
+			c.PerIP = rule
+			continue
+		}
+		if c.Routes == nil {
+			c.Routes = make(map[string]Rule)
+		}
+		c.Routes[route] = rule
+	}
+	return nil
+}
+
+func parseRule(spec string) (Rule, error) {
+	rateStr, burstStr, hasBurst := strings.Cut(strings.TrimSpace(spec), ":")
+	countStr, unit, ok := strings.Cut(rateStr, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("débit %q sans unité (/s, /m ou /h)", rateStr)
+	}
+	count, err := strconv.ParseFloat(countStr, 64)
+	if err != nil || count <= 0 {
+		return Rule{}, fmt.Errorf("nombre de requêtes invalide %q", countStr)
+	}
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Rule{}, fmt.Errorf("unité inconnue %q (s, m ou h)", unit)
+	}
+
+	rule := Rule{Rate: count / per.Seconds(), Burst: max(1, int(count))}
+	if hasBurst {
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst < 1 {
+			return Rule{}, fmt.Errorf("rafale invalide %q", burstStr)
+		}
+		rule.Burst = burst
+	}
+	return rule, nil
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/auth"
+	"github.com/antonyloussararian/Go-CryptoPrice/database"
+	"github.com/antonyloussararian/Go-CryptoPrice/logging"
+	"github.com/antonyloussararian/Go-CryptoPrice/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// quotaRetention is how long the daily counters are kept.
+const quotaRetention = 30 * 24 * time.Hour
+
+// Limiter limits the requests to /api routes with the token buckets and the
+// daily quota of its configuration.
+type Limiter struct {
+	cfg     Config
+	buckets *bucketSet
+	db      *database.DB
+
+	mu        sync.Mutex
+	cleanedOn string
+}
+
+func NewLimiter(db *database.DB, cfg Config) *Limiter {
+	return &Limiter{cfg: cfg, buckets: newBucketSet(), db: db}
+}
+
+// clientID identifies the client of a request.
+func clientID(c *gin.Context) string {
+	switch p := auth.PrincipalFrom(c); {
+	case p == nil:
+		return "ip:" + c.ClientIP()
+	case p.KeyID != 0:
+		return "key:" + strconv.FormatInt(p.KeyID, 10)
+	default:
+		return "jwt:" + p.Subject
+	}
+}
+
+// IPMiddleware limits the /api requests of every IP address with the PerIP
+// rule. It runs before the authentication middleware, so that a client
+// sending invalid keys or tokens is limited before they are checked.
+func (l *Limiter) IPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if l.cfg.PerIP.Burst == 0 || !strings.HasPrefix(route, "/api/") {
+			c.Next()
+			return
+		}
+		client := "ip:" + c.ClientIP()
+		if ok, _, wait := l.buckets.allow(client, l.cfg.PerIP, time.Now()); !ok {
+			l.reject(c, client, route, "ip", wait,
+				fmt.Sprintf("trop de requêtes depuis cette adresse, réessayez dans %s s", retrySeconds(wait)))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Middleware identifies clients by their API key or JWT subject when
+// authenticated, by their IP address otherwise, so it must run after the
+// authentication middleware. Refused requests get a 429 with a Retry-After
+// header.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !strings.HasPrefix(route, "/api/") {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		client := clientID(c)
+
+		rule := l.cfg.ruleFor(route)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rule.Burst))
+		ok, remaining, wait := l.buckets.allow(client+" "+route, rule, now)
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			l.reject(c, client, route, "rate", wait,
+				fmt.Sprintf("trop de requêtes sur %s, réessayez dans %s s", route, retrySeconds(wait)))
+			return
+		}
+
+		if l.cfg.DailyQuota > 0 {
+			l.cleanQuotas(c, now)
+			db := l.db.WithContext(c.Request.Context())
+			used, ok, err := db.ConsumeDailyQuota(client, now, l.cfg.DailyQuota)
+			switch {
+			case err != nil:
+				// The quota is not enforced rather than failing the request.
+				slog.ErrorContext(c.Request.Context(), "échec du décompte du quota journalier", logging.Err(err))
+			case !ok:
+				midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+				l.reject(c, client, route, "quota", midnight.Sub(now),
+					fmt.Sprintf("quota journalier de %d requêtes atteint", l.cfg.DailyQuota))
+				return
+			default:
+				c.Header("X-Quota-Limit", strconv.Itoa(l.cfg.DailyQuota))
+				c.Header("X-Quota-Remaining", strconv.Itoa(l.cfg.DailyQuota-used))
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func (l *Limiter) reject(c *gin.Context, client, route, reason string, wait time.Duration, message string) {
+	metrics.RateLimitedRequests.WithLabelValues(route, reason).Inc()
+	slog.WarnContext(c.Request.Context(), "requête limitée", "client", client, "route", route, "reason", reason)
+	c.Header("Retry-After", retrySeconds(wait))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
+
+// retrySeconds rounds wait up to whole seconds, as Retry-After expects.
+func retrySeconds(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+// cleanQuotas drops the old daily counters on the first request of a day.
+func (l *Limiter) cleanQuotas(c *gin.Context, now time.Time) {
+	today := now.UTC().Format(time.DateOnly)
+	l.mu.Lock()
+	if l.cleanedOn == today {
+		l.mu.Unlock()
+		return
+	}
+	l.cleanedOn = today
+	l.mu.Unlock()
+
+	if err := l.db.WithContext(c.Request.Context()).DeleteQuotasBefore(now.Add(-quotaRetention)); err != nil {
+		slog.ErrorContext(c.Request.Context(), "échec de la purge des quotas journaliers", logging.Err(err))
+	}
+}
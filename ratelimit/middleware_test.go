@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejectAll stands for the authentication middleware refusing an invalid
+// key, and counts the keys it had to check.
+type rejectAll struct{ checked int }
+
+func (a *rejectAll) middleware(c *gin.Context) {
+	a.checked++
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+func TestIPLimitRunsBeforeAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := DefaultConfig()
+	cfg.PerIP = Rule{Rate: 1.0 / 60, Burst: 3}
+	cfg.DailyQuota = 0
+	limiter := NewLimiter(nil, cfg)
+	auth := &rejectAll{}
+
+	r := gin.New()
+	r.Use(limiter.IPMiddleware(), auth.middleware, limiter.Middleware())
+	r.GET("/api/pairs", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(path, addr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = addr + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := request("/api/pairs", "192.0.2.1"); code != http.StatusUnauthorized {
+			t.Fatalf("request %d: status %d, want 401", i, code)
+		}
+	}
+	if code := request("/api/pairs", "192.0.2.1"); code != http.StatusTooManyRequests {
+		t.Errorf("request over the limit: status %d, want 429", code)
+	}
+	if auth.checked != 3 {
+		t.Errorf("%d keys checked, want 3", auth.checked)
+	}
+
+	// Other addresses and routes outside /api are not affected.
+	if code := request("/api/pairs", "192.0.2.2"); code != http.StatusUnauthorized {
+		t.Errorf("another address: status %d, want 401", code)
+	}
+	if code := request("/healthz", "192.0.2.1"); code != http.StatusUnauthorized {
+		t.Errorf("/healthz: status %d, want 401 from the authentication", code)
+	}
+}
+
+func TestParseIPRule(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.ParseRules("ip=1200/m:200, default=600/m"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PerIP != (Rule{Rate: 20, Burst: 200}) || cfg.Default != (Rule{Rate: 10, Burst: 600}) {
+		t.Errorf("per IP %+v, default %+v", cfg.PerIP, cfg.Default)
+	}
+	if _, ok := cfg.Routes["ip"]; ok {
+		t.Error("ip parsed as a route")
+	}
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Pearson returns the linear correlation of x and y, which must have the
+// same length. It returns 0 when either series is constant.
+func Pearson(x, y []float64) float64 {
+	n := len(x)
+	if n < 2 || n != len(y) {
+		return 0
+	}
+	mx, my := Mean(x), Mean(y)
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// Spearman returns the rank correlation of x and y, with tied values given
+// their average rank.
+func Spearman(x, y []float64) float64 {
+	if len(x) < 2 || len(x) != len(y) {
+		return 0
+	}
+	return Pearson(ranks(x), ranks(y))
+}
+
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	r := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			r[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return r
+}
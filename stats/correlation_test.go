@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		values, want []float64
+	}{
+		{[]float64{30, 10, 20}, []float64{3, 1, 2}},
+		// Tied values share the average of the ranks they span.
+		{[]float64{1, 2, 2, 3}, []float64{1, 2.5, 2.5, 4}},
+		{[]float64{5, 5, 5}, []float64{2, 2, 2}},
+		{[]float64{4, 1, 4, 1, 4}, []float64{4, 1.5, 4, 1.5, 4}},
+	}
+	for _, tt := range tests {
+		if got := ranks(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ranks(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestSpearman(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"monotonic", []float64{1, 2, 3, 4}, []float64{1, 8, 27, 64}, 1},
+		{"reversed", []float64{1, 2, 3, 4}, []float64{10, 5, 2, 1}, -1},
+		// Ranks 1, 2.5, 2.5, 4 against 1, 2, 3, 4: 4.5 / sqrt(4.5 × 5).
+		{"ties", []float64{1, 2, 2, 3}, []float64{1, 2, 3, 4}, 3 / math.Sqrt(10)},
+		{"constant", []float64{1, 1, 1}, []float64{1, 2, 3}, 0},
+		{"different lengths", []float64{1, 2, 3}, []float64{1, 2}, 0},
+		{"single value", []float64{1}, []float64{1}, 0},
+	}
+	for _, tt := range tests {
+		if got := Spearman(tt.x, tt.y); !near(got, tt.want) {
+			t.Errorf("%s: Spearman = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
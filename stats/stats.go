@@ -0,0 +1,232 @@
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+const year = 365 * 24 * time.Hour
+
+// PeriodsPerYear returns how many candles of the interval fit in a year.
+// Crypto markets trade around the clock, so no trading-day calendar is used.
+func PeriodsPerYear(interval time.Duration) float64 {
+	return float64(year) / float64(interval)
+}
+
+// LogReturns returns the log returns between adjacent values. A return
+// from or to a non-positive value has no meaning and is skipped, so the
+// result may hold fewer than len(values)-1 returns.
+func LogReturns(values []float64) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	returns := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] <= 0 || values[i] <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(values[i]/values[i-1]))
+	}
+	return returns
+}
+
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// StdDev returns the sample standard deviation.
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := Mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+func AnnualizedVolatility(returns []float64, periodsPerYear float64) float64 {
+	return StdDev(returns) * math.Sqrt(periodsPerYear)
+}
+
+// Sharpe is the annualized mean return over volatility, with a zero risk-free
+// rate.
+func Sharpe(returns []float64, periodsPerYear float64) float64 {
+	sd := StdDev(returns)
+	if sd == 0 {
+		return 0
+	}
+	return Mean(returns) / sd * math.Sqrt(periodsPerYear)
+}
+
+func Skewness(values []float64) float64 {
+	n := float64(len(values))
+	if n < 3 {
+		return 0
+	}
+	mean := Mean(values)
+	var m2, m3 float64
+	for _, v := range values {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+	}
+	m2 /= n
+	m3 /= n
+	if m2 == 0 {
+		return 0
+	}
+	return m3 / math.Pow(m2, 1.5)
+}
+
+// Kurtosis returns the excess kurtosis (0 for a normal distribution).
+func Kurtosis(values []float64) float64 {
+	n := float64(len(values))
+	if n < 4 {
+		return 0
+	}
+	mean := Mean(values)
+	var m2, m4 float64
+	for _, v := range values {
+		d := v - mean
+		m2 += d * d
+		m4 += d * d * d * d
+	}
+	m2 /= n
+	m4 /= n
+	if m2 == 0 {
+		return 0
+	}
+	return m4/(m2*m2) - 3
+}
+
+// MaxDrawdown returns the largest peak-to-trough decline as a positive
+// fraction, along with the indexes of the peak and the trough.
+func MaxDrawdown(values []float64) (float64, int, int) {
+	var maxDD float64
+	peak, peakIdx, ddPeak, ddTrough := math.Inf(-1), 0, 0, 0
+	for i, v := range values {
+		if v > peak {
+			peak, peakIdx = v, i
+		}
+		if peak > 0 {
+			if dd := (peak - v) / peak; dd > maxDD {
+				maxDD, ddPeak, ddTrough = dd, peakIdx, i
+			}
+		}
+	}
+	return maxDD, ddPeak, ddTrough
+}
+
+func PercentChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
+
+type ReturnPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type Summary struct {
+	Observations         int           `json:"observations"`
+	From                 time.Time     `json:"from"`
+	To                   time.Time     `json:"to"`
+	FirstClose           float64       `json:"first_close"`
+	LastClose            float64       `json:"last_close"`
+	PercentChange        float64       `json:"percent_change"`
+	MeanReturn           float64       `json:"mean_return"`
+	Volatility           float64       `json:"volatility"`
+	AnnualizedVolatility float64       `json:"annualized_volatility"`
+	Sharpe               float64       `json:"sharpe"`
+	Skewness             float64       `json:"skewness"`
+	Kurtosis             float64       `json:"kurtosis"`
+	MaxDrawdown          float64       `json:"max_drawdown"`
+	MaxDrawdownPeak      time.Time     `json:"max_drawdown_peak"`
+	MaxDrawdownTrough    time.Time     `json:"max_drawdown_trough"`
+	LogReturns           []ReturnPoint `json:"log_returns"`
+}
+
+// Summarize computes the statistics of candles of the given interval. Only
+// the returns between candles one interval apart are used: a return across
+// missing candles would cover several intervals and skew the volatility.
+func Summarize(data []candles.Candle, interval time.Duration) Summary {
+	s := Summary{Observations: len(data), LogReturns: []ReturnPoint{}}
+	if len(data) == 0 {
+		return s
+	}
+
+	closes := make([]float64, len(data))
+	for i, c := range data {
+		closes[i] = c.Close
+	}
+	var returns []float64
+	for i := 1; i < len(data); i++ {
+		if data[i].Time.Sub(data[i-1].Time) != interval {
+			continue
+		}
+		for _, r := range LogReturns(closes[i-1 : i+1]) {
+			returns = append(returns, r)
+			s.LogReturns = append(s.LogReturns, ReturnPoint{Time: data[i].Time, Value: r})
+		}
+	}
+
+	ppy := PeriodsPerYear(interval)
+	s.From = data[0].Time
+	s.To = data[len(data)-1].Time
+	s.FirstClose = closes[0]
+	s.LastClose = closes[len(closes)-1]
+	s.PercentChange = PercentChange(s.FirstClose, s.LastClose)
+	s.MeanReturn = Mean(returns)
+	s.Volatility = StdDev(returns)
+	s.AnnualizedVolatility = AnnualizedVolatility(returns, ppy)
+	s.Sharpe = Sharpe(returns, ppy)
+	s.Skewness = Skewness(returns)
+	s.Kurtosis = Kurtosis(returns)
+
+	dd, peak, trough := MaxDrawdown(closes)
+	s.MaxDrawdown = dd
+	s.MaxDrawdownPeak = data[peak].Time
+	s.MaxDrawdownTrough = data[trough].Time
+
+	return s
+}
+
+// PercentChanges returns the change between the last close and the close at
+// or before each lookback. Lookbacks not covered by the data are omitted.
+func PercentChanges(data []candles.Candle, lookbacks map[string]time.Duration) map[string]float64 {
+	changes := make(map[string]float64, len(lookbacks))
+	if len(data) == 0 {
+		return changes
+	}
+
+	last := data[len(data)-1]
+	for name, lookback := range lookbacks {
+		target := last.Time.Add(-lookback)
+		if data[0].Time.After(target) {
+			continue
+		}
+		ref := data[0]
+		for _, c := range data {
+			if c.Time.After(target) {
+				break
+			}
+			ref = c
+		}
+		changes[name] = PercentChange(ref.Close, last.Close)
+	}
+	return changes
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antonyloussararian/Go-CryptoPrice/candles"
+)
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-9*math.Max(1, math.Abs(want))
+}
+
+// The expected values are computed by hand from the definitions: for
+// 1, 2, 3, 4, 10 the deviations from the mean 4 are -3, -2, -1, 0, 6, so
+// m2 = 50/5 = 10, m3 = 180/5 = 36 and m4 = 1394/5 = 278.8.
+func TestMoments(t *testing.T) {
+	skewed := []float64{1, 2, 3, 4, 10}
+	// Returns with mean 0.005 and squared deviations summing to 0.0013.
+	returns := []float64{0.01, -0.02, 0.03, 0}
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"mean", Mean(skewed), 4},
+		{"sample standard deviation", StdDev(skewed), math.Sqrt(50.0 / 4)},
+		{"skewness", Skewness(skewed), 36 / math.Pow(10, 1.5)},
+		{"symmetric skewness", Skewness([]float64{1, 2, 3}), 0},
+		{"excess kurtosis", Kurtosis(skewed), 278.8/100 - 3},
+		{"kurtosis of two values", Kurtosis([]float64{-1, 1, -1, 1}), -2},
+		{"constant", Skewness([]float64{5, 5, 5}) + Kurtosis([]float64{5, 5, 5, 5}), 0},
+		{"too few values", Skewness([]float64{1, 2}) + Kurtosis([]float64{1, 2, 3}), 0},
+		// sqrt(0.0013 / 3) × sqrt(365).
+		{"annualized volatility", AnnualizedVolatility(returns, 365), math.Sqrt(0.0013/3) * math.Sqrt(365)},
+		{"sharpe", Sharpe(returns, 365), 0.005 / math.Sqrt(0.0013/3) * math.Sqrt(365)},
+		{"sharpe of constant returns", Sharpe([]float64{0.01, 0.01}, 365), 0},
+		{"periods per year of 1h", PeriodsPerYear(time.Hour), 8760},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	tests := []struct {
+		values       []float64
+		dd           float64
+		peak, trough int
+	}{
+		// 120 → 90 loses 25%, 130 → 65 loses 50%.
+		{[]float64{100, 120, 90, 110, 130, 65, 70}, 0.5, 4, 5},
+		{[]float64{1, 2, 3}, 0, 0, 0},
+		{[]float64{3, 2, 1}, 2.0 / 3, 0, 2},
+		{nil, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		dd, peak, trough := MaxDrawdown(tt.values)
+		if !near(dd, tt.dd) || peak != tt.peak || trough != tt.trough {
+			t.Errorf("MaxDrawdown(%v) = %v, %d, %d, want %v, %d, %d", tt.values, dd, peak, trough, tt.dd, tt.peak, tt.trough)
+		}
+	}
+}
+
+func TestLogReturnsSkipsNonPositive(t *testing.T) {
+	got := LogReturns([]float64{1, 2, 0, 4, 8, -1})
+	want := []float64{math.Ln2, math.Ln2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LogReturns = %v, want %v", got, want)
+	}
+}
+
+// A missing candle must not produce a return spanning two intervals.
+func TestSummarizeSkipsGaps(t *testing.T) {
+	start := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	at := func(hours int, close float64) candles.Candle {
+		return candles.Candle{Time: start.Add(time.Duration(hours) * time.Hour), Close: close}
+	}
+	data := []candles.Candle{at(0, 100), at(1, 110), at(3, 121), at(4, 133.1)}
+
+	s := Summarize(data, time.Hour)
+	if s.Observations != 4 || len(s.LogReturns) != 2 {
+		t.Fatalf("observations %d, returns %+v", s.Observations, s.LogReturns)
+	}
+	for i, want := range []time.Time{data[1].Time, data[3].Time} {
+		if r := s.LogReturns[i]; !r.Time.Equal(want) || !near(r.Value, math.Log(1.1)) {
+			t.Errorf("return %d = %+v, want log(1.1) at %v", i, r, want)
+		}
+	}
+	if !near(s.MeanReturn, math.Log(1.1)) || !near(s.Volatility, 0) || !near(s.PercentChange, 33.1) {
+		t.Errorf("mean %v, volatility %v, change %v", s.MeanReturn, s.Volatility, s.PercentChange)
+	}
+}
@@ -0,0 +1,113 @@
+// Package tracing configures OpenTelemetry tracing: the exporter, the tracer
+// provider and the Gin middleware starting a span per request.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the service.name of the traces, unless OTEL_SERVICE_NAME
+// is set.
+const ServiceName = "go-cryptoprice"
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Tracer returns the tracer of an instrumented package. It can be called
+// before Setup: spans are then recorded by the provider installed later.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer("github.com/antonyloussararian/Go-CryptoPrice/" + name)
+}
+
+type Config struct {
+	// Exporter is "otlp", "stdout" or "none" (default).
+	Exporter string
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g.
+	// "http://localhost:4318". When empty, the OTEL_EXPORTER_OTLP_ENDPOINT
+	// and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables apply.
+	Endpoint string
+	// Writer receives the spans of the stdout exporter (default os.Stdout).
+	Writer io.Writer
+}
+
+// Setup installs the global tracer provider and returns the function that
+// flushes and stops it.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		w := cfg.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	default:
+		return nil, fmt.Errorf("exportateur de traces inconnu %q (otlp, stdout ou none)", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := newProvider(sdktrace.WithBatcher(exporter))
+	return provider.Shutdown, nil
+}
+
+// SetupInMemory installs a provider keeping the spans in memory, exported as
+// soon as they end, for tests and local checks.
+func SetupInMemory() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	newProvider(sdktrace.WithSyncer(exporter))
+	return exporter
+}
+
+func newProvider(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		res = resource.Default()
+	}
+	// OTEL_SERVICE_NAME, read by resource.Default, wins over ServiceName.
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		res, _ = resource.Merge(res, resource.NewSchemaless(semconv.ServiceName(name)))
+	}
+
+	provider := sdktrace.NewTracerProvider(append(opts, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider
+}
+
+// RecordError marks the span as failed.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}